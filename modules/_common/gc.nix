@@ -1,8 +1,16 @@
 { config, pkgs, ... }:
 
 let
+  inherit ((pkgs.callPackage ../../. { inherit pkgs; })) earth-view;
+
   imgDir = config.services.earth-view.imageDirectory;
   cfg = config.services.earth-view.gc;
+
+  deleteCommand =
+    if cfg.trash then
+      "${pkgs.findutils}/bin/xargs --no-run-if-empty ${earth-view}/bin/earth-view trash"
+    else
+      "${pkgs.findutils}/bin/xargs rm -f";
 in
 pkgs.writeScriptBin "gc" ''
   #!${pkgs.bash}/bin/bash
@@ -27,8 +35,8 @@ pkgs.writeScriptBin "gc" ''
   ${pkgs.findutils}/bin/find $outdir -type f -printf '%Ts\t%h/%P\n' | \
     ${pkgs.coreutils}/bin/sort -n | \
     ${pkgs.coreutils}/bin/cut -f2 | \
-    ${pkgs.gnugrep}/bin/grep -v $(${pkgs.coreutils}/bin/readlink $outdir/.current) | \
+    ${pkgs.gnugrep}/bin/grep -v -F -x "$(${pkgs.coreutils}/bin/readlink $outdir/.current $outdir/.current-dark)" | \
     ${pkgs.coreutils}/bin/head -n -${toString (cfg.keep - 1)} | \
-    ${pkgs.findutils}/bin/xargs rm -f
+    ${deleteCommand}
   ${pkgs.findutils}/bin/find $outdir -xtype l -delete
 ''
@@ -41,8 +41,6 @@
     default = "fill";
     description = ''
       Display background images according to this option.
-
-      Note that this option has no effect on GNOME shell desktops.
     '';
   };
 
@@ -53,7 +51,19 @@
       Will place a separate image per screen when enabled, otherwise a single image
       will be stretched across all screens.
 
-      Note that this option has no effect on GNOME shell desktops.
+      Note that this option has no effect on KDE desktops.
+    '';
+  };
+
+  darkVariant = lib.mkOption {
+    type = lib.types.bool;
+    default = false;
+    description = ''
+      Whether to set a separate background image for dark mode. A bright image is
+      picked for light mode and a dark image for dark mode, based on their measured
+      luminance, so that switching the color scheme also switches the background.
+
+      Note that this option only has an effect on GNOME shell desktops.
     '';
   };
 
@@ -96,5 +106,15 @@
         formatted as a string understood by `du`'s size option.
       '';
     };
+
+    trash = lib.mkOption {
+      type = lib.types.bool;
+      default = false;
+      description = ''
+        Whether to move garbage collected images to the trash instead of permanently
+        deleting them. Trashed images can be restored from any file manager following
+        the FreeDesktop.org Trash specification.
+      '';
+    };
   };
 }
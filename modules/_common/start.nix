@@ -10,13 +10,20 @@ let
 
   cfg = config.services.earth-view;
 
-  fehFlags = lib.concatStringsSep " " (
-    [
-      "--bg-${cfg.display}"
-      "--no-fehbg"
-    ]
+  setFlags = lib.concatStringsSep " " (
+    [ "--display ${cfg.display}" ]
     ++ lib.optional (!cfg.enableXinerama) "--no-xinerama"
+    ++ lib.optional cfg.darkVariant "--pair"
   );
+
+  # Programs used by earth-view to set the background
+  backendsPath = lib.makeBinPath [
+    pkgs.feh
+    pkgs.glib
+    pkgs.kdePackages.plasma-workspace
+    pkgs.swaybg
+    pkgs.swww
+  ];
 in
 source:
 pkgs.writeScriptBin "start" ''
@@ -25,24 +32,21 @@ pkgs.writeScriptBin "start" ''
   outdir="$HOME/${cfg.imageDirectory}"
 
   ${pkgs.coreutils}/bin/mkdir -p $outdir
-  file=$(${earth-view}/bin/earth-view fetch random -i ${source} -o $outdir)
+  files=$(PATH=${backendsPath}:$PATH ${earth-view}/bin/earth-view set random ${setFlags} -i ${source} -o $outdir)
 
   if test $? -ne 0; then
-    ${pkgs.coreutils}/bin/echo "Error while fetching image"
+    ${pkgs.coreutils}/bin/echo "Error while setting background"
     exit 1
   fi
 
-  if test "$XDG_CURRENT_DESKTOP" = "GNOME"; then
-    ${pkgs.coreutils}/bin/echo "GNOME detected, use gsettings"
-    ${pkgs.glib}/bin/gsettings set org.gnome.desktop.background picture-uri file://$file
-    ${pkgs.glib}/bin/gsettings set org.gnome.desktop.background picture-uri-dark file://$file
-  elif test "$XDG_CURRENT_DESKTOP" = "KDE"; then
-    ${pkgs.coreutils}/bin/echo "KDE detected, use plasma-apply-wallpaperimage"
-    ${pkgs.kdePackages.plasma-workspace}/bin/plasma-apply-wallpaperimage $file
-  else
-    ${pkgs.coreutils}/bin/echo "Could not detect environment, use feh"
-    ${pkgs.feh}/bin/feh ${fehFlags} $file
-  fi
+  # Light image comes first, followed by dark image if darkVariant is enabled
+  # Other lines, such as the paths of derived images, are ignored
+  ${pkgs.coreutils}/bin/ln -fs $(${pkgs.coreutils}/bin/head -n 1 <<< "$files") $outdir/.current
 
-  ${pkgs.coreutils}/bin/ln -fs $file $outdir/.current
+  ${
+    if cfg.darkVariant then
+      "${pkgs.coreutils}/bin/ln -fs $(${pkgs.gnused}/bin/sed -n 2p <<< \"$files\") $outdir/.current-dark"
+    else
+      "${pkgs.coreutils}/bin/rm -f $outdir/.current-dark"
+  }
 ''
@@ -50,6 +50,8 @@ in
             Type = "oneshot";
             IOSchedulingClass = "idle";
             ExecStart = "${startScript}/bin/start";
+            # Keep programs displaying the background (i.e. swaybg) running once the service exits
+            KillMode = "process";
           };
 
           Install.WantedBy = [ "graphical-session.target" ];
@@ -46,6 +46,8 @@ in
             Type = "oneshot";
             IOSchedulingClass = "idle";
             ExecStart = "${startScript}/bin/start";
+            # Keep programs displaying the background (i.e. swaybg) running once the service exits
+            KillMode = "process";
           };
 
           wantedBy = [ "graphical-session.target" ];
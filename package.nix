@@ -4,7 +4,7 @@ buildGoModule (finalAttrs: {
   pname = "earth-view";
   version = "1.0.0";
   src = ./src;
-  vendorHash = "sha256-kiYMJXsrRJxU2P6mxFtt0kZd5qu1Qbd3uIXjXFUyjZA=";
+  vendorHash = "sha256-tJwBH5qoMlkgFnhvve8OmB0Ww2mpZgx4DRi470Ss76Y=";
   doCheck = false;
 
   meta = {
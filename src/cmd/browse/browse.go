@@ -0,0 +1,137 @@
+/*
+Copyright © 2024 Nicolas Goudry <goudry.nicolas@gmail.com>
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package browse
+
+import (
+	"fmt"
+	"path/filepath"
+	"strconv"
+
+	"earth-view/cmd"
+	"earth-view/cmd/fetch"
+	"earth-view/cmd/set"
+	"earth-view/lib"
+	"earth-view/lib/index"
+	"earth-view/lib/wallpaper"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/spf13/cobra"
+)
+
+var (
+	backendFlags set.BackendFlags
+	dir          string
+
+	browseCmd = &cobra.Command{
+		Use:   "browse",
+		Short: "Browse images interactively",
+		Long: fmt.Sprintf(`Browse Google Earth View images in an interactive terminal interface.
+
+Description:
+  This command lists the images of the local index, which is filled when
+  downloading images or by the 'index build' command, along with their
+  location. It works offline, except for downloading images.
+
+  The list can be filtered by typing '/' followed by a fuzzy pattern matched
+  against the identifier, country, region, continent and attribution of images.
+
+  The selected image is previewed next to the list if it was downloaded in the
+  directory set by the '--dir' flag, defaulting to the current working
+  directory. Pressing 'v' displays it in the whole terminal, using the best
+  graphics protocol supported by the terminal.
+
+  Keys:
+    /       filter images
+    esc     clear filter
+    d       download the selected image
+    f       add the selected image to favorites, or remove it
+    b       ban the selected image, or allow it again
+    s       set the selected image as desktop background
+    v       view the selected image in the whole terminal
+    q       quit
+
+  Setting the background records it in history, and updates the '.current'
+  symbolic link of the image directory if it exists.
+
+Backends:
+%s`, set.BackendHelp),
+		DisableFlagsInUseLine: true,
+		SilenceUsage:          true,
+		Args:                  cobra.MaximumNArgs(0),
+		Run: func(_ *cobra.Command, _ []string) {
+			cobra.CheckErr(runBrowseCmd(dir))
+		},
+	}
+)
+
+func init() {
+	cmd.RootCmd.AddCommand(browseCmd)
+
+	backendFlags.AddFlags(browseCmd.Flags())
+	browseCmd.Flags().StringVar(&dir, "dir", ".", "directory holding downloaded images")
+}
+
+// setBackground sets the desktop background with the backend selected by flags
+func setBackground(filePath string) error {
+	opts, err := backendFlags.Options()
+	if err != nil {
+		return err
+	}
+
+	backend, err := backendFlags.Backend(wallpaper.ExecRunner{})
+	if err != nil {
+		return err
+	}
+
+	return wallpaper.Show(backend, filePath, opts, true, "")
+}
+
+func runBrowseCmd(dir string) error {
+	idx, err := index.Load()
+	if err != nil {
+		return err
+	}
+
+	if idx.Len() == 0 {
+		return fmt.Errorf("index is empty, build it with the 'index build' command")
+	}
+
+	prefs, err := lib.LoadPreferences()
+	if err != nil {
+		return err
+	}
+
+	absDir, err := filepath.Abs(dir)
+	if err != nil {
+		return err
+	}
+
+	m := newModel(idx.Entries(), prefs, absDir)
+	m.download = func(id int) (string, error) {
+		return fetch.Fetch(strconv.Itoa(id), absDir, false)
+	}
+	m.setBackground = setBackground
+
+	_, err = tea.NewProgram(m, tea.WithAltScreen()).Run()
+
+	return err
+}
@@ -0,0 +1,72 @@
+/*
+Copyright © 2024 Nicolas Goudry <goudry.nicolas@gmail.com>
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package browse
+
+import (
+	"strings"
+	"unicode"
+)
+
+// fuzzyScore returns whether all the characters of the pattern appear in order in the text, along
+// with a score rewarding consecutive characters and characters at the start of words
+func fuzzyScore(pattern string, text string) (int, bool) {
+	pattern = strings.ToLower(pattern)
+	runes := []rune(strings.ToLower(text))
+
+	score := 0
+	position := 0
+	previous := -2
+
+	for _, p := range pattern {
+		if unicode.IsSpace(p) {
+			continue
+		}
+
+		found := false
+		for ; position < len(runes); position++ {
+			if runes[position] != p {
+				continue
+			}
+
+			score++
+			if position == previous+1 {
+				score += 4
+			}
+
+			if position == 0 || !unicode.IsLetter(runes[position-1]) {
+				score += 3
+			}
+
+			previous = position
+			position++
+			found = true
+
+			break
+		}
+
+		if !found {
+			return 0, false
+		}
+	}
+
+	return score, true
+}
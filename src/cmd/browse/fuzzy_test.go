@@ -0,0 +1,31 @@
+package browse
+
+import (
+	"testing"
+)
+
+func TestFuzzyScore(t *testing.T) {
+	for _, tc := range []struct {
+		pattern string
+		text    string
+		matches bool
+	}{
+		{"", "France", true},
+		{"fra", "France", true},
+		{"frce", "France", true},
+		{"ecnarf", "France", false},
+		{"us utah", "United States Utah", true},
+		{"ICE", "Iceland", true},
+	} {
+		if _, ok := fuzzyScore(tc.pattern, tc.text); ok != tc.matches {
+			t.Fatalf("Expected %q matching %q to be %t", tc.pattern, tc.text, tc.matches)
+		}
+	}
+
+	// Consecutive characters at the start of a word score higher than scattered ones
+	prefix, _ := fuzzyScore("ice", "1006 Iceland")
+	scattered, _ := fuzzyScore("ice", "1007 Indonesia Central")
+	if prefix <= scattered {
+		t.Fatalf("Expected prefix match to score higher, got %d and %d", prefix, scattered)
+	}
+}
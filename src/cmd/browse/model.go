@@ -0,0 +1,579 @@
+package browse
+
+import (
+	"bufio"
+	"fmt"
+	"image/jpeg"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"earth-view/lib"
+	"earth-view/lib/geo"
+	"earth-view/lib/index"
+	"earth-view/lib/preview"
+	"earth-view/lib/theme"
+
+	"github.com/charmbracelet/bubbles/help"
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/table"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+const padding = 1
+
+// keyMap holds the key bindings of the browser
+type keyMap struct {
+	Filter    key.Binding
+	Clear     key.Binding
+	Download  key.Binding
+	Favorite  key.Binding
+	Ban       key.Binding
+	Set       key.Binding
+	View      key.Binding
+	Quit      key.Binding
+	EndFilter key.Binding
+}
+
+func (k keyMap) ShortHelp() []key.Binding {
+	return []key.Binding{k.Filter, k.Download, k.Favorite, k.Ban, k.Set, k.View, k.Quit}
+}
+
+func (k keyMap) FullHelp() [][]key.Binding {
+	return [][]key.Binding{k.ShortHelp()}
+}
+
+var keys = keyMap{
+	Filter:    key.NewBinding(key.WithKeys("/"), key.WithHelp("/", "filter")),
+	Clear:     key.NewBinding(key.WithKeys("esc"), key.WithHelp("esc", "clear filter")),
+	Download:  key.NewBinding(key.WithKeys("d"), key.WithHelp("d", "download")),
+	Favorite:  key.NewBinding(key.WithKeys("f"), key.WithHelp("f", "favorite")),
+	Ban:       key.NewBinding(key.WithKeys("b"), key.WithHelp("b", "ban")),
+	Set:       key.NewBinding(key.WithKeys("s"), key.WithHelp("s", "set background")),
+	View:      key.NewBinding(key.WithKeys("v", "enter"), key.WithHelp("v", "view")),
+	Quit:      key.NewBinding(key.WithKeys("q", "ctrl+c"), key.WithHelp("q", "quit")),
+	EndFilter: key.NewBinding(key.WithKeys("enter", "esc", "up", "down")),
+}
+
+// Custom messages
+type previewMsg struct {
+	id      int
+	content string
+	err     error
+}
+type statusMsg struct {
+	text string
+	err  error
+}
+type downloadedMsg struct {
+	id  int
+	err error
+}
+
+// UI state
+type model struct {
+	entries   []index.Entry
+	visible   []index.Entry
+	table     table.Model
+	help      help.Model
+	prefs     *lib.Preferences
+	dir       string
+	filter    string
+	filtering bool
+	status    string
+	statusErr bool
+	width     int
+	height    int
+	previews  map[int]string
+
+	// download fetches an image in the image directory and returns its path
+	download func(id int) (string, error)
+	// setBackground sets the desktop background to the given image file
+	setBackground func(filePath string) error
+}
+
+func newModel(entries []index.Entry, prefs *lib.Preferences, dir string) model {
+	styles := table.DefaultStyles()
+	styles.Header = styles.Header.
+		BorderStyle(lipgloss.NormalBorder()).
+		BorderForeground(theme.Muted).
+		BorderBottom(true).
+		Bold(true)
+	styles.Selected = styles.Selected.Foreground(theme.Blue).Bold(true)
+
+	m := model{
+		entries:  entries,
+		table:    table.New(table.WithFocused(true), table.WithStyles(styles)),
+		help:     help.New(),
+		prefs:    prefs,
+		dir:      dir,
+		previews: make(map[int]string),
+		width:    80,
+		height:   24,
+	}
+	m.resize()
+	m.applyFilter()
+
+	return m
+}
+
+// imagePath returns the path of the image file in the image directory
+func (m model) imagePath(id int) string {
+	return filepath.Join(m.dir, strconv.Itoa(id)+".jpeg")
+}
+
+// selected returns the entry under the cursor
+func (m model) selected() (index.Entry, bool) {
+	cursor := m.table.Cursor()
+	if cursor < 0 || cursor >= len(m.visible) {
+		return index.Entry{}, false
+	}
+
+	return m.visible[cursor], true
+}
+
+// listWidth returns the width of the list, the rest being used by the preview pane
+func (m model) listWidth() int {
+	return m.width * 3 / 5
+}
+
+// previewSize returns the number of columns and rows of the preview image
+func (m model) previewSize() (int, int) {
+	return max(m.width-m.listWidth()-padding*3, 1), max(m.height/2, 1)
+}
+
+// resize fits the table columns in the list width
+func (m *model) resize() {
+	width := m.listWidth()
+	fixed := 2 + 6 + 5
+	flexible := max(width-fixed-10, 10)
+
+	m.table.SetColumns([]table.Column{
+		{Title: "", Width: 2},
+		{Title: "ID", Width: 6},
+		{Title: "Country", Width: flexible / 2},
+		{Title: "Region", Width: flexible - flexible/2},
+		{Title: "Local", Width: 5},
+	})
+	m.table.SetWidth(width)
+	// Header, filter, status and help lines
+	m.table.SetHeight(max(m.height-5, 3))
+	m.help.Width = m.width
+	m.previews = make(map[int]string)
+}
+
+// applyFilter lists the entries matching the filter, best matches first
+func (m *model) applyFilter() {
+	type match struct {
+		entry index.Entry
+		score int
+	}
+
+	var matches []match
+	for _, entry := range m.entries {
+		text := strings.Join([]string{
+			strconv.Itoa(entry.Id),
+			entry.Country,
+			entry.Region,
+			geo.ContinentOf(entry.Country),
+			entry.Attribution,
+		}, " ")
+
+		if score, ok := fuzzyScore(m.filter, text); ok {
+			matches = append(matches, match{entry, score})
+		}
+	}
+
+	if m.filter != "" {
+		slices.SortStableFunc(matches, func(a, b match) int { return b.score - a.score })
+	}
+
+	m.visible = make([]index.Entry, len(matches))
+	for i, match := range matches {
+		m.visible[i] = match.entry
+	}
+
+	m.refreshRows()
+	m.table.SetCursor(0)
+}
+
+// refreshRows updates the table rows from the visible entries
+func (m *model) refreshRows() {
+	rows := make([]table.Row, len(m.visible))
+	for i, entry := range m.visible {
+		marker := ""
+		if m.prefs.IsFavorite(entry.Id) {
+			marker = "★"
+		} else if m.prefs.IsBanned(entry.Id) {
+			marker = "✗"
+		}
+
+		local := ""
+		if lib.FileExists(m.imagePath(entry.Id)) {
+			local = "✓"
+		}
+
+		rows[i] = table.Row{marker, strconv.Itoa(entry.Id), entry.Country, entry.Region, local}
+	}
+
+	m.table.SetRows(rows)
+}
+
+// loadPreview renders the preview of the selected image if it is downloaded and not yet rendered
+func (m model) loadPreview() tea.Cmd {
+	entry, ok := m.selected()
+	if !ok {
+		return nil
+	}
+
+	if _, ok := m.previews[entry.Id]; ok || !lib.FileExists(m.imagePath(entry.Id)) {
+		return nil
+	}
+
+	filePath := m.imagePath(entry.Id)
+	cols, rows := m.previewSize()
+
+	return func() tea.Msg {
+		file, err := os.Open(filePath)
+		if err != nil {
+			return previewMsg{id: entry.Id, err: err}
+		}
+		defer file.Close()
+
+		img, err := jpeg.Decode(file)
+		if err != nil {
+			return previewMsg{id: entry.Id, err: fmt.Errorf("failed to decode image: %s", err)}
+		}
+
+		content, err := preview.BlocksString(img, cols, rows)
+
+		return previewMsg{id: entry.Id, content: content, err: err}
+	}
+}
+
+// togglePreference adds the selected image to favorites or banned images, or removes it
+func (m *model) togglePreference(favorite bool) tea.Cmd {
+	entry, ok := m.selected()
+	if !ok {
+		return nil
+	}
+
+	pref := lib.Preference{
+		Id:          entry.Id,
+		Time:        time.Now(),
+		Country:     entry.Country,
+		Region:      entry.Region,
+		Attribution: entry.Attribution,
+	}
+
+	var text string
+	switch {
+	case favorite && m.prefs.Unlike(entry.Id):
+		text = fmt.Sprintf("Removed %d from favorites", entry.Id)
+	case favorite:
+		m.prefs.Like(pref)
+		text = fmt.Sprintf("Added %d to favorites", entry.Id)
+	case m.prefs.Unban(entry.Id):
+		text = fmt.Sprintf("Removed %d from banned images", entry.Id)
+	default:
+		m.prefs.Ban(pref)
+		text = fmt.Sprintf("Added %d to banned images", entry.Id)
+	}
+
+	m.refreshRows()
+
+	if err := m.prefs.Save(); err != nil {
+		return func() tea.Msg { return statusMsg{err: err} }
+	}
+
+	return func() tea.Msg { return statusMsg{text: text} }
+}
+
+// downloadSelected fetches the selected image
+func (m model) downloadSelected() tea.Cmd {
+	entry, ok := m.selected()
+	if !ok {
+		return nil
+	}
+
+	return func() tea.Msg {
+		_, err := m.download(entry.Id)
+		return downloadedMsg{id: entry.Id, err: err}
+	}
+}
+
+// setSelected sets the selected image as desktop background, downloading it first if needed
+func (m model) setSelected() tea.Cmd {
+	entry, ok := m.selected()
+	if !ok {
+		return nil
+	}
+
+	return func() tea.Msg {
+		filePath, err := m.download(entry.Id)
+		if err != nil {
+			return statusMsg{err: err}
+		}
+
+		if err := m.setBackground(filePath); err != nil {
+			return statusMsg{err: err}
+		}
+
+		return statusMsg{text: fmt.Sprintf("Background set to %d", entry.Id)}
+	}
+}
+
+// viewSelected displays the selected image with the best protocol supported by the terminal,
+// suspending the user interface meanwhile
+func (m model) viewSelected() tea.Cmd {
+	entry, ok := m.selected()
+	if !ok {
+		return nil
+	}
+
+	if !lib.FileExists(m.imagePath(entry.Id)) {
+		return func() tea.Msg {
+			return statusMsg{err: fmt.Errorf("image %d is not downloaded", entry.Id)}
+		}
+	}
+
+	return tea.Exec(&previewCommand{filePath: m.imagePath(entry.Id)}, func(err error) tea.Msg {
+		if err != nil {
+			return statusMsg{err: err}
+		}
+
+		return nil
+	})
+}
+
+func (m model) Init() tea.Cmd {
+	return m.loadPreview()
+}
+
+func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.KeyMsg:
+		if m.filtering {
+			return m.updateFilter(msg)
+		}
+
+		switch {
+		case key.Matches(msg, keys.Quit):
+			return m, tea.Quit
+		case key.Matches(msg, keys.Filter):
+			m.filtering = true
+			return m, nil
+		case key.Matches(msg, keys.Clear):
+			m.filter = ""
+			m.applyFilter()
+			return m, m.loadPreview()
+		case key.Matches(msg, keys.Download):
+			m.status, m.statusErr = "Downloading...", false
+			return m, m.downloadSelected()
+		case key.Matches(msg, keys.Favorite):
+			return m, m.togglePreference(true)
+		case key.Matches(msg, keys.Ban):
+			return m, m.togglePreference(false)
+		case key.Matches(msg, keys.Set):
+			m.status, m.statusErr = "Setting background...", false
+			return m, m.setSelected()
+		case key.Matches(msg, keys.View):
+			return m, m.viewSelected()
+		}
+
+	case tea.WindowSizeMsg:
+		m.width, m.height = msg.Width, msg.Height
+		m.resize()
+		return m, m.loadPreview()
+
+	case previewMsg:
+		// Errors are shown in place of the preview so that loading is not retried endlessly
+		m.previews[msg.id] = msg.content
+		if msg.err != nil {
+			m.previews[msg.id] = lipgloss.NewStyle().Foreground(theme.Red).Render(msg.err.Error())
+		}
+
+		return m, nil
+
+	case downloadedMsg:
+		if msg.err != nil {
+			m.status, m.statusErr = msg.err.Error(), true
+			return m, nil
+		}
+
+		m.status, m.statusErr = fmt.Sprintf("Downloaded %d", msg.id), false
+		delete(m.previews, msg.id)
+		m.refreshRows()
+		return m, m.loadPreview()
+
+	case statusMsg:
+		m.status, m.statusErr = msg.text, msg.err != nil
+		if msg.err != nil {
+			m.status = msg.err.Error()
+		}
+
+		m.refreshRows()
+		return m, m.loadPreview()
+	}
+
+	var cmd tea.Cmd
+	m.table, cmd = m.table.Update(msg)
+
+	return m, tea.Batch(cmd, m.loadPreview())
+}
+
+// updateFilter handles keys while typing the filter
+func (m model) updateFilter(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch {
+	case msg.Type == tea.KeyCtrlC:
+		return m, tea.Quit
+	case key.Matches(msg, keys.EndFilter):
+		m.filtering = false
+		if msg.Type == tea.KeyEsc {
+			m.filter = ""
+			m.applyFilter()
+		}
+
+		// Arrows stop filtering and move in the list right away
+		if msg.Type == tea.KeyUp || msg.Type == tea.KeyDown {
+			var cmd tea.Cmd
+			m.table, cmd = m.table.Update(msg)
+			return m, tea.Batch(cmd, m.loadPreview())
+		}
+	case msg.Type == tea.KeyBackspace:
+		if runes := []rune(m.filter); len(runes) > 0 {
+			m.filter = string(runes[:len(runes)-1])
+			m.applyFilter()
+		}
+	case msg.Type == tea.KeyRunes || msg.Type == tea.KeySpace:
+		m.filter += string(msg.Runes)
+		m.applyFilter()
+	}
+
+	return m, m.loadPreview()
+}
+
+func (m model) View() string {
+	title := lipgloss.NewStyle().Bold(true).Foreground(theme.Blue).Render("Earth View")
+	count := lipgloss.NewStyle().
+		Foreground(theme.Muted).
+		Render(fmt.Sprintf("%d/%d images", len(m.visible), len(m.entries)))
+
+	filter := ""
+	if m.filtering || m.filter != "" {
+		cursor := ""
+		if m.filtering {
+			cursor = "█"
+		}
+
+		filter = lipgloss.NewStyle().Foreground(theme.Orange).Render("/" + m.filter + cursor)
+	}
+
+	header := lipgloss.NewStyle().Padding(0, padding).Render(
+		lipgloss.JoinHorizontal(lipgloss.Top, title, "  ", count, "  ", filter),
+	)
+
+	body := lipgloss.JoinHorizontal(
+		lipgloss.Top,
+		lipgloss.NewStyle().PaddingLeft(padding).Render(m.table.View()),
+		lipgloss.NewStyle().PaddingLeft(padding*2).Render(m.previewView()),
+	)
+
+	statusStyle := lipgloss.NewStyle().Padding(0, padding).Foreground(theme.Green)
+	if m.statusErr {
+		statusStyle = statusStyle.Foreground(theme.Red)
+	}
+
+	return lipgloss.JoinVertical(
+		lipgloss.Left,
+		header,
+		body,
+		statusStyle.Render(m.status),
+		lipgloss.NewStyle().Padding(0, padding).Render(m.help.View(keys)),
+	)
+}
+
+// previewView renders the preview pane of the selected image
+func (m model) previewView() string {
+	entry, ok := m.selected()
+	if !ok {
+		return lipgloss.NewStyle().Foreground(theme.Muted).Render("No image matches the filter")
+	}
+
+	image, ok := m.previews[entry.Id]
+	if !lib.FileExists(m.imagePath(entry.Id)) {
+		image = lipgloss.NewStyle().
+			Foreground(theme.Muted).
+			Render("Not downloaded, press d to download")
+	} else if !ok {
+		image = lipgloss.NewStyle().Foreground(theme.Muted).Render("Loading preview...")
+	}
+
+	var location []string
+	for _, part := range []string{entry.Region, entry.Country, geo.ContinentOf(entry.Country)} {
+		if part != "" {
+			location = append(location, part)
+		}
+	}
+
+	details := []string{
+		lipgloss.NewStyle().Bold(true).Render(strings.Join(location, ", ")),
+		entry.Point().FormatDMS(),
+	}
+
+	if entry.Attribution != "" {
+		details = append(
+			details,
+			lipgloss.NewStyle().Foreground(theme.Muted).Render(entry.Attribution),
+		)
+	}
+
+	if m.prefs.IsFavorite(entry.Id) {
+		details = append(details, lipgloss.NewStyle().Foreground(theme.Yellow).Render("★ Favorite"))
+	} else if m.prefs.IsBanned(entry.Id) {
+		details = append(details, lipgloss.NewStyle().Foreground(theme.Red).Render("✗ Banned"))
+	}
+
+	return lipgloss.JoinVertical(lipgloss.Left, image, "", strings.Join(details, "\n"))
+}
+
+// previewCommand displays an image in the whole terminal until enter is pressed
+// It implements tea.ExecCommand so that the user interface is suspended meanwhile
+type previewCommand struct {
+	filePath string
+	stdin    io.Reader
+	stdout   io.Writer
+}
+
+func (c *previewCommand) SetStdin(r io.Reader) {
+	c.stdin = r
+}
+
+func (c *previewCommand) SetStdout(w io.Writer) {
+	c.stdout = w
+}
+
+func (c *previewCommand) SetStderr(_ io.Writer) {}
+
+func (c *previewCommand) Run() error {
+	protocol := preview.DetectTerminal()
+
+	// Clear the screen and move the cursor to its top left corner
+	fmt.Fprint(c.stdout, "\x1b[2J\x1b[H")
+
+	if err := preview.RenderFile(c.stdout, c.filePath, protocol); err != nil {
+		return err
+	}
+
+	fmt.Fprint(c.stdout, "Press enter to go back")
+	if _, err := bufio.NewReader(c.stdin).ReadString('\n'); err != nil {
+		return err
+	}
+
+	return preview.Clear(c.stdout, protocol)
+}
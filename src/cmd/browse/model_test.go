@@ -0,0 +1,130 @@
+package browse
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"earth-view/lib"
+	"earth-view/lib/index"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+var entries = []index.Entry{
+	{Id: 1003, Country: "France", Region: "Brittany"},
+	{Id: 1004, Country: "Iceland"},
+	{Id: 1006, Country: "Chile", Region: "Atacama"},
+}
+
+func newTestModel(t *testing.T) model {
+	t.Setenv("XDG_STATE_HOME", t.TempDir())
+
+	dir := t.TempDir()
+	m := newModel(entries, &lib.Preferences{}, dir)
+	m.download = func(id int) (string, error) {
+		filePath := filepath.Join(dir, fmt.Sprintf("%d.jpeg", id))
+		return filePath, os.WriteFile(filePath, nil, 0644)
+	}
+	m.setBackground = func(string) error { return nil }
+
+	return m
+}
+
+// send runs the update function with the given messages, and the messages of resulting commands
+func send(m model, msgs ...tea.Msg) model {
+	for _, msg := range msgs {
+		updated, cmd := m.Update(msg)
+		m = updated.(model)
+
+		for _, result := range runCmd(cmd) {
+			m = send(m, result)
+		}
+	}
+
+	return m
+}
+
+// runCmd runs a command and returns its messages, expanding batches
+func runCmd(cmd tea.Cmd) []tea.Msg {
+	if cmd == nil {
+		return nil
+	}
+
+	msg := cmd()
+	if batch, ok := msg.(tea.BatchMsg); ok {
+		var msgs []tea.Msg
+		for _, cmd := range batch {
+			msgs = append(msgs, runCmd(cmd)...)
+		}
+
+		return msgs
+	}
+
+	if msg == nil {
+		return nil
+	}
+
+	return []tea.Msg{msg}
+}
+
+func keyMsg(s string) tea.KeyMsg {
+	switch s {
+	case "enter":
+		return tea.KeyMsg{Type: tea.KeyEnter}
+	case "esc":
+		return tea.KeyMsg{Type: tea.KeyEsc}
+	}
+
+	return tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(s)}
+}
+
+func TestBrowseFilter(t *testing.T) {
+	m := newTestModel(t)
+
+	m = send(m, keyMsg("/"), keyMsg("i"), keyMsg("c"), keyMsg("e"), keyMsg("enter"))
+	if len(m.visible) == 0 || m.visible[0].Id != 1004 {
+		t.Fatalf("Expected 1004 to be the best match, got %v", m.visible)
+	}
+
+	if len(m.visible) == len(entries) || !strings.Contains(m.View(), "/ice") {
+		t.Fatalf("Expected filter to be shown")
+	}
+
+	m = send(m, keyMsg("esc"))
+	if len(m.visible) != len(entries) {
+		t.Fatalf("Expected filter to be cleared, got %v", m.visible)
+	}
+}
+
+func TestBrowseActions(t *testing.T) {
+	m := newTestModel(t)
+
+	m = send(m, keyMsg("f"))
+	if !m.prefs.IsFavorite(1003) {
+		t.Fatalf("Expected 1003 to be a favorite")
+	}
+
+	prefs, _ := lib.LoadPreferences()
+	if !prefs.IsFavorite(1003) {
+		t.Fatalf("Expected preferences to be saved")
+	}
+
+	m = send(m, keyMsg("b"))
+	if m.prefs.IsFavorite(1003) || !m.prefs.IsBanned(1003) {
+		t.Fatalf("Expected 1003 to be banned")
+	}
+
+	m = send(m, keyMsg("d"))
+	if !lib.FileExists(m.imagePath(1003)) || m.status != "Downloaded 1003" {
+		t.Fatalf("Expected 1003 to be downloaded, got status %q", m.status)
+	}
+
+	m.setBackground = func(string) error { return fmt.Errorf("no backend") }
+	m = send(m, keyMsg("s"))
+	if !m.statusErr || m.status != "no backend" {
+		t.Fatalf("Expected error status, got %q", m.status)
+	}
+}
@@ -0,0 +1,97 @@
+/*
+Copyright © 2024 Nicolas Goudry <goudry.nicolas@gmail.com>
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package compose
+
+import (
+	"image"
+
+	"earth-view/lib/imaging"
+
+	"golang.org/x/image/draw"
+)
+
+// layoutBounds returns the smallest area holding all the given areas
+func layoutBounds(rects []image.Rectangle) image.Rectangle {
+	var bounds image.Rectangle
+	for _, rect := range rects {
+		bounds = bounds.Union(rect)
+	}
+
+	return bounds
+}
+
+// bezelRects moves the monitor areas apart by the given gap between adjacent monitors, so that an
+// image spanned across them lines up as if it continued behind their bezels
+// Each monitor is moved by one gap per distinct edge of other monitors on its left or above it
+func bezelRects(rects []image.Rectangle, gap int) []image.Rectangle {
+	shifted := make([]image.Rectangle, len(rects))
+	for i, rect := range rects {
+		rightEdges := make(map[int]bool)
+		bottomEdges := make(map[int]bool)
+
+		for _, other := range rects {
+			if other.Max.X <= rect.Min.X {
+				rightEdges[other.Max.X] = true
+			}
+
+			if other.Max.Y <= rect.Min.Y {
+				bottomEdges[other.Max.Y] = true
+			}
+		}
+
+		shifted[i] = rect.Add(image.Pt(len(rightEdges)*gap, len(bottomEdges)*gap))
+	}
+
+	return shifted
+}
+
+// composeDistinct draws each image on the area of its monitor, cropped around its most detailed
+// part to fill it
+func composeDistinct(images []image.Image, rects []image.Rectangle) *image.RGBA {
+	canvas := image.NewRGBA(layoutBounds(rects))
+	for i, rect := range rects {
+		tile := imaging.FocusFill(images[i], rect.Dx(), rect.Dy(), imaging.DefaultInterpolator)
+		draw.Draw(canvas, rect, tile, image.Point{}, draw.Src)
+	}
+
+	return canvas
+}
+
+// composeSpan draws a single image across all monitors, leaving out the parts hidden behind the
+// gap between adjacent monitors
+func composeSpan(img image.Image, rects []image.Rectangle, gap int) *image.RGBA {
+	virtualRects := bezelRects(rects, gap)
+	virtualBounds := layoutBounds(virtualRects)
+	spanned := imaging.FocusFill(
+		img,
+		virtualBounds.Dx(),
+		virtualBounds.Dy(),
+		imaging.DefaultInterpolator,
+	)
+
+	canvas := image.NewRGBA(layoutBounds(rects))
+	for i, rect := range rects {
+		draw.Draw(canvas, rect, spanned, virtualRects[i].Min.Sub(virtualBounds.Min), draw.Src)
+	}
+
+	return canvas
+}
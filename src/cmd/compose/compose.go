@@ -0,0 +1,233 @@
+/*
+Copyright © 2024 Nicolas Goudry <goudry.nicolas@gmail.com>
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package compose
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"image/jpeg"
+	"os"
+	"slices"
+
+	"earth-view/cmd"
+	"earth-view/cmd/fetch"
+	"earth-view/lib"
+	"earth-view/lib/screen"
+
+	"github.com/spf13/cobra"
+)
+
+const (
+	// composeQuality is the JPEG quality of composed images
+	composeQuality = 92
+	// maxRandomAttempts is the number of random picks allowed per monitor to find distinct images
+	maxRandomAttempts = 5
+)
+
+var (
+	bezel  int
+	dir    string
+	input  string
+	layout string
+	output string
+	span   bool
+
+	composeCmd = &cobra.Command{
+		Use:   "compose [file|identifier|random]...",
+		Short: "Compose a background for multiple monitors",
+		Long: `Compose a single background image for a multi-monitor desktop.
+
+Description:
+  This command composes an image covering all the monitors of the desktop, for
+  backends that only accept a single file spanned across monitors. The path of
+  the composed image is printed to the standard output. It is saved as
+  'compose.jpeg' in the current working directory by default, which can be
+  changed with the '--output' flag.
+
+  By default, a different image is drawn on each monitor, cropped to fill it
+  around its most detailed part. Images are given as arguments, in the order of
+  monitors, as either a file, an identifier or 'random'. Random images are used
+  for monitors without an argument. When the '--span' flag is set, a single
+  image is spanned across all monitors instead. The '--bezel' flag sets the
+  number of pixels hidden between adjacent monitors by their bezels, which are
+  left out of the image so that it lines up across monitors.
+
+  Monitors are detected with wlr-randr on Wayland or xrandr otherwise. Their
+  geometries can be given explicitly with the '--layout' flag instead, as a
+  comma separated list formatted as xrandr does, e.g.
+  '1920x1080+0+0,2560x1440+1920+0'.
+
+  Images which are not yet on the filesystem are downloaded to the directory
+  set by the '--dir' flag, defaulting to the current working directory. The
+  '--input' flag can be used to provide a file containing the output of the
+  'list' command to choose random images from. Random images can be restricted
+  by the selection flags, described in the help of the 'fetch random' command.`,
+		DisableFlagsInUseLine: true,
+		SilenceUsage:          true,
+		Run: func(_ *cobra.Command, args []string) {
+			filePath, err := runComposeCmd(args, layout, span, bezel, output)
+			cobra.CheckErr(err)
+			fmt.Println(filePath)
+		},
+	}
+)
+
+func init() {
+	cmd.RootCmd.AddCommand(composeCmd)
+
+	composeCmd.Flags().
+		StringVar(&layout, "layout", "auto", "monitor geometries as WxH+X+Y, or 'auto' to detect them")
+	composeCmd.Flags().BoolVar(&span, "span", false, "span a single image across all monitors")
+	composeCmd.Flags().IntVar(&bezel, "bezel", 0, "pixels hidden between adjacent monitors")
+	composeCmd.Flags().
+		StringVarP(&output, "output", "o", "", "write image to given file or directory")
+	composeCmd.Flags().StringVar(&dir, "dir", ".", "directory to download images to")
+	composeCmd.Flags().
+		StringVarP(&input, "input", "i", "", "input file to choose random images from")
+	fetch.AddSelectionFlags(composeCmd.Flags())
+}
+
+// parseLayout returns the monitors given by the '--layout' flag, or the detected ones with 'auto'
+func parseLayout(value string) ([]screen.Monitor, error) {
+	if value == "auto" {
+		return screen.Detect(os.Getenv, screen.Run)
+	}
+
+	return screen.ParseLayout(value)
+}
+
+// resolveImages returns the paths of the given number of images, using random images once sources
+// are exhausted
+func resolveImages(sources []string, count int) ([]string, error) {
+	var filePaths []string
+	for i := 0; i < count; i++ {
+		var filePath string
+		var err error
+
+		if i < len(sources) && sources[i] != "random" {
+			filePath, err = fetch.Resolve(sources[i], input, dir, false)
+		} else {
+			filePath, err = resolveRandomImage(filePaths)
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		filePaths = append(filePaths, filePath)
+	}
+
+	return filePaths, nil
+}
+
+// resolveRandomImage returns the path of a random image, picked again when it is already used as
+// long as attempts remain
+func resolveRandomImage(used []string) (string, error) {
+	var filePath string
+	var err error
+
+	for attempt := 0; attempt < maxRandomAttempts; attempt++ {
+		filePath, err = fetch.Resolve("random", input, dir, false)
+		if err != nil || !slices.Contains(used, filePath) {
+			break
+		}
+	}
+
+	return filePath, err
+}
+
+// readImage decodes a JPEG image file
+func readImage(filePath string) (image.Image, error) {
+	content, err := os.ReadFile(filePath)
+	if err != nil {
+		return nil, err
+	}
+
+	img, err := jpeg.Decode(bytes.NewReader(content))
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode %s: %s", filePath, err)
+	}
+
+	return img, nil
+}
+
+func runComposeCmd(
+	sources []string,
+	layout string,
+	span bool,
+	bezel int,
+	output string,
+) (string, error) {
+	if bezel < 0 {
+		return "", fmt.Errorf("invalid bezel: %d. Expected a number of pixels", bezel)
+	}
+
+	monitors, err := parseLayout(layout)
+	if err != nil {
+		return "", err
+	}
+
+	count := len(monitors)
+	if span {
+		count = 1
+	}
+
+	if len(sources) > count {
+		return "", fmt.Errorf("too many images: %d given for %d monitors", len(sources), count)
+	}
+
+	filePaths, err := resolveImages(sources, count)
+	if err != nil {
+		return "", err
+	}
+
+	var images []image.Image
+	for _, filePath := range filePaths {
+		img, err := readImage(filePath)
+		if err != nil {
+			return "", err
+		}
+
+		images = append(images, img)
+	}
+
+	rects := screen.Rects(monitors)
+
+	var canvas *image.RGBA
+	if span {
+		canvas = composeSpan(images[0], rects, bezel)
+	} else {
+		canvas = composeDistinct(images, rects)
+	}
+
+	var content bytes.Buffer
+	if err := jpeg.Encode(&content, canvas, &jpeg.Options{Quality: composeQuality}); err != nil {
+		return "", err
+	}
+
+	absPath, err := lib.ResolveAbsFilePath(output, "compose.jpeg")
+	if err != nil {
+		return "", err
+	}
+
+	return absPath, lib.WriteFile(content.Bytes(), absPath)
+}
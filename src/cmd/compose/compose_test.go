@@ -0,0 +1,142 @@
+package compose
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// plainImage returns an image of a single color
+func plainImage(c color.Color) image.Image {
+	img := image.NewRGBA(image.Rect(0, 0, 180, 120))
+	for y := 0; y < 120; y++ {
+		for x := 0; x < 180; x++ {
+			img.Set(x, y, c)
+		}
+	}
+
+	return img
+}
+
+func TestBezelRects(t *testing.T) {
+	rects := bezelRects([]image.Rectangle{
+		image.Rect(0, 0, 100, 50),
+		image.Rect(100, 0, 200, 50),
+		image.Rect(100, 50, 200, 100),
+		image.Rect(200, 0, 300, 50),
+	}, 10)
+
+	expected := []image.Rectangle{
+		image.Rect(0, 0, 100, 50),
+		image.Rect(110, 0, 210, 50),
+		image.Rect(110, 60, 210, 110),
+		image.Rect(220, 0, 320, 50),
+	}
+	for i := range expected {
+		if rects[i] != expected[i] {
+			t.Fatalf("Expected monitor %d at %v, got %v", i, expected[i], rects[i])
+		}
+	}
+}
+
+func TestComposeDistinct(t *testing.T) {
+	red := color.RGBA{R: 0xff, A: 0xff}
+	blue := color.RGBA{B: 0xff, A: 0xff}
+	rects := []image.Rectangle{image.Rect(0, 20, 60, 60), image.Rect(60, 0, 90, 60)}
+
+	canvas := composeDistinct([]image.Image{plainImage(red), plainImage(blue)}, rects)
+	if bounds := canvas.Bounds(); bounds != image.Rect(0, 0, 90, 60) {
+		t.Fatalf("Expected canvas covering all monitors, got %v", bounds)
+	}
+
+	for _, tc := range []struct {
+		x, y     int
+		expected color.RGBA
+	}{
+		{10, 30, red},
+		{70, 30, blue},
+		{10, 10, color.RGBA{}},
+	} {
+		if c := canvas.RGBAAt(tc.x, tc.y); c != tc.expected {
+			t.Fatalf("Expected %v at (%d,%d), got %v", tc.expected, tc.x, tc.y, c)
+		}
+	}
+}
+
+func TestComposeSpan(t *testing.T) {
+	// Left half is red and right half is blue
+	src := image.NewRGBA(image.Rect(0, 0, 200, 100))
+	for y := 0; y < 100; y++ {
+		for x := 0; x < 200; x++ {
+			if x < 100 {
+				src.Set(x, y, color.RGBA{R: 0xff, A: 0xff})
+			} else {
+				src.Set(x, y, color.RGBA{B: 0xff, A: 0xff})
+			}
+		}
+	}
+
+	// The gap hides the middle of the image, so that each monitor shows a single color
+	rects := []image.Rectangle{image.Rect(0, 0, 80, 100), image.Rect(80, 0, 160, 100)}
+	canvas := composeSpan(src, rects, 40)
+
+	if bounds := canvas.Bounds(); bounds != image.Rect(0, 0, 160, 100) {
+		t.Fatalf("Expected canvas covering all monitors, got %v", bounds)
+	}
+
+	if c := canvas.RGBAAt(78, 50); c.R < 0xf0 || c.B > 0x10 {
+		t.Fatalf("Expected red on the right edge of the left monitor, got %v", c)
+	}
+
+	if c := canvas.RGBAAt(82, 50); c.B < 0xf0 || c.R > 0x10 {
+		t.Fatalf("Expected blue on the left edge of the right monitor, got %v", c)
+	}
+}
+
+func TestRunComposeCmd(t *testing.T) {
+	dir := t.TempDir()
+
+	var sources []string
+	for i, c := range []color.Color{color.White, color.Black} {
+		var content bytes.Buffer
+		if err := jpeg.Encode(&content, plainImage(c), nil); err != nil {
+			t.Fatalf("Failed to encode image: %s", err)
+		}
+
+		source := filepath.Join(dir, string(rune('a'+i))+".jpeg")
+		if err := os.WriteFile(source, content.Bytes(), 0644); err != nil {
+			t.Fatalf("Failed to write image: %s", err)
+		}
+
+		sources = append(sources, source)
+	}
+
+	output := filepath.Join(dir, "out.jpeg")
+	filePath, err := runComposeCmd(sources, "64x48+0+0,48x64+64+0", false, 0, output)
+	if err != nil {
+		t.Fatalf("Expected image to be composed, got error: %s", err)
+	}
+
+	file, err := os.Open(filePath)
+	if err != nil {
+		t.Fatalf("Expected composed image to be written, got error: %s", err)
+	}
+	defer file.Close()
+
+	config, err := jpeg.DecodeConfig(file)
+	if err != nil || config.Width != 112 || config.Height != 64 {
+		t.Fatalf("Expected 112x64 image, got %dx%d (%v)", config.Width, config.Height, err)
+	}
+
+	if _, err := runComposeCmd(sources, "64x48+0+0,48x64+64+0", true, 0, output); err == nil {
+		t.Fatalf("Expected error for more images than spanned")
+	}
+
+	if _, err := runComposeCmd(sources[:1], "64x48+0+0", false, -1, output); err == nil {
+		t.Fatalf("Expected error for negative bezel")
+	}
+}
@@ -0,0 +1,154 @@
+/*
+Copyright © 2024 Nicolas Goudry <goudry.nicolas@gmail.com>
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package daemon
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// Commands accepted on the control socket
+var controlCommands = []string{"next", "prev", "pause", "resume", "status"}
+
+// request is sent by clients on the control socket
+type request struct {
+	Command string `json:"command"`
+}
+
+// response is sent by the daemon in reply to a request
+type response struct {
+	Status *status `json:"status,omitempty"`
+	Error  string  `json:"error,omitempty"`
+}
+
+// status describes the state of the daemon
+type status struct {
+	Paused     bool       `json:"paused"`
+	Backend    string     `json:"backend"`
+	Current    string     `json:"current,omitempty"`
+	Prefetched string     `json:"prefetched,omitempty"`
+	NextChange *time.Time `json:"nextChange,omitempty"`
+}
+
+// listen creates the control socket
+// A leftover socket file is removed, unless another daemon is listening on it
+func listen(socketPath string) (net.Listener, error) {
+	if conn, err := net.Dial("unix", socketPath); err == nil {
+		conn.Close()
+		return nil, fmt.Errorf("a daemon is already listening on %s", socketPath)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(socketPath), 0700); err != nil {
+		return nil, err
+	}
+
+	if err := os.Remove(socketPath); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return nil, err
+	}
+
+	return net.Listen("unix", socketPath)
+}
+
+// serve handles requests from the control socket until the listener is closed
+func (d *daemon) serve(listener net.Listener) {
+	for {
+		conn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+
+		go d.handleConn(conn)
+	}
+}
+
+// handleConn reads a single request from the connection and writes the response
+func (d *daemon) handleConn(conn net.Conn) {
+	defer conn.Close()
+
+	var req request
+	if err := json.NewDecoder(conn).Decode(&req); err != nil {
+		log.Printf("invalid control request: %s", err)
+		return
+	}
+
+	if err := json.NewEncoder(conn).Encode(d.handle(req.Command)); err != nil {
+		log.Printf("failed to send control response: %s", err)
+	}
+}
+
+// handle executes a control command and returns the resulting daemon status
+func (d *daemon) handle(command string) response {
+	var err error
+
+	switch command {
+	case "next":
+		_, err = d.next()
+		d.requestWake()
+	case "prev":
+		_, err = d.prev()
+		d.requestWake()
+	case "pause":
+		d.setPaused(true)
+	case "resume":
+		d.setPaused(false)
+	case "status":
+	default:
+		err = fmt.Errorf("unknown command: %s", command)
+	}
+
+	if err != nil {
+		return response{Error: err.Error()}
+	}
+
+	s := d.status()
+	return response{Status: &s}
+}
+
+// sendCommand sends a command to the daemon listening on the given socket and returns its status
+func sendCommand(socketPath string, command string) (*status, error) {
+	conn, err := net.Dial("unix", socketPath)
+	if err != nil {
+		return nil, fmt.Errorf("daemon is not reachable: %s", err)
+	}
+	defer conn.Close()
+
+	if err := json.NewEncoder(conn).Encode(request{Command: command}); err != nil {
+		return nil, err
+	}
+
+	var resp response
+	if err := json.NewDecoder(conn).Decode(&resp); err != nil {
+		return nil, fmt.Errorf("invalid response from daemon: %s", err)
+	}
+
+	if resp.Error != "" {
+		return nil, errors.New(resp.Error)
+	}
+
+	return resp.Status, nil
+}
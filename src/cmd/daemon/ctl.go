@@ -0,0 +1,123 @@
+/*
+Copyright © 2024 Nicolas Goudry <goudry.nicolas@gmail.com>
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package daemon
+
+import (
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"earth-view/cmd"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	jsonOutput bool
+
+	ctlCmd = &cobra.Command{
+		Use:   "ctl next|prev|pause|resume|status",
+		Short: "Control background rotation",
+		Long: `Send a command to a running background rotation daemon.
+
+Description:
+  This command controls the daemon started with the 'daemon' command, through
+  its control socket. The following commands are available:
+
+  - next: set the next background, which is downloaded in advance
+  - prev: set the previous background
+  - pause: stop changing background according to the schedule
+  - resume: start changing background according to the schedule again
+  - status: show the state of the daemon
+
+  The state of the daemon is output after each command. Use the '--json' flag
+  to output it in JSON format.`,
+		DisableFlagsInUseLine: true,
+		SilenceUsage:          true,
+		ValidArgs:             controlCommands,
+		Args: func(cmd *cobra.Command, args []string) error {
+			if len(args) != 1 || !slices.Contains(controlCommands, args[0]) {
+				return fmt.Errorf(
+					"expected exactly one command among: %s",
+					strings.Join(controlCommands, ", "),
+				)
+			}
+
+			return nil
+		},
+		Run: func(_ *cobra.Command, args []string) {
+			out, err := runCtlCmd(args[0], socketPath, jsonOutput)
+			cobra.CheckErr(err)
+			fmt.Println(out)
+		},
+	}
+)
+
+func init() {
+	cmd.RootCmd.AddCommand(ctlCmd)
+
+	ctlCmd.Flags().BoolVar(&jsonOutput, "json", false, "output daemon state in JSON format")
+	ctlCmd.Flags().
+		StringVar(&socketPath, "socket", defaultSocketPath(), "path of the control socket")
+}
+
+func runCtlCmd(command string, socketPath string, jsonOutput bool) (string, error) {
+	s, err := sendCommand(socketPath, command)
+	if err != nil {
+		return "", err
+	}
+
+	if jsonOutput {
+		out, err := json.Marshal(s)
+		return string(out), err
+	}
+
+	return formatStatus(s), nil
+}
+
+// formatStatus returns a human readable representation of the daemon status
+func formatStatus(s *status) string {
+	state := "running"
+	if s.Paused {
+		state = "paused"
+	}
+
+	nextChange := "none"
+	if s.NextChange != nil {
+		nextChange = s.NextChange.Format(time.DateTime)
+	}
+
+	prefetched := s.Prefetched
+	if prefetched == "" {
+		prefetched = "none"
+	}
+
+	return strings.Join([]string{
+		"State: " + state,
+		"Backend: " + s.Backend,
+		"Current: " + s.Current,
+		"Prefetched: " + prefetched,
+		"Next change: " + nextChange,
+	}, "\n")
+}
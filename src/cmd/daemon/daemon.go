@@ -0,0 +1,467 @@
+/*
+Copyright © 2024 Nicolas Goudry <goudry.nicolas@gmail.com>
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package daemon
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"sync"
+	"syscall"
+	"time"
+
+	"earth-view/cmd"
+	"earth-view/cmd/fetch"
+	"earth-view/cmd/set"
+	"earth-view/lib"
+	"earth-view/lib/wallpaper"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	backendFlags set.BackendFlags
+	cronExpr     string
+	input        string
+	interval     time.Duration
+	onResume     bool
+	output       string
+	socketPath   string
+
+	daemonCmd = &cobra.Command{
+		Use:   "daemon",
+		Short: "Rotate desktop background",
+		Long: fmt.Sprintf(
+			`Periodically set the desktop background to a random Google Earth View image.
+
+Description:
+  This command runs in the foreground and sets the desktop background to a
+  random image on start, then according to its schedule. The next image is
+  always downloaded in advance, so that switching background is instant.
+
+  Random images are picked the same way as the 'fetch random' command does, and
+  are saved in the directory set by the '--output' flag, which defaults to the
+  current working directory. A '.current' symbolic link to the current
+  background is maintained in this directory.
+
+Schedule:
+  The background can be changed at a fixed interval with the '--interval' flag,
+  or at times matching a cron expression with the '--schedule' flag. Cron
+  expressions use the standard 5 fields format (minute, hour, day of month,
+  month and day of week) and macros such as '@hourly' or '@daily'.
+
+  The '--on-resume' flag makes the background change when the system resumes
+  from suspend. Without any of these flags, the background only changes on
+  start and on request.
+
+Control:
+  The daemon listens for requests on a Unix socket, which can be sent with the
+  'ctl' command. Default socket is '$XDG_RUNTIME_DIR/earth-view/daemon.sock'.
+
+Backends:
+%s`,
+			set.BackendHelp,
+		),
+		DisableFlagsInUseLine: true,
+		SilenceUsage:          true,
+		Args:                  cobra.MaximumNArgs(0),
+		PreRunE: func(_ *cobra.Command, _ []string) error {
+			if interval != 0 && cronExpr != "" {
+				return fmt.Errorf("--interval and --schedule cannot be provided together")
+			}
+
+			return nil
+		},
+		Run: func(_ *cobra.Command, _ []string) {
+			cobra.CheckErr(runDaemonCmd())
+		},
+	}
+)
+
+func init() {
+	cmd.RootCmd.AddCommand(daemonCmd)
+
+	backendFlags.AddFlags(daemonCmd.Flags())
+	fetch.AddSelectionFlags(daemonCmd.Flags())
+	daemonCmd.Flags().StringVarP(&input, "input", "i", "", "input file to choose images from")
+	daemonCmd.Flags().DurationVar(&interval, "interval", 0, "duration between background changes")
+	daemonCmd.Flags().
+		BoolVar(&onResume, "on-resume", false, "change background when resuming from suspend")
+	daemonCmd.Flags().StringVarP(&output, "output", "o", "", "write images to given directory")
+	daemonCmd.Flags().StringVar(&cronExpr, "schedule", "", "cron expression of background changes")
+	daemonCmd.Flags().
+		StringVar(&socketPath, "socket", defaultSocketPath(), "path of the control socket")
+}
+
+// defaultSocketPath returns the default path of the daemon control socket
+func defaultSocketPath() string {
+	return filepath.Join(lib.RuntimeDir(), "earth-view", "daemon.sock")
+}
+
+// prefetchResult holds the outcome of downloading the next image in advance
+type prefetchResult struct {
+	file string
+	err  error
+}
+
+// daemon holds the state of the background rotation
+type daemon struct {
+	backend wallpaper.Backend
+	opts    wallpaper.Options
+	// pick downloads a random image and returns its path
+	pick func() (string, error)
+	// linkDir is the directory holding the '.current' link, if any
+	linkDir string
+	// wake is used to make the event loop compute the next change again
+	wake chan struct{}
+
+	mu         sync.Mutex
+	paused     bool
+	prefetch   chan prefetchResult
+	prefetched *prefetchResult
+	nextChange time.Time
+}
+
+func newDaemon(
+	backend wallpaper.Backend,
+	opts wallpaper.Options,
+	pick func() (string, error),
+) *daemon {
+	return &daemon{
+		backend: backend,
+		opts:    opts,
+		pick:    pick,
+		wake:    make(chan struct{}, 1),
+	}
+}
+
+func runDaemonCmd() error {
+	opts, err := backendFlags.Options()
+	if err != nil {
+		return err
+	}
+
+	backend, err := backendFlags.Backend(wallpaper.ExecRunner{})
+	if err != nil {
+		return err
+	}
+
+	var sched schedule
+	if interval > 0 {
+		sched = intervalSchedule(interval)
+	} else if cronExpr != "" {
+		if sched, err = parseCron(cronExpr); err != nil {
+			return err
+		}
+	}
+
+	outDir, err := filepath.Abs(output)
+	if err != nil {
+		return err
+	}
+
+	if stat, err := os.Stat(outDir); err != nil || !stat.IsDir() {
+		return fmt.Errorf("output must be an existing directory")
+	}
+
+	d := newDaemon(backend, opts, func() (string, error) {
+		return fetch.FetchRandom(input, outDir, false)
+	})
+	d.linkDir = outDir
+
+	listener, err := listen(socketPath)
+	if err != nil {
+		return err
+	}
+	defer listener.Close()
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	go d.serve(listener)
+
+	resumed := make(chan struct{}, 1)
+	go watchResume(ctx, resumeCheckInterval, resumed)
+
+	if _, err := d.change(); err != nil {
+		log.Printf("failed to set background: %s", err)
+	}
+
+	d.run(ctx, sched, resumed)
+
+	return nil
+}
+
+// run changes the background according to the schedule, until the context is done
+func (d *daemon) run(ctx context.Context, sched schedule, resumed <-chan struct{}) {
+	d.reschedule(sched, time.Now())
+
+	for {
+		d.mu.Lock()
+		nextChange := d.nextChange
+		d.mu.Unlock()
+
+		var timer *time.Timer
+		var timerCh <-chan time.Time
+		if !nextChange.IsZero() {
+			timer = time.NewTimer(time.Until(nextChange))
+			timerCh = timer.C
+		}
+
+		change := false
+		select {
+		case <-ctx.Done():
+			if timer != nil {
+				timer.Stop()
+			}
+			return
+		case <-timerCh:
+			change = true
+		case <-resumed:
+			// Timers do not run while the system is suspended, scheduled changes may be overdue
+			change = onResume || (!nextChange.IsZero() && !time.Now().Before(nextChange))
+		case <-d.wake:
+			// The background was changed on request, the interval starts over
+			d.reschedule(sched, time.Now())
+		}
+
+		if timer != nil {
+			timer.Stop()
+		}
+
+		if change {
+			d.mu.Lock()
+			paused := d.paused
+			d.mu.Unlock()
+
+			if !paused {
+				if _, err := d.change(); err != nil {
+					log.Printf("failed to set background: %s", err)
+				}
+			}
+
+			d.reschedule(sched, time.Now())
+		}
+	}
+}
+
+// reschedule computes the next scheduled change
+func (d *daemon) reschedule(sched schedule, now time.Time) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if sched == nil {
+		d.nextChange = time.Time{}
+	} else {
+		d.nextChange = sched.next(now)
+	}
+}
+
+// requestWake makes the event loop compute the next change again, without blocking
+func (d *daemon) requestWake() {
+	select {
+	case d.wake <- struct{}{}:
+	default:
+	}
+}
+
+// change sets the prefetched random image as background
+func (d *daemon) change() (string, error) {
+	// The lock is not held while waiting for the download, so that the status can be queried
+	file, err := d.takePrefetched()
+	if err != nil {
+		return "", err
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	// Prefetch the next image whatever the outcome, to avoid trying the same image again
+	defer d.startPrefetch()
+
+	if err := d.show(file, true); err != nil {
+		return "", err
+	}
+
+	return file, nil
+}
+
+// next sets the next background from history if previous backgrounds were requested, or the
+// prefetched random image otherwise
+func (d *daemon) next() (string, error) {
+	d.mu.Lock()
+
+	history, err := lib.LoadHistory()
+	if err != nil {
+		d.mu.Unlock()
+		return "", err
+	}
+
+	if history.Position >= len(history.Entries)-1 {
+		// Changing the background loads history again once the next image is downloaded
+		d.mu.Unlock()
+		return d.change()
+	}
+
+	defer d.mu.Unlock()
+
+	return d.navigate(history, 1)
+}
+
+// prev sets the previous background from history
+func (d *daemon) prev() (string, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	history, err := lib.LoadHistory()
+	if err != nil {
+		return "", err
+	}
+
+	return d.navigate(history, -1)
+}
+
+// navigate moves through history by the given offset and sets the matching background
+// The lock must be held since history was loaded, so that no change is saved in between
+func (d *daemon) navigate(history *lib.History, offset int) (string, error) {
+	if _, err := history.Move(offset); err != nil {
+		return "", err
+	}
+
+	entry, _ := history.Current()
+
+	// Garbage collected images are downloaded again, unless they cannot be derived again
+	if entry.Id != 0 {
+		if err := fetch.Restore(entry.Id, entry.Path); err != nil {
+			history.Move(-offset)
+			return "", err
+		}
+	}
+
+	if err := d.show(entry.Path, false); err != nil {
+		history.Move(-offset)
+		return "", err
+	}
+
+	return entry.Path, nil
+}
+
+// show sets the given file as background, records it in history if requested and updates the
+// '.current' link
+func (d *daemon) show(file string, record bool) error {
+	err := wallpaper.Show(d.backend, file, d.opts, record, d.linkDir)
+	if errors.Is(err, wallpaper.ErrNotTracked) {
+		// The background is set, failing to track it is not fatal
+		log.Print(err)
+		return nil
+	}
+
+	return err
+}
+
+// startPrefetch downloads the next random image in the background
+func (d *daemon) startPrefetch() {
+	ch := make(chan prefetchResult, 1)
+	d.prefetch = ch
+	d.prefetched = nil
+
+	go func() {
+		file, err := d.pick()
+		ch <- prefetchResult{file: file, err: err}
+	}()
+}
+
+// pollPrefetch saves the prefetch result if it is available, without waiting for it
+func (d *daemon) pollPrefetch() {
+	if d.prefetch == nil {
+		return
+	}
+
+	select {
+	case result := <-d.prefetch:
+		d.prefetched = &result
+		d.prefetch = nil
+	default:
+	}
+}
+
+// takePrefetched returns the prefetched image, waiting for it if needed, or downloads a new one
+// if prefetching failed or never started
+// It must be called without holding the lock, which is only taken to swap the prefetch state
+func (d *daemon) takePrefetched() (string, error) {
+	d.mu.Lock()
+	prefetch, result := d.prefetch, d.prefetched
+	d.prefetch, d.prefetched = nil, nil
+	d.mu.Unlock()
+
+	if prefetch != nil {
+		prefetched := <-prefetch
+		result = &prefetched
+	}
+
+	if result != nil && result.err == nil {
+		return result.file, nil
+	}
+
+	return d.pick()
+}
+
+// setPaused pauses or resumes scheduled background changes
+func (d *daemon) setPaused(paused bool) {
+	d.mu.Lock()
+	d.paused = paused
+	d.mu.Unlock()
+}
+
+// status returns the current state of the daemon
+func (d *daemon) status() status {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	d.pollPrefetch()
+
+	s := status{
+		Paused:  d.paused,
+		Backend: d.backend.Name(),
+	}
+
+	if history, err := lib.LoadHistory(); err == nil {
+		if entry, ok := history.Current(); ok {
+			s.Current = entry.Path
+		}
+	}
+
+	if d.prefetched != nil && d.prefetched.err == nil {
+		s.Prefetched = d.prefetched.file
+	}
+
+	if !d.nextChange.IsZero() {
+		nextChange := d.nextChange
+		s.NextChange = &nextChange
+	}
+
+	return s
+}
@@ -0,0 +1,238 @@
+package daemon
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"earth-view/lib"
+	"earth-view/lib/wallpaper"
+)
+
+// fakeBackend records the backgrounds it is asked to set
+type fakeBackend struct {
+	mu    sync.Mutex
+	files []string
+}
+
+func (b *fakeBackend) Name() string {
+	return "fake"
+}
+
+func (b *fakeBackend) Set(file string, _ wallpaper.Options) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.files = append(b.files, file)
+	return nil
+}
+
+func (b *fakeBackend) last() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if len(b.files) == 0 {
+		return ""
+	}
+
+	return b.files[len(b.files)-1]
+}
+
+// newTestDaemon creates a daemon picking images named after a counter in a temporary directory
+func newTestDaemon(t *testing.T) (*daemon, *fakeBackend) {
+	t.Setenv("XDG_STATE_HOME", t.TempDir())
+
+	dir := t.TempDir()
+	backend := &fakeBackend{}
+
+	var mu sync.Mutex
+	count := 0
+
+	d := newDaemon(backend, wallpaper.Options{Display: "fill"}, func() (string, error) {
+		mu.Lock()
+		defer mu.Unlock()
+
+		count++
+		file := filepath.Join(dir, strconv.Itoa(count)+".jpeg")
+		return file, os.WriteFile(file, nil, 0644)
+	})
+	d.linkDir = dir
+
+	return d, backend
+}
+
+func TestDaemonNextPrev(t *testing.T) {
+	d, backend := newTestDaemon(t)
+
+	first, err := d.change()
+	if err != nil {
+		t.Fatalf("Expected success, got error: %v", err)
+	}
+
+	second, err := d.next()
+	if err != nil {
+		t.Fatalf("Expected success, got error: %v", err)
+	}
+
+	if first == second {
+		t.Fatalf("Expected a new background, got %s twice", first)
+	}
+
+	if got, err := d.prev(); err != nil || got != first {
+		t.Fatalf("Expected previous background %s, got %s (error: %v)", first, got, err)
+	}
+
+	if got, err := d.next(); err != nil || got != second {
+		t.Fatalf("Expected next background from history %s, got %s (error: %v)", second, got, err)
+	}
+
+	if backend.last() != second {
+		t.Fatalf("Expected backend to set %s, got %s", second, backend.last())
+	}
+
+	link, err := os.Readlink(filepath.Join(d.linkDir, ".current"))
+	if err != nil || link != second {
+		t.Fatalf("Expected current link to point to %s, got %s (error: %v)", second, link, err)
+	}
+}
+
+func TestDaemonPrevWithoutHistory(t *testing.T) {
+	d, _ := newTestDaemon(t)
+
+	if _, err := d.prev(); err == nil {
+		t.Fatal("Expected error, got success")
+	}
+}
+
+func TestDaemonPrefetch(t *testing.T) {
+	d, _ := newTestDaemon(t)
+
+	if _, err := d.change(); err != nil {
+		t.Fatalf("Expected success, got error: %v", err)
+	}
+
+	// Wait for the prefetch to complete
+	deadline := time.Now().Add(2 * time.Second)
+	for d.status().Prefetched == "" && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	prefetched := d.status().Prefetched
+	if prefetched == "" {
+		t.Fatal("Expected next background to be prefetched")
+	}
+
+	if got, err := d.next(); err != nil || got != prefetched {
+		t.Fatalf("Expected prefetched background %s, got %s (error: %v)", prefetched, got, err)
+	}
+}
+
+func TestDaemonStatusDuringDownload(t *testing.T) {
+	d, _ := newTestDaemon(t)
+
+	release := make(chan struct{})
+	pick := d.pick
+	d.pick = func() (string, error) {
+		<-release
+		return pick()
+	}
+
+	changed := make(chan error, 1)
+	go func() {
+		_, err := d.change()
+		changed <- err
+	}()
+
+	statusDone := make(chan struct{})
+	go func() {
+		d.status()
+		close(statusDone)
+	}()
+
+	select {
+	case <-statusDone:
+	case <-time.After(2 * time.Second):
+		t.Fatal("Expected status not to wait for the download")
+	}
+
+	close(release)
+	if err := <-changed; err != nil {
+		t.Fatalf("Expected success, got error: %v", err)
+	}
+}
+
+func TestDaemonSchedule(t *testing.T) {
+	d, backend := newTestDaemon(t)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	done := make(chan struct{})
+	go func() {
+		d.run(ctx, intervalSchedule(20*time.Millisecond), nil)
+		close(done)
+	}()
+
+	deadline := time.Now().Add(2 * time.Second)
+	for backend.last() == "" && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	if backend.last() == "" {
+		t.Fatal("Expected background to be changed on schedule")
+	}
+
+	cancel()
+	<-done
+}
+
+func TestDaemonControlSocket(t *testing.T) {
+	d, backend := newTestDaemon(t)
+	socketPath := filepath.Join(t.TempDir(), "daemon.sock")
+
+	listener, err := listen(socketPath)
+	if err != nil {
+		t.Fatalf("Failed to listen on control socket: %v", err)
+	}
+	defer listener.Close()
+
+	go d.serve(listener)
+
+	if _, err := listen(socketPath); err == nil {
+		t.Fatal("Expected error when another daemon is listening, got success")
+	}
+
+	s, err := sendCommand(socketPath, "next")
+	if err != nil {
+		t.Fatalf("Expected success, got error: %v", err)
+	}
+
+	if s.Current == "" || s.Current != backend.last() {
+		t.Fatalf("Expected current background to be %s, got %s", backend.last(), s.Current)
+	}
+
+	if s, err = sendCommand(socketPath, "pause"); err != nil || !s.Paused {
+		t.Fatalf("Expected daemon to be paused (error: %v)", err)
+	}
+
+	if s, err = sendCommand(socketPath, "resume"); err != nil || s.Paused {
+		t.Fatalf("Expected daemon to be resumed (error: %v)", err)
+	}
+
+	if _, err := sendCommand(socketPath, "unknown"); err == nil {
+		t.Fatal("Expected error for unknown command, got success")
+	}
+
+	if _, err := sendCommand(socketPath, "prev"); err == nil {
+		t.Fatal("Expected error without previous background, got success")
+	}
+
+	history, err := lib.LoadHistory()
+	if err != nil || len(history.Entries) != 1 || history.Entries[0].Backend != "fake" {
+		t.Fatalf("Expected background to be recorded in history (error: %v)", err)
+	}
+}
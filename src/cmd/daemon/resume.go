@@ -0,0 +1,65 @@
+/*
+Copyright © 2024 Nicolas Goudry <goudry.nicolas@gmail.com>
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package daemon
+
+import (
+	"context"
+	"time"
+)
+
+const (
+	// resumeCheckInterval is the time between two checks for a system resume
+	resumeCheckInterval = 10 * time.Second
+	// resumeThreshold is the minimum gap between clocks to consider that the system was suspended
+	resumeThreshold = 30 * time.Second
+)
+
+// watchResume reports system resumes from suspend in the given channel
+// The monotonic clock does not advance while the system is suspended, unlike the wall clock: a
+// resume is detected when the wall clock moved forward noticeably more than the monotonic clock
+// between two checks
+func watchResume(ctx context.Context, checkInterval time.Duration, resumed chan<- struct{}) {
+	ticker := time.NewTicker(checkInterval)
+	defer ticker.Stop()
+
+	last := time.Now()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			// Round(0) strips the monotonic clock reading, forcing a wall clock comparison
+			wallElapsed := now.Round(0).Sub(last.Round(0))
+			monotonicElapsed := now.Sub(last)
+
+			if wallElapsed-monotonicElapsed > resumeThreshold {
+				select {
+				case resumed <- struct{}{}:
+				default:
+				}
+			}
+
+			last = now
+		}
+	}
+}
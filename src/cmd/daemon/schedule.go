@@ -0,0 +1,230 @@
+/*
+Copyright © 2024 Nicolas Goudry <goudry.nicolas@gmail.com>
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package daemon
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// schedule computes when the background should be changed
+type schedule interface {
+	// next returns the first change time strictly after the given time
+	next(after time.Time) time.Time
+}
+
+// intervalSchedule changes the background at a fixed interval
+type intervalSchedule time.Duration
+
+func (s intervalSchedule) next(after time.Time) time.Time {
+	return after.Add(time.Duration(s))
+}
+
+// cronSchedule changes the background at times matching a cron expression
+// Each field holds the set of allowed values, indexed by value
+type cronSchedule struct {
+	minutes  [60]bool
+	hours    [24]bool
+	days     [32]bool
+	months   [13]bool
+	weekdays [7]bool
+	// anyDay and anyWeekday are set when the matching field is '*', in which case only the other
+	// field restricts days, as cron does
+	anyDay     bool
+	anyWeekday bool
+}
+
+// cronMacros maps cron macros to their equivalent expressions
+var cronMacros = map[string]string{
+	"@hourly":   "0 * * * *",
+	"@daily":    "0 0 * * *",
+	"@midnight": "0 0 * * *",
+	"@weekly":   "0 0 * * 0",
+	"@monthly":  "0 0 1 * *",
+	"@yearly":   "0 0 1 1 *",
+	"@annually": "0 0 1 1 *",
+}
+
+// cronField describes the allowed values of a cron expression field
+type cronField struct {
+	name string
+	min  int
+	max  int
+}
+
+// parseCron parses a standard 5 fields cron expression: minute, hour, day of month, month and day
+// of week. Fields support wildcards, ranges, steps and lists. Macros such as '@daily' are also
+// supported
+func parseCron(expr string) (*cronSchedule, error) {
+	if macro, ok := cronMacros[strings.TrimSpace(expr)]; ok {
+		expr = macro
+	}
+
+	fields := strings.Fields(expr)
+	if len(fields) != 5 {
+		return nil, fmt.Errorf(
+			"invalid cron expression '%s': expected 5 fields, got %d",
+			expr,
+			len(fields),
+		)
+	}
+
+	s := &cronSchedule{
+		anyDay:     fields[2] == "*",
+		anyWeekday: fields[4] == "*",
+	}
+
+	targets := []struct {
+		field  cronField
+		values []bool
+	}{
+		{cronField{"minute", 0, 59}, s.minutes[:]},
+		{cronField{"hour", 0, 23}, s.hours[:]},
+		{cronField{"day of month", 1, 31}, s.days[:]},
+		{cronField{"month", 1, 12}, s.months[:]},
+		// Sunday can be either 0 or 7
+		{cronField{"day of week", 0, 7}, make([]bool, 8)},
+	}
+
+	for i, target := range targets {
+		if err := parseCronField(fields[i], target.field, target.values); err != nil {
+			return nil, fmt.Errorf("invalid cron expression '%s': %s", expr, err)
+		}
+	}
+
+	weekdays := targets[4].values
+	for i := range s.weekdays {
+		s.weekdays[i] = weekdays[i]
+	}
+	s.weekdays[0] = s.weekdays[0] || weekdays[7]
+
+	return s, nil
+}
+
+// parseCronField parses a comma separated list of values, ranges and steps, and marks matching
+// values as allowed
+func parseCronField(expr string, field cronField, values []bool) error {
+	for _, part := range strings.Split(expr, ",") {
+		rangeExpr, stepExpr, hasStep := strings.Cut(part, "/")
+
+		step := 1
+		if hasStep {
+			var err error
+			if step, err = strconv.Atoi(stepExpr); err != nil || step <= 0 {
+				return fmt.Errorf("invalid %s step '%s'", field.name, stepExpr)
+			}
+		}
+
+		start, end := field.min, field.max
+		if rangeExpr != "*" {
+			startExpr, endExpr, isRange := strings.Cut(rangeExpr, "-")
+
+			var err error
+			if start, err = parseCronValue(startExpr, field); err != nil {
+				return err
+			}
+
+			end = start
+			if isRange {
+				if end, err = parseCronValue(endExpr, field); err != nil {
+					return err
+				}
+			} else if hasStep {
+				// A single value with a step, e.g. '5/15', ranges until the maximum value
+				end = field.max
+			}
+
+			if end < start {
+				return fmt.Errorf("invalid %s range '%s'", field.name, rangeExpr)
+			}
+		}
+
+		for value := start; value <= end; value += step {
+			values[value] = true
+		}
+	}
+
+	return nil
+}
+
+// parseCronValue parses a single field value and checks that it is within the field bounds
+func parseCronValue(expr string, field cronField) (int, error) {
+	value, err := strconv.Atoi(expr)
+	if err != nil || value < field.min || value > field.max {
+		return 0, fmt.Errorf(
+			"invalid %s '%s': expected a value between %d and %d",
+			field.name,
+			expr,
+			field.min,
+			field.max,
+		)
+	}
+
+	return value, nil
+}
+
+// matchDay checks whether the given day matches the day of month and day of week fields
+// As in cron, a day matches when either field matches if both fields are restricted
+func (s *cronSchedule) matchDay(t time.Time) bool {
+	day := s.days[t.Day()]
+	weekday := s.weekdays[t.Weekday()]
+
+	switch {
+	case s.anyDay && s.anyWeekday:
+		return true
+	case s.anyDay:
+		return weekday
+	case s.anyWeekday:
+		return day
+	default:
+		return day || weekday
+	}
+}
+
+// cronSearchLimit bounds the search of the next matching time, for expressions which never match
+// such as '0 0 31 2 *'
+const cronSearchLimit = 5 * 366 * 24 * time.Hour
+
+// next returns the next time matching the expression, or the zero time if there is none
+func (s *cronSchedule) next(after time.Time) time.Time {
+	t := after.Truncate(time.Minute).Add(time.Minute)
+	limit := after.Add(cronSearchLimit)
+
+	for t.Before(limit) {
+		switch {
+		case !s.months[t.Month()]:
+			t = time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, t.Location())
+		case !s.matchDay(t):
+			t = time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, t.Location())
+		case !s.hours[t.Hour()]:
+			t = time.Date(t.Year(), t.Month(), t.Day(), t.Hour()+1, 0, 0, 0, t.Location())
+		case !s.minutes[t.Minute()]:
+			t = t.Add(time.Minute)
+		default:
+			return t
+		}
+	}
+
+	return time.Time{}
+}
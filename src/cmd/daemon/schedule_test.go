@@ -0,0 +1,68 @@
+package daemon
+
+import (
+	"testing"
+	"time"
+)
+
+var scheduleStart = time.Date(2024, time.May, 31, 10, 42, 30, 0, time.UTC)
+
+func TestIntervalSchedule(t *testing.T) {
+	got := intervalSchedule(90 * time.Minute).next(scheduleStart)
+	if want := scheduleStart.Add(90 * time.Minute); !got.Equal(want) {
+		t.Fatalf("Expected next change at %v, got %v", want, got)
+	}
+}
+
+func TestCronScheduleNext(t *testing.T) {
+	for expr, want := range map[string]time.Time{
+		"* * * * *":        time.Date(2024, time.May, 31, 10, 43, 0, 0, time.UTC),
+		"*/15 * * * *":     time.Date(2024, time.May, 31, 10, 45, 0, 0, time.UTC),
+		"0 * * * *":        time.Date(2024, time.May, 31, 11, 0, 0, 0, time.UTC),
+		"@daily":           time.Date(2024, time.June, 1, 0, 0, 0, 0, time.UTC),
+		"30 8 * * 1-5":     time.Date(2024, time.June, 3, 8, 30, 0, 0, time.UTC),
+		"0 9,18 * * *":     time.Date(2024, time.May, 31, 18, 0, 0, 0, time.UTC),
+		"0 0 29 2 *":       time.Date(2028, time.February, 29, 0, 0, 0, 0, time.UTC),
+		"0 12 1 * 7":       time.Date(2024, time.June, 1, 12, 0, 0, 0, time.UTC),
+		"0 12 15 * 0":      time.Date(2024, time.June, 2, 12, 0, 0, 0, time.UTC),
+		"5/20 10-12 * * *": time.Date(2024, time.May, 31, 10, 45, 0, 0, time.UTC),
+	} {
+		sched, err := parseCron(expr)
+		if err != nil {
+			t.Fatalf("Expected valid cron expression '%s', got error: %v", expr, err)
+		}
+
+		if got := sched.next(scheduleStart); !got.Equal(want) {
+			t.Fatalf("Expected next change of '%s' at %v, got %v", expr, want, got)
+		}
+	}
+}
+
+func TestCronScheduleNever(t *testing.T) {
+	sched, err := parseCron("0 0 31 2 *")
+	if err != nil {
+		t.Fatalf("Expected valid cron expression, got error: %v", err)
+	}
+
+	if got := sched.next(scheduleStart); !got.IsZero() {
+		t.Fatalf("Expected no next change, got %v", got)
+	}
+}
+
+func TestCronScheduleInvalid(t *testing.T) {
+	for _, expr := range []string{
+		"* * * *",
+		"60 * * * *",
+		"* 24 * * *",
+		"* * 0 * *",
+		"* * * 13 *",
+		"* * * * 8",
+		"*/0 * * * *",
+		"10-5 * * * *",
+		"a * * * *",
+	} {
+		if _, err := parseCron(expr); err == nil {
+			t.Fatalf("Expected error for cron expression '%s', got success", expr)
+		}
+	}
+}
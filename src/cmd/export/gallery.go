@@ -0,0 +1,188 @@
+/*
+Copyright © 2024 Nicolas Goudry <goudry.nicolas@gmail.com>
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package export
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+
+	"earth-view/cmd/fetch"
+	"earth-view/lib"
+	"earth-view/lib/gallery"
+	"earth-view/lib/index"
+	"earth-view/lib/mirror"
+
+	"github.com/spf13/cobra"
+)
+
+// fetchAsset fetches images missing from the directory
+// It is a variable so that tests do not depend on gstatic.com
+var fetchAsset = mirror.FetchUpstream
+
+var (
+	dir    string
+	input  string
+	output string
+	title  string
+
+	galleryCmd = &cobra.Command{
+		Use:   "gallery",
+		Short: "Export images as a static HTML gallery",
+		Long: `Export Google Earth View images as a static HTML gallery.
+
+Description:
+  This command generates a self-contained static site in the directory set by
+  the '--output' flag, which can be hosted by any web server or opened from the
+  filesystem. It holds:
+
+    index.html        a grid of thumbnails, which can be filtered by country
+    map.html          a world map and a list of the locations of images
+    images/{id}.html  a page per image showing its metadata and attribution
+    images/{id}.jpeg  the images themselves
+    thumbs/{id}.jpeg  the thumbnails of images
+
+  Images are read from the directory set by the '--dir' flag, defaulting to the
+  current working directory. All its images are exported, unless the '--input'
+  flag is set to a file holding the output of the 'list' command, in which case
+  the listed images are exported and the missing ones are fetched into the
+  directory.
+
+  Metadata of images is read from the local index, or from the directory if
+  they are not indexed yet, images missing from it being fetched along with
+  their metadata. The site does not load anything from other servers, except
+  for the links to maps of image locations.
+
+  Running the command again updates the site, reusing existing thumbnails.`,
+		DisableFlagsInUseLine: true,
+		SilenceUsage:          true,
+		Args:                  cobra.MaximumNArgs(0),
+		Run: func(_ *cobra.Command, _ []string) {
+			filePath, err := runGalleryCmd(dir, input, output, title)
+			cobra.CheckErr(err)
+			fmt.Println(filePath)
+		},
+	}
+)
+
+func init() {
+	exportCmd.AddCommand(galleryCmd)
+
+	galleryCmd.Flags().StringVar(&dir, "dir", ".", "directory holding downloaded images")
+	galleryCmd.Flags().StringVarP(&input, "input", "i", "", "input file listing images to export")
+	galleryCmd.Flags().StringVarP(&output, "output", "o", "site", "directory to write the site to")
+	galleryCmd.Flags().StringVar(&title, "title", "Earth View", "title of the site")
+}
+
+func runGalleryCmd(dir string, input string, output string, title string) (string, error) {
+	absDir, err := filepath.Abs(dir)
+	if err != nil {
+		return "", err
+	}
+
+	m := mirror.New(absDir)
+	m.Fetch = fetchAsset
+
+	ids, err := m.Ids()
+	if input != "" {
+		ids, err = fetch.ReadInputIds(input)
+	}
+	if err != nil {
+		return "", err
+	}
+
+	if len(ids) == 0 {
+		return "", fmt.Errorf("no image to export in %s", absDir)
+	}
+
+	slices.Sort(ids)
+	ids = slices.Compact(ids)
+
+	idx, err := index.Load()
+	if err != nil {
+		return "", err
+	}
+
+	m.OnFetch = func(asset *lib.Asset) {
+		idx.Add(index.EntryFromAsset(asset))
+	}
+
+	var entries []index.Entry
+	for _, id := range ids {
+		entry, err := mirrorEntry(idx, m, id)
+		if mirror.IsNotFound(err) {
+			fmt.Fprintf(os.Stderr, "skipping image %d: %s\n", id, err)
+			continue
+		}
+		if err != nil {
+			return "", err
+		}
+
+		entries = append(entries, entry)
+	}
+
+	if err := idx.Save(); err != nil {
+		fmt.Fprintf(os.Stderr, "failed to record images metadata in index: %s\n", err)
+	}
+
+	absOutput, err := filepath.Abs(output)
+	if err != nil {
+		return "", err
+	}
+
+	if err := gallery.Generate(absOutput, title, entries, m.Image); err != nil {
+		return "", err
+	}
+
+	return filepath.Join(absOutput, "index.html"), nil
+}
+
+// mirrorEntry returns the index entry of an image, adding it to the index if it is missing
+// Images missing from the directory are fetched once along with their metadata, which is otherwise
+// read from the directory
+func mirrorEntry(idx *index.Index, m *mirror.Mirror, id int) (index.Entry, error) {
+	if entry, ok := idx.Get(id); ok {
+		return entry, nil
+	}
+
+	content, err := m.Metadata(id)
+	if err != nil {
+		return index.Entry{}, err
+	}
+
+	// Fetched images are indexed along with their dimensions
+	if entry, ok := idx.Get(id); ok {
+		return entry, nil
+	}
+
+	var metadata map[string]interface{}
+	if err := json.Unmarshal(content, &metadata); err != nil {
+		return index.Entry{}, err
+	}
+
+	entry := index.EntryFromMetadata(id, metadata)
+	idx.Add(entry)
+
+	return entry, nil
+}
@@ -0,0 +1,105 @@
+package export
+
+import (
+	"bytes"
+	"image"
+	"image/jpeg"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"earth-view/lib"
+	"earth-view/lib/index"
+)
+
+func TestRunGalleryCmd(t *testing.T) {
+	t.Setenv("XDG_DATA_HOME", t.TempDir())
+
+	idx := index.New()
+	idx.Add(index.Entry{Id: 1003, Country: "France"})
+	idx.Add(index.Entry{Id: 1004, Country: "Iceland"})
+	if err := idx.Save(); err != nil {
+		t.Fatalf("Failed to save index: %s", err)
+	}
+
+	var content bytes.Buffer
+	if err := jpeg.Encode(&content, image.NewRGBA(image.Rect(0, 0, 60, 40)), nil); err != nil {
+		t.Fatalf("Failed to encode image: %s", err)
+	}
+
+	dir := t.TempDir()
+	for _, name := range []string{"1003.jpeg", "1004.jpeg", "notes.txt", "wallpaper.jpeg"} {
+		if err := os.WriteFile(filepath.Join(dir, name), content.Bytes(), 0644); err != nil {
+			t.Fatalf("Failed to write %s: %s", name, err)
+		}
+	}
+
+	output := filepath.Join(t.TempDir(), "site")
+	filePath, err := runGalleryCmd(dir, "", output, "Earth View")
+	if err != nil {
+		t.Fatalf("Expected gallery to be exported, got error: %s", err)
+	}
+
+	if filePath != filepath.Join(output, "index.html") {
+		t.Fatalf("Expected path of index page, got %s", filePath)
+	}
+
+	page, err := os.ReadFile(filePath)
+	if err != nil {
+		t.Fatalf("Expected index page to be written, got error: %s", err)
+	}
+
+	if count := strings.Count(string(page), "<li "); count != 2 {
+		t.Fatalf("Expected downloaded images to be exported, got %d images", count)
+	}
+
+	if _, err := runGalleryCmd(t.TempDir(), "", output, "Earth View"); err == nil {
+		t.Fatalf("Expected error for directory without images")
+	}
+}
+
+func TestRunGalleryCmdFetchOnce(t *testing.T) {
+	t.Setenv("XDG_DATA_HOME", t.TempDir())
+
+	var content bytes.Buffer
+	if err := jpeg.Encode(&content, image.NewRGBA(image.Rect(0, 0, 60, 40)), nil); err != nil {
+		t.Fatalf("Failed to encode image: %s", err)
+	}
+
+	fetched := make(map[int]int)
+	prevFetch := fetchAsset
+	fetchAsset = func(id int) (*lib.Asset, error) {
+		fetched[id]++
+
+		return &lib.Asset{
+			Id:       id,
+			Metadata: map[string]interface{}{"country": "Chile"},
+			Content:  content.Bytes(),
+		}, nil
+	}
+	t.Cleanup(func() { fetchAsset = prevFetch })
+
+	inputFile := filepath.Join(t.TempDir(), "input.json")
+	if err := os.WriteFile(inputFile, []byte("[1005]"), 0644); err != nil {
+		t.Fatalf("Failed to write input: %s", err)
+	}
+
+	output := filepath.Join(t.TempDir(), "site")
+	if _, err := runGalleryCmd(t.TempDir(), inputFile, output, "Earth View"); err != nil {
+		t.Fatalf("Expected gallery to be exported, got error: %s", err)
+	}
+
+	if fetched[1005] != 1 {
+		t.Fatalf("Expected missing image to be fetched once, got %d fetches", fetched[1005])
+	}
+
+	idx, err := index.Load()
+	if err != nil {
+		t.Fatalf("Failed to load index: %s", err)
+	}
+
+	if entry, ok := idx.Get(1005); !ok || entry.Country != "Chile" {
+		t.Fatalf("Expected fetched image to be indexed, got %+v", entry)
+	}
+}
@@ -0,0 +1,131 @@
+/*
+Copyright © 2024 Nicolas Goudry <goudry.nicolas@gmail.com>
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package favorites
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"earth-view/cmd"
+	"earth-view/lib"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	banned     bool
+	jsonOutput bool
+
+	favoritesCmd = &cobra.Command{
+		Use:   "favorites",
+		Short: "List favorite images",
+		Long: `List the favorite Google Earth View images.
+
+Description:
+  This command lists the images added with the 'like' command, along with their
+  location and the date they were added.
+
+  The '--banned' flag lists the images banned with the 'ban' command instead.`,
+		DisableFlagsInUseLine: true,
+		SilenceUsage:          true,
+		Args:                  cobra.MaximumNArgs(0),
+		Run: func(_ *cobra.Command, _ []string) {
+			out, err := runFavoritesCmd(banned, jsonOutput)
+			cobra.CheckErr(err)
+
+			if out != "" {
+				fmt.Println(out)
+			}
+		},
+	}
+)
+
+func init() {
+	cmd.RootCmd.AddCommand(favoritesCmd)
+
+	favoritesCmd.Flags().BoolVar(&banned, "banned", false, "list banned images")
+	favoritesCmd.Flags().BoolVar(&jsonOutput, "json", false, "output images in JSON format")
+}
+
+func runFavoritesCmd(banned bool, jsonOutput bool) (string, error) {
+	prefs, err := lib.LoadPreferences()
+	if err != nil {
+		return "", err
+	}
+
+	list := prefs.Favorites
+	if banned {
+		list = prefs.Banned
+	}
+
+	if jsonOutput {
+		if list == nil {
+			list = []lib.Preference{}
+		}
+
+		out, err := json.MarshalIndent(list, "", "  ")
+		if err != nil {
+			return "", err
+		}
+
+		return string(out), nil
+	}
+
+	if len(list) == 0 {
+		return "", nil
+	}
+
+	var out strings.Builder
+
+	writer := tabwriter.NewWriter(&out, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(writer, "ID\tADDED\tCOUNTRY\tREGION\tATTRIBUTION")
+
+	for _, pref := range list {
+		fmt.Fprintf(
+			writer,
+			"%d\t%s\t%s\t%s\t%s\n",
+			pref.Id,
+			pref.Time.Local().Format(time.DateTime),
+			orDash(pref.Country),
+			orDash(pref.Region),
+			orDash(pref.Attribution),
+		)
+	}
+
+	if err := writer.Flush(); err != nil {
+		return "", err
+	}
+
+	return strings.TrimSuffix(out.String(), "\n"), nil
+}
+
+// orDash returns the given value, or a dash if it is empty
+func orDash(value string) string {
+	if value == "" {
+		return "-"
+	}
+
+	return value
+}
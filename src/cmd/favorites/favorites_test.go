@@ -0,0 +1,77 @@
+package favorites
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"earth-view/lib"
+)
+
+func setup(t *testing.T) {
+	t.Setenv("XDG_DATA_HOME", t.TempDir())
+	t.Setenv("XDG_STATE_HOME", t.TempDir())
+
+	prevLookup := lookupMetadata
+	lookupMetadata = func(id int) (map[string]interface{}, error) {
+		if id == 1003 {
+			return map[string]interface{}{"country": "France", "region": "Brittany"}, nil
+		}
+
+		return nil, fmt.Errorf("asset not found")
+	}
+
+	t.Cleanup(func() { lookupMetadata = prevLookup })
+}
+
+func TestLikeCurrent(t *testing.T) {
+	setup(t)
+
+	if _, err := runPreferenceCmd(nil, true, false); err == nil {
+		t.Fatalf("Expected error without current background")
+	}
+
+	if err := lib.RecordHistory("/tmp/1003.jpeg", "feh"); err != nil {
+		t.Fatalf("Expected no error while recording history, got %v", err)
+	}
+
+	out, err := runPreferenceCmd(nil, true, false)
+	if err != nil || out != "Added 1003 to favorites" {
+		t.Fatalf("Expected current background to be liked, got %q (%v)", out, err)
+	}
+
+	out, _ = runFavoritesCmd(false, false)
+	if !strings.Contains(out, "France") || !strings.Contains(out, "Brittany") {
+		t.Fatalf("Expected favorites to list location, got %q", out)
+	}
+
+	out, _ = runPreferenceCmd([]string{"1003"}, true, true)
+	if out != "Removed 1003 from favorites" {
+		t.Fatalf("Expected favorite to be removed, got %q", out)
+	}
+
+	out, _ = runFavoritesCmd(false, false)
+	if out != "" {
+		t.Fatalf("Expected no favorites, got %q", out)
+	}
+}
+
+func TestBan(t *testing.T) {
+	setup(t)
+
+	for _, expected := range []string{"Added 1004 to banned images", "1004 is already in banned images"} {
+		out, err := runPreferenceCmd([]string{"1004"}, false, false)
+		if err != nil || out != expected {
+			t.Fatalf("Expected %q, got %q (%v)", expected, out, err)
+		}
+	}
+
+	out, _ := runFavoritesCmd(true, true)
+	if !strings.Contains(out, `"id": 1004`) {
+		t.Fatalf("Expected banned images to list 1004, got %q", out)
+	}
+
+	if _, err := runPreferenceCmd([]string{"abc"}, false, false); err == nil {
+		t.Fatalf("Expected error for invalid identifier")
+	}
+}
@@ -0,0 +1,203 @@
+/*
+Copyright © 2024 Nicolas Goudry <goudry.nicolas@gmail.com>
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package favorites
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+
+	"earth-view/cmd"
+	"earth-view/lib"
+	"earth-view/lib/index"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	remove bool
+
+	// lookupMetadata retrieves the metadata of an asset, it is replaced in tests
+	lookupMetadata = func(id int) (map[string]interface{}, error) {
+		asset := lib.Asset{Id: id}
+		return asset.GetMetadata()
+	}
+
+	preferenceHelp = `  When no identifier is provided, the current background is used, as recorded
+  in history by the 'set' and 'daemon' commands.
+
+  Preferences are stored in '$XDG_STATE_HOME/earth-view/preferences.json'. The
+  location of the image is retrieved from the index, or from gstatic.com if it
+  is not indexed, and saved along with its identifier, so that it can be listed
+  offline.
+
+  The '--remove' flag reverts the operation.`
+
+	likeCmd = &cobra.Command{
+		Use:   "like [identifier]",
+		Short: "Add an image to favorites",
+		Long: fmt.Sprintf(`Add a Google Earth View image to favorites.
+
+Description:
+  This command marks an image as favorite. Favorite images are more likely to
+  be picked by the 'fetch random', 'set' and 'daemon' commands. Liking a banned
+  image removes it from the banned images.
+
+%s`, preferenceHelp),
+		DisableFlagsInUseLine: true,
+		SilenceUsage:          true,
+		Args:                  cobra.MaximumNArgs(1),
+		Run: func(_ *cobra.Command, args []string) {
+			out, err := runPreferenceCmd(args, true, remove)
+			cobra.CheckErr(err)
+			fmt.Println(out)
+		},
+	}
+
+	banCmd = &cobra.Command{
+		Use:   "ban [identifier]",
+		Short: "Ban an image from random selection",
+		Long: fmt.Sprintf(`Ban a Google Earth View image from random selection.
+
+Description:
+  This command marks an image as banned. Banned images are never picked by the
+  'fetch random', 'set' and 'daemon' commands. Banning a favorite image removes
+  it from the favorites.
+
+%s`, preferenceHelp),
+		DisableFlagsInUseLine: true,
+		SilenceUsage:          true,
+		Args:                  cobra.MaximumNArgs(1),
+		Run: func(_ *cobra.Command, args []string) {
+			out, err := runPreferenceCmd(args, false, remove)
+			cobra.CheckErr(err)
+			fmt.Println(out)
+		},
+	}
+)
+
+func init() {
+	cmd.RootCmd.AddCommand(likeCmd)
+	cmd.RootCmd.AddCommand(banCmd)
+
+	likeCmd.Flags().BoolVar(&remove, "remove", false, "remove image from favorites")
+	banCmd.Flags().BoolVar(&remove, "remove", false, "remove image from banned images")
+}
+
+// resolveId returns the identifier given as argument, or the one of the current background
+func resolveId(args []string) (int, error) {
+	if len(args) > 0 {
+		id, err := strconv.Atoi(args[0])
+		if err != nil {
+			return -1, fmt.Errorf("invalid identifier: %s", args[0])
+		}
+
+		return id, nil
+	}
+
+	history, err := lib.LoadHistory()
+	if err != nil {
+		return -1, err
+	}
+
+	entry, ok := history.Current()
+	if !ok {
+		return -1, fmt.Errorf("no current background found in history")
+	}
+
+	if entry.Id == 0 {
+		return -1, fmt.Errorf("current background is not a Google Earth View image: %s", entry.Path)
+	}
+
+	return entry.Id, nil
+}
+
+// newPreference creates the preference of an image, with its location if it can be retrieved
+func newPreference(id int) lib.Preference {
+	pref := lib.Preference{Id: id, Time: time.Now()}
+
+	// Prefer the local index to avoid fetching the image
+	if idx, err := index.Load(); err == nil {
+		if entry, ok := idx.Get(id); ok {
+			pref.Country = entry.Country
+			pref.Region = entry.Region
+			pref.Attribution = entry.Attribution
+
+			return pref
+		}
+	}
+
+	if metadata, err := lookupMetadata(id); err == nil {
+		pref.Country, _ = metadata["country"].(string)
+		pref.Region, _ = metadata["region"].(string)
+		pref.Attribution, _ = metadata["attribution"].(string)
+	}
+
+	return pref
+}
+
+func runPreferenceCmd(args []string, like bool, remove bool) (string, error) {
+	id, err := resolveId(args)
+	if err != nil {
+		return "", err
+	}
+
+	prefs, err := lib.LoadPreferences()
+	if err != nil {
+		return "", err
+	}
+
+	list := "banned images"
+	if like {
+		list = "favorites"
+	}
+
+	var changed bool
+	switch {
+	case like && remove:
+		changed = prefs.Unlike(id)
+	case like:
+		changed = prefs.Like(newPreference(id))
+	case remove:
+		changed = prefs.Unban(id)
+	default:
+		changed = prefs.Ban(newPreference(id))
+	}
+
+	if !changed {
+		if remove {
+			return fmt.Sprintf("%d is not in %s", id, list), nil
+		}
+
+		return fmt.Sprintf("%d is already in %s", id, list), nil
+	}
+
+	if err := prefs.Save(); err != nil {
+		return "", err
+	}
+
+	if remove {
+		return fmt.Sprintf("Removed %d from %s", id, list), nil
+	}
+
+	return fmt.Sprintf("Added %d to %s", id, list), nil
+}
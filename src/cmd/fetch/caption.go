@@ -0,0 +1,201 @@
+/*
+Copyright © 2024 Nicolas Goudry <goudry.nicolas@gmail.com>
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package fetch
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"math"
+	"slices"
+	"strings"
+
+	"earth-view/lib/imaging"
+	"earth-view/lib/index"
+
+	"github.com/spf13/pflag"
+	"golang.org/x/image/draw"
+)
+
+// captionPositions lists the corners captions can be drawn in
+var captionPositions = []string{"bottom-right", "bottom-left", "top-right", "top-left"}
+
+// captionFlags holds the flags configuring captions
+var captionFlags captionOptions
+
+// captionOptions configures the caption drawn on images
+type captionOptions struct {
+	enabled     bool
+	position    string
+	margin      int
+	size        float64
+	coordinates bool
+	attribution bool
+	backdrop    float64
+}
+
+func (c *captionOptions) addFlags(f *pflag.FlagSet) {
+	f.BoolVar(&c.enabled, "caption", false, "draw the image location on the image")
+	f.StringVar(
+		&c.position,
+		"caption-position",
+		"bottom-right",
+		"corner of the caption, one of: "+strings.Join(captionPositions, ", "),
+	)
+	f.IntVar(&c.margin, "caption-margin", 32, "distance between the caption and the image edges")
+	f.Float64Var(&c.size, "caption-size", 0, "font size of the caption in pixels")
+	f.BoolVar(&c.coordinates, "caption-coordinates", false, "add coordinates to the caption")
+	f.BoolVar(&c.attribution, "caption-attribution", true, "add attribution to the caption")
+	f.Float64Var(&c.backdrop, "caption-backdrop", 0.5, "opacity of the caption backdrop")
+}
+
+// options returns the caption options set by flags, or nil if images are not captioned
+func (c *captionOptions) options() (*captionOptions, error) {
+	if !c.enabled {
+		return nil, nil
+	}
+
+	if !slices.Contains(captionPositions, c.position) {
+		return nil, fmt.Errorf(
+			"invalid caption position: %s. Supported positions are: %s",
+			c.position,
+			strings.Join(captionPositions, ", "),
+		)
+	}
+
+	if c.margin < 0 || c.size < 0 {
+		return nil, fmt.Errorf("caption margin and size cannot be negative")
+	}
+
+	if c.backdrop < 0 || c.backdrop > 1 {
+		return nil, fmt.Errorf(
+			"invalid caption backdrop: %g. Expected an opacity from 0 to 1",
+			c.backdrop,
+		)
+	}
+
+	return c, nil
+}
+
+// lines returns the lines of the caption of an image: its location, then its coordinates and its
+// attribution if enabled
+func (c *captionOptions) lines(entry index.Entry) []string {
+	location := entry.Location()
+	if location == "" {
+		location = "Unknown location"
+	}
+
+	lines := []string{location}
+	if c.coordinates {
+		lines = append(lines, entry.Point().FormatDMS())
+	}
+
+	if c.attribution && entry.Attribution != "" {
+		lines = append(lines, entry.Attribution)
+	}
+
+	return lines
+}
+
+// render draws the caption of the image with the given identifier
+func (c *captionOptions) render(img image.Image, id int) (image.Image, error) {
+	entry, err := index.Lookup(id)
+	if err != nil {
+		return nil, err
+	}
+
+	return drawCaption(img, c.lines(entry), c)
+}
+
+// drawCaption draws the lines on a copy of the image, the first one larger than the others, over a
+// translucent backdrop
+// The font size defaults to a fortieth of the image height
+func drawCaption(img image.Image, lines []string, c *captionOptions) (*image.RGBA, error) {
+	bounds := img.Bounds()
+	dst := image.NewRGBA(image.Rect(0, 0, bounds.Dx(), bounds.Dy()))
+	draw.Draw(dst, dst.Bounds(), img, bounds.Min, draw.Src)
+
+	size := c.size
+	if size == 0 {
+		size = math.Max(float64(bounds.Dy())/40, 10)
+	}
+
+	title, err := imaging.NewFace(size)
+	if err != nil {
+		return nil, err
+	}
+
+	details, err := imaging.NewFace(size * 0.7)
+	if err != nil {
+		return nil, err
+	}
+
+	// Measure lines to size the backdrop
+	var width, height int
+	heights := make([]int, len(lines))
+	for i, line := range lines {
+		face := details
+		if i == 0 {
+			face = title
+		}
+
+		width = max(width, imaging.TextWidth(face, line))
+		heights[i] = face.Metrics().Height.Ceil()
+		height += heights[i]
+	}
+
+	padding := int(size / 2)
+	box := image.Rect(0, 0, width+2*padding, height+2*padding)
+
+	right := strings.HasSuffix(c.position, "right")
+	offset := image.Pt(c.margin, c.margin)
+	if right {
+		offset.X = dst.Rect.Dx() - c.margin - box.Dx()
+	}
+	if strings.HasPrefix(c.position, "bottom") {
+		offset.Y = dst.Rect.Dy() - c.margin - box.Dy()
+	}
+	box = box.Add(offset)
+
+	if c.backdrop > 0 {
+		backdrop := image.NewUniform(color.NRGBA{A: uint8(math.Round(c.backdrop * 0xff))})
+		draw.Draw(dst, box, backdrop, image.Point{}, draw.Over)
+	}
+
+	y := box.Min.Y + padding
+	for i, line := range lines {
+		face := details
+		if i == 0 {
+			face = title
+		}
+
+		x := box.Min.X + padding
+		if right {
+			x = box.Max.X - padding - imaging.TextWidth(face, line)
+		}
+
+		imaging.DrawText(dst, face, x, y+face.Metrics().Ascent.Ceil(), line, color.White)
+		y += heights[i]
+	}
+
+	return dst, nil
+}
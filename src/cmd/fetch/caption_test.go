@@ -0,0 +1,80 @@
+package fetch
+
+import (
+	"image"
+	"image/color"
+	"reflect"
+	"testing"
+
+	"earth-view/lib/index"
+)
+
+func TestCaptionOptions(t *testing.T) {
+	if opts, err := (&captionOptions{}).options(); opts != nil || err != nil {
+		t.Fatalf("Expected no caption without flag, got %+v (%v)", opts, err)
+	}
+
+	for _, opts := range []captionOptions{
+		{enabled: true, position: "center"},
+		{enabled: true, position: "top-left", margin: -1},
+		{enabled: true, position: "top-left", backdrop: 1.5},
+	} {
+		if _, err := opts.options(); err == nil {
+			t.Fatalf("Expected error for invalid options %+v", opts)
+		}
+	}
+}
+
+func TestCaptionLines(t *testing.T) {
+	entry := index.Entry{
+		Id:          1003,
+		Country:     "France",
+		Region:      "Brittany",
+		Lat:         48.5,
+		Lng:         -4.25,
+		Attribution: "©2024 Google",
+	}
+
+	opts := captionOptions{attribution: true}
+	if lines := opts.lines(entry); !reflect.DeepEqual(
+		lines,
+		[]string{"Brittany, France", "©2024 Google"},
+	) {
+		t.Fatalf("Expected location and attribution, got %v", lines)
+	}
+
+	opts = captionOptions{coordinates: true}
+	expected := []string{"Unknown location", `48°30'00.0"N 4°15'00.0"W`}
+	if lines := opts.lines(index.Entry{Lat: 48.5, Lng: -4.25}); !reflect.DeepEqual(
+		lines,
+		expected,
+	) {
+		t.Fatalf("Expected %v, got %v", expected, lines)
+	}
+}
+
+func TestDrawCaption(t *testing.T) {
+	src := image.NewRGBA(image.Rect(0, 0, 400, 300))
+	for y := 0; y < 300; y++ {
+		for x := 0; x < 400; x++ {
+			src.Set(x, y, color.Gray{Y: 0x80})
+		}
+	}
+
+	opts := &captionOptions{position: "bottom-right", margin: 10, size: 20, backdrop: 0.5}
+	dst, err := drawCaption(src, []string{"Brittany, France", "©2024 Google"}, opts)
+	if err != nil {
+		t.Fatalf("Expected caption to be drawn, got error: %s", err)
+	}
+
+	// The backdrop darkens the bottom-right corner, inside the margin
+	if c := dst.RGBAAt(388, 288); c.R >= 0x80 {
+		t.Fatalf("Expected backdrop in the bottom-right corner, got %v", c)
+	}
+
+	for _, p := range []image.Point{{395, 295}, {10, 10}, {10, 288}} {
+		if c := dst.RGBAAt(p.X, p.Y); c.R != 0x80 {
+			t.Fatalf("Expected image to be unchanged at %v, got %v", p, c)
+		}
+	}
+}
@@ -22,16 +22,30 @@ THE SOFTWARE.
 package fetch
 
 import (
+	"fmt"
+	"os"
+	"strings"
+
+	"earth-view/lib"
+	"earth-view/lib/index"
+	"earth-view/lib/preview"
+
 	"github.com/spf13/pflag"
 )
 
 var (
-	output    string
-	overwrite bool
+	fit             string
+	output          string
+	overwrite       bool
+	previewProtocol string
 
 	helpText = struct {
 		process string
 		output  string
+		fit     string
+		caption string
+		derive  string
+		preview string
 	}{
 		process: `  The image metadata is first retrieved from gstatic.com (the server hosting the
   images assets) then the image is decoded before being saved on the filesystem.`,
@@ -42,10 +56,85 @@ var (
 
   If the output file exists, it is not overwritten. This behaviour can be
   changed by using the '--overwrite' flag.`,
+		fit: `  When the '--fit' flag is provided, the image is also resized to the given size,
+  e.g. '1920x1080', or to the size of the screen with 'auto', which detects
+  monitors with wlr-randr on Wayland or xrandr otherwise and uses the primary or
+  largest one. The image is cropped to the aspect ratio of the screen around its
+  most detailed part rather than its center. The resized image is saved next to
+  the original one, its size appended to the filename, e.g.
+  '1003-1920x1080.jpeg', and its path is output instead.`,
+		caption: `  When the '--caption' flag is provided, the region and country of the image are
+  drawn in a corner of the image, set by the '--caption-position' flag, over a
+  translucent backdrop whose opacity is set by the '--caption-backdrop' flag.
+  The attribution of the image is added below, as required by the license of
+  the imagery, unless '--caption-attribution=false' is provided, and so are its
+  coordinates if the '--caption-coordinates' flag is provided. The font size
+  defaults to a fortieth of the image height. Metadata is read from the local
+  index, or retrieved from gstatic.com if the image is not indexed yet. The
+  captioned image is saved next to the original one, or to the resized one when
+  '--fit' is provided, with '-caption' appended to the filename, and its path is
+  output instead.`,
+		derive: `  When the '--derive lockscreen' flag is provided, a blurred and darkened copy
+  of the image is also saved for screen lockers, with '-lockscreen' appended to
+  the filename. It is derived from the resized image when '--fit' is provided,
+  but never captioned. The blur radius and method are set by the
+  '--lockscreen-blur' and '--lockscreen-blur-method' flags, the darkening by
+  the '--lockscreen-dim' flag and the darkening of the corners by the
+  '--lockscreen-vignette' flag.
+
+  The paths of derived images are output after the paths of fetched images, in
+  the same order, so that screen lockers can use them. The first lines always
+  hold one path per fetched image.`,
+		preview: preview.ProtocolHelp,
 	}
 )
 
 func addCommonFlags(f *pflag.FlagSet) {
 	f.StringVarP(&output, "output", "o", "", "write image to given file or directory")
 	f.BoolVar(&overwrite, "overwrite", false, "overwrite output file if it exists")
+	f.StringVar(&fit, "fit", "", "resize image to given size as WxH or to the screen with 'auto'")
+	captionFlags.addFlags(f)
+	f.StringArrayVar(
+		&derive,
+		"derive",
+		nil,
+		"write a derived image, one of: "+strings.Join(derivatives, ", "),
+	)
+	lockscreenFlags.addFlags(f)
+	f.StringVar(&previewProtocol, "preview", "", "render image in the terminal")
+	f.Lookup("preview").NoOptDefVal = string(preview.Auto)
+}
+
+// outputPaths returns the paths to output: one per fetched image, followed by the paths of the
+// images derived from them
+func outputPaths(filePaths []string, derivedPaths []string) []string {
+	return append(filePaths, derivedPaths...)
+}
+
+// renderPreviews renders the given image files in the terminal if the '--preview' flag is set
+func renderPreviews(filePaths ...string) error {
+	if previewProtocol == "" {
+		return nil
+	}
+
+	protocol, err := preview.ParseProtocol(previewProtocol)
+	if err != nil {
+		return err
+	}
+
+	for _, filePath := range filePaths {
+		if err := preview.RenderFile(os.Stdout, filePath, protocol); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// indexAsset records the metadata of a fetched asset in the index, for later selection by location
+// Failing to do so does not prevent the image from being used
+func indexAsset(asset *lib.Asset) {
+	if err := index.Record(asset); err != nil {
+		fmt.Fprintf(os.Stderr, "failed to record image metadata in index: %s\n", err)
+	}
 }
@@ -0,0 +1,157 @@
+/*
+Copyright © 2024 Nicolas Goudry <goudry.nicolas@gmail.com>
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package fetch
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"image/jpeg"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"earth-view/lib"
+)
+
+// derivedQuality is the JPEG quality of images derived from fetched images
+const derivedQuality = 92
+
+// derivation holds the transformations applied to fetched images, as set by flags
+type derivation struct {
+	fit        *fitSize
+	caption    *captionOptions
+	lockscreen *lockscreenOptions
+}
+
+// parseDerivation returns the transformations set by flags
+// It is called before fetching images so that invalid flags are reported early
+func parseDerivation() (*derivation, error) {
+	size, err := parseFit(fit)
+	if err != nil {
+		return nil, err
+	}
+
+	caption, err := captionFlags.options()
+	if err != nil {
+		return nil, err
+	}
+
+	lockscreen, err := parseDerivatives(derive)
+	if err != nil {
+		return nil, err
+	}
+
+	return &derivation{fit: size, caption: caption, lockscreen: lockscreen}, nil
+}
+
+// apply transforms the given images, fetched with the given identifiers in the same order, and
+// returns the paths of the resulting images, and the paths of the images derived from them
+// Images are resized first, so that captions are drawn at the size of the screen and lock screen
+// images are derived at this size, without captions
+func (d *derivation) apply(
+	ids []int,
+	filePaths []string,
+	overwrite bool,
+) ([]string, []string, error) {
+	var derivedPaths, lockscreenPaths []string
+	for i, filePath := range filePaths {
+		id := ids[i]
+		derivedPath := filePath
+
+		if d.fit != nil {
+			var err error
+			derivedPath, err = deriveImage(derivedPath, d.fit.suffix(), overwrite, d.fit.resize)
+			if err != nil {
+				return nil, nil, err
+			}
+		}
+
+		if d.lockscreen != nil {
+			lockscreenPath, err := deriveImage(
+				derivedPath,
+				"-lockscreen",
+				overwrite,
+				d.lockscreen.render,
+			)
+			if err != nil {
+				return nil, nil, err
+			}
+
+			lockscreenPaths = append(lockscreenPaths, lockscreenPath)
+		}
+
+		if d.caption != nil {
+			var err error
+			derivedPath, err = deriveImage(
+				derivedPath,
+				"-caption",
+				overwrite,
+				func(img image.Image) (image.Image, error) { return d.caption.render(img, id) },
+			)
+			if err != nil {
+				return nil, nil, err
+			}
+		}
+
+		derivedPaths = append(derivedPaths, derivedPath)
+	}
+
+	return derivedPaths, lockscreenPaths, nil
+}
+
+// deriveImage transforms an image and saves the result next to it, with the suffix appended to its
+// filename, e.g. '1003-1920x1080.jpeg', and returns the path of the derived image
+// The derived image is only written if it does not yet exist or if overwrite is set
+func deriveImage(
+	filePath string,
+	suffix string,
+	overwrite bool,
+	transform func(img image.Image) (image.Image, error),
+) (string, error) {
+	derivedPath := strings.TrimSuffix(filePath, filepath.Ext(filePath)) + suffix + ".jpeg"
+	if lib.FileExists(derivedPath) && !overwrite {
+		return derivedPath, nil
+	}
+
+	content, err := os.ReadFile(filePath)
+	if err != nil {
+		return "", err
+	}
+
+	img, err := jpeg.Decode(bytes.NewReader(content))
+	if err != nil {
+		return "", fmt.Errorf("failed to decode %s: %s", filePath, err)
+	}
+
+	derived, err := transform(img)
+	if err != nil {
+		return "", err
+	}
+
+	var out bytes.Buffer
+	if err := jpeg.Encode(&out, derived, &jpeg.Options{Quality: derivedQuality}); err != nil {
+		return "", err
+	}
+
+	return derivedPath, lib.WriteFile(out.Bytes(), derivedPath)
+}
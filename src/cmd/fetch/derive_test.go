@@ -0,0 +1,111 @@
+package fetch
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"earth-view/lib/index"
+)
+
+// writeTestImage saves a gray JPEG image with the given file name in a temporary directory
+func writeTestImage(t *testing.T, name string) string {
+	t.Helper()
+
+	img := image.NewRGBA(image.Rect(0, 0, 180, 120))
+	for y := 0; y < 120; y++ {
+		for x := 0; x < 180; x++ {
+			img.Set(x, y, color.Gray{Y: 0x80})
+		}
+	}
+
+	var content bytes.Buffer
+	if err := jpeg.Encode(&content, img, nil); err != nil {
+		t.Fatalf("Failed to encode image: %s", err)
+	}
+
+	filePath := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(filePath, content.Bytes(), 0644); err != nil {
+		t.Fatalf("Failed to write image: %s", err)
+	}
+
+	return filePath
+}
+
+// decodeSize returns the size of a JPEG image file
+func decodeSize(t *testing.T, filePath string) (int, int) {
+	t.Helper()
+
+	file, err := os.Open(filePath)
+	if err != nil {
+		t.Fatalf("Expected %s to be written, got error: %s", filePath, err)
+	}
+	defer file.Close()
+
+	config, err := jpeg.DecodeConfig(file)
+	if err != nil {
+		t.Fatalf("Expected %s to be a JPEG image, got error: %s", filePath, err)
+	}
+
+	return config.Width, config.Height
+}
+
+func TestDerivationApply(t *testing.T) {
+	t.Setenv("XDG_DATA_HOME", t.TempDir())
+
+	idx := index.New()
+	idx.Add(index.Entry{Id: 1003, Country: "France", Attribution: "©2024 Google"})
+	if err := idx.Save(); err != nil {
+		t.Fatalf("Failed to save index: %s", err)
+	}
+
+	filePath := writeTestImage(t, "1003.jpeg")
+	dir := filepath.Dir(filePath)
+
+	if filePaths, _, err := (&derivation{}).apply([]int{1003}, []string{filePath}, false); err != nil ||
+		filePaths[0] != filePath {
+		t.Fatalf("Expected original image without transformation, got %v (%v)", filePaths, err)
+	}
+
+	d := &derivation{fit: &fitSize{320, 100}}
+	filePaths, _, err := d.apply([]int{1003}, []string{filePath}, false)
+	if err != nil {
+		t.Fatalf("Expected image to be resized, got error: %s", err)
+	}
+
+	if expected := filepath.Join(dir, "1003-320x100.jpeg"); filePaths[0] != expected {
+		t.Fatalf("Expected %s, got %s", expected, filePaths[0])
+	}
+
+	if width, height := decodeSize(t, filePaths[0]); width != 320 || height != 100 {
+		t.Fatalf("Expected 320x100 image, got %dx%d", width, height)
+	}
+
+	d.caption = &captionOptions{position: "bottom-left", margin: 8, attribution: true}
+	if filePaths, _, err = d.apply([]int{1003}, []string{filePath}, false); err != nil {
+		t.Fatalf("Expected image to be resized and captioned, got error: %s", err)
+	}
+
+	if expected := filepath.Join(dir, "1003-320x100-caption.jpeg"); filePaths[0] != expected {
+		t.Fatalf("Expected %s, got %s", expected, filePaths[0])
+	}
+
+	if width, height := decodeSize(t, filePaths[0]); width != 320 || height != 100 {
+		t.Fatalf("Expected 320x100 image, got %dx%d", width, height)
+	}
+
+	// The identifier of the image is not read from its filename
+	filePath = writeTestImage(t, "2024-wallpaper.jpeg")
+	if filePaths, _, err = d.apply([]int{1003}, []string{filePath}, false); err != nil {
+		t.Fatalf("Expected image with custom filename to be captioned, got error: %s", err)
+	}
+
+	expected := filepath.Join(filepath.Dir(filePath), "2024-wallpaper-320x100-caption.jpeg")
+	if filePaths[0] != expected {
+		t.Fatalf("Expected %s, got %s", expected, filePaths[0])
+	}
+}
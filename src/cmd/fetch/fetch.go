@@ -23,7 +23,9 @@ package fetch
 
 import (
 	"fmt"
+	"path/filepath"
 	"strconv"
+	"strings"
 
 	"earth-view/cmd"
 	"earth-view/lib"
@@ -43,7 +45,15 @@ var (
 Description:
 %s
 
-%s`, helpText.process, helpText.output),
+%s
+
+%s
+
+%s
+
+%s
+
+%s`, helpText.process, helpText.output, helpText.fit, helpText.caption, helpText.derive, helpText.preview),
 		DisableFlagsInUseLine: true,
 		SilenceUsage:          true,
 		Args: func(cmd *cobra.Command, args []string) error {
@@ -54,9 +64,24 @@ Description:
 			return nil
 		},
 		Run: func(cmd *cobra.Command, args []string) {
+			derivation, err := parseDerivation()
+			cobra.CheckErr(err)
+
 			filePath, err := runFetchCmd(args[0], output, overwrite)
 			cobra.CheckErr(err)
-			fmt.Println(filePath)
+
+			// The identifier was validated when fetching the image
+			id, _ := strconv.Atoi(args[0])
+			filePaths, derivedPaths, err := derivation.apply(
+				[]int{id},
+				[]string{filePath},
+				overwrite,
+			)
+			cobra.CheckErr(err)
+
+			filePaths = outputPaths(filePaths, derivedPaths)
+			fmt.Println(strings.Join(filePaths, "\n"))
+			cobra.CheckErr(renderPreviews(filePaths...))
 		},
 	}
 )
@@ -85,6 +110,7 @@ func runFetchCmd(id string, output string, overwrite bool) (string, error) {
 		if err != nil {
 			return "", err
 		}
+		indexAsset(&asset)
 
 		err = lib.WriteFile(content, filePath)
 		if err != nil {
@@ -94,3 +120,32 @@ func runFetchCmd(id string, output string, overwrite bool) (string, error) {
 
 	return filePath, nil
 }
+
+// Fetch downloads the image with given identifier and returns the path of the saved file
+// It allows other commands to reuse the fetch behaviour
+func Fetch(id string, output string, overwrite bool) (string, error) {
+	return runFetchCmd(id, output, overwrite)
+}
+
+// Resolve returns the absolute path of the given image, either a file, an identifier or 'random',
+// downloading it if needed. Random images are chosen among the input according to the selection
+// flags
+// It allows other commands to accept the same image arguments
+func Resolve(source string, input string, output string, overwrite bool) (string, error) {
+	if source == "random" {
+		return FetchRandom(input, output, overwrite)
+	}
+
+	if lib.FileExists(source) {
+		return filepath.Abs(source)
+	}
+
+	if _, err := strconv.Atoi(source); err == nil {
+		return Fetch(source, output, overwrite)
+	}
+
+	return "", fmt.Errorf(
+		"invalid image provided: %s. Expected a file, an identifier or 'random'",
+		source,
+	)
+}
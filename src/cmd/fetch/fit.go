@@ -0,0 +1,78 @@
+/*
+Copyright © 2024 Nicolas Goudry <goudry.nicolas@gmail.com>
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package fetch
+
+import (
+	"fmt"
+	"image"
+	"os"
+
+	"earth-view/lib/imaging"
+	"earth-view/lib/screen"
+)
+
+// detectMonitors returns the enabled monitors
+// It is a variable so that tests do not depend on the monitors of the host
+var detectMonitors = func() ([]screen.Monitor, error) {
+	return screen.Detect(os.Getenv, screen.Run)
+}
+
+// fitSize is the size images are resized to with the '--fit' flag
+type fitSize struct {
+	width  int
+	height int
+}
+
+// parseFit returns the size set by the '--fit' flag, or nil if images are not resized
+// The 'auto' value resolves to the size of the primary or largest monitor
+func parseFit(value string) (*fitSize, error) {
+	switch value {
+	case "":
+		return nil, nil
+	case "auto":
+		monitors, err := detectMonitors()
+		if err != nil {
+			return nil, err
+		}
+
+		monitor := screen.Main(monitors)
+
+		return &fitSize{monitor.Width, monitor.Height}, nil
+	}
+
+	width, height, err := imaging.ParseSize(value)
+	if err != nil {
+		return nil, err
+	}
+
+	return &fitSize{width, height}, nil
+}
+
+// suffix returns the suffix appended to the filename of resized images, e.g. '-1920x1080'
+func (s *fitSize) suffix() string {
+	return fmt.Sprintf("-%dx%d", s.width, s.height)
+}
+
+// resize scales the image to the size, cropped around its most detailed part
+func (s *fitSize) resize(img image.Image) (image.Image, error) {
+	return imaging.FocusFill(img, s.width, s.height, imaging.DefaultInterpolator), nil
+}
@@ -0,0 +1,33 @@
+package fetch
+
+import (
+	"testing"
+
+	"earth-view/lib/screen"
+)
+
+func TestParseFit(t *testing.T) {
+	if size, err := parseFit(""); size != nil || err != nil {
+		t.Fatalf("Expected no resizing without flag, got %+v (%v)", size, err)
+	}
+
+	if size, err := parseFit("1920x1080"); err != nil || *size != (fitSize{1920, 1080}) {
+		t.Fatalf("Expected 1920x1080, got %+v (%v)", size, err)
+	}
+
+	if _, err := parseFit("1920"); err == nil {
+		t.Fatalf("Expected error for invalid size")
+	}
+
+	defer func(detect func() ([]screen.Monitor, error)) { detectMonitors = detect }(detectMonitors)
+	detectMonitors = func() ([]screen.Monitor, error) {
+		return []screen.Monitor{
+			{Name: "eDP-1", Width: 1920, Height: 1200},
+			{Name: "DP-1", Width: 2560, Height: 1080, Primary: true},
+		}, nil
+	}
+
+	if size, err := parseFit("auto"); err != nil || *size != (fitSize{2560, 1080}) {
+		t.Fatalf("Expected size of primary monitor, got %+v (%v)", size, err)
+	}
+}
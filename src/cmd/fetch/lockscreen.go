@@ -0,0 +1,128 @@
+/*
+Copyright © 2024 Nicolas Goudry <goudry.nicolas@gmail.com>
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package fetch
+
+import (
+	"fmt"
+	"image"
+	"math"
+	"slices"
+	"strings"
+
+	"earth-view/lib/imaging"
+
+	"github.com/spf13/pflag"
+)
+
+var (
+	// derivatives lists the images which can be derived with the '--derive' flag
+	derivatives = []string{"lockscreen"}
+
+	// blurMethods lists the methods the lock screen image can be blurred with
+	blurMethods = []string{"gaussian", "box"}
+
+	derive          []string
+	lockscreenFlags lockscreenOptions
+)
+
+// lockscreenOptions configures the lock screen image derived from fetched images
+type lockscreenOptions struct {
+	blur       float64
+	blurMethod string
+	dim        float64
+	vignette   float64
+}
+
+func (l *lockscreenOptions) addFlags(f *pflag.FlagSet) {
+	f.Float64Var(&l.blur, "lockscreen-blur", 24, "blur radius of the lock screen image in pixels")
+	f.StringVar(
+		&l.blurMethod,
+		"lockscreen-blur-method",
+		"gaussian",
+		"blur method of the lock screen image, one of: "+strings.Join(blurMethods, ", "),
+	)
+	f.Float64Var(&l.dim, "lockscreen-dim", 0.3, "darkening of the lock screen image, from 0 to 1")
+	f.Float64Var(
+		&l.vignette,
+		"lockscreen-vignette",
+		0,
+		"darkening of the lock screen image corners, from 0 to 1",
+	)
+}
+
+// parseDerivatives checks the values of the '--derive' flag and returns the lock screen options if
+// it is requested, or nil
+func parseDerivatives(values []string) (*lockscreenOptions, error) {
+	for _, value := range values {
+		if !slices.Contains(derivatives, value) {
+			return nil, fmt.Errorf(
+				"invalid derived image: %s. Supported derived images are: %s",
+				value,
+				strings.Join(derivatives, ", "),
+			)
+		}
+	}
+
+	if !slices.Contains(values, "lockscreen") {
+		return nil, nil
+	}
+
+	return lockscreenFlags.options()
+}
+
+// options checks the lock screen options set by flags
+func (l *lockscreenOptions) options() (*lockscreenOptions, error) {
+	if !slices.Contains(blurMethods, l.blurMethod) {
+		return nil, fmt.Errorf(
+			"invalid blur method: %s. Supported methods are: %s",
+			l.blurMethod,
+			strings.Join(blurMethods, ", "),
+		)
+	}
+
+	if l.blur < 0 {
+		return nil, fmt.Errorf("blur radius cannot be negative")
+	}
+
+	if l.dim < 0 || l.dim > 1 || l.vignette < 0 || l.vignette > 1 {
+		return nil, fmt.Errorf("dim and vignette must be from 0 to 1")
+	}
+
+	return l, nil
+}
+
+// render blurs and darkens the image so that text drawn by screen lockers over it is readable
+// The Gaussian blur radius is three times its standard deviation, beyond which the kernel has
+// almost no weight
+func (l *lockscreenOptions) render(img image.Image) (image.Image, error) {
+	var blurred *image.RGBA
+	if l.blurMethod == "box" {
+		blurred = imaging.BoxBlur(img, int(math.Round(l.blur)))
+	} else {
+		blurred = imaging.GaussianBlur(img, l.blur/3)
+	}
+
+	imaging.Dim(blurred, l.dim)
+	imaging.Vignette(blurred, l.vignette)
+
+	return blurred, nil
+}
@@ -0,0 +1,83 @@
+package fetch
+
+import (
+	"image"
+	"image/color"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+func TestParseDerivatives(t *testing.T) {
+	if opts, err := parseDerivatives(nil); opts != nil || err != nil {
+		t.Fatalf("Expected no lock screen image without flag, got %+v (%v)", opts, err)
+	}
+
+	if opts, err := parseDerivatives([]string{"lockscreen"}); opts == nil || err != nil {
+		t.Fatalf("Expected lock screen options, got %+v (%v)", opts, err)
+	}
+
+	if _, err := parseDerivatives([]string{"thumbnail"}); err == nil {
+		t.Fatalf("Expected error for unknown derived image")
+	}
+
+	for _, opts := range []lockscreenOptions{
+		{blurMethod: "motion"},
+		{blurMethod: "box", blur: -1},
+		{blurMethod: "box", dim: 2},
+	} {
+		if _, err := opts.options(); err == nil {
+			t.Fatalf("Expected error for invalid options %+v", opts)
+		}
+	}
+}
+
+func TestLockscreenRender(t *testing.T) {
+	src := image.NewRGBA(image.Rect(0, 0, 60, 40))
+	for y := 0; y < 40; y++ {
+		for x := 0; x < 60; x++ {
+			src.Set(x, y, color.Gray{Y: 200})
+		}
+	}
+
+	for _, method := range blurMethods {
+		opts := lockscreenOptions{blur: 6, blurMethod: method, dim: 0.5, vignette: 0.5}
+		img, err := opts.render(src)
+		if err != nil {
+			t.Fatalf("Expected image to be rendered, got error: %s", err)
+		}
+
+		center := color.GrayModel.Convert(img.At(30, 20)).(color.Gray)
+		corner := color.GrayModel.Convert(img.At(0, 0)).(color.Gray)
+		if center.Y != 100 || corner.Y >= center.Y {
+			t.Fatalf("Expected dimmed image with darker corners, got %v and %v", center, corner)
+		}
+	}
+}
+
+func TestDerivationApplyLockscreen(t *testing.T) {
+	filePath := writeTestImage(t, "1003.jpeg")
+	dir := filepath.Dir(filePath)
+
+	d := &derivation{
+		fit:        &fitSize{90, 60},
+		lockscreen: &lockscreenOptions{blur: 6, blurMethod: "gaussian", dim: 0.3},
+	}
+	filePaths, derivedPaths, err := d.apply([]int{1003}, []string{filePath}, false)
+	if err != nil {
+		t.Fatalf("Expected lock screen image to be derived, got error: %s", err)
+	}
+
+	// Derived paths are output after the path of the fetched image
+	expected := []string{
+		filepath.Join(dir, "1003-90x60.jpeg"),
+		filepath.Join(dir, "1003-90x60-lockscreen.jpeg"),
+	}
+	if paths := outputPaths(filePaths, derivedPaths); !slices.Equal(paths, expected) {
+		t.Fatalf("Expected %v, got %v", expected, paths)
+	}
+
+	if width, height := decodeSize(t, derivedPaths[0]); width != 90 || height != 60 {
+		t.Fatalf("Expected 90x60 lock screen image, got %dx%d", width, height)
+	}
+}
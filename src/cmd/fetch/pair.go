@@ -0,0 +1,181 @@
+/*
+Copyright © 2024 Nicolas Goudry <goudry.nicolas@gmail.com>
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package fetch
+
+import (
+	"bytes"
+	"fmt"
+	"image/jpeg"
+	"os"
+	"sort"
+	"strconv"
+
+	"earth-view/lib"
+	"earth-view/lib/imaging"
+)
+
+// maxPairAttempts is the number of random picks allowed per candidate before giving up on finding
+// enough distinct valid candidates
+const maxPairAttempts = 10
+
+// pairCandidate holds a random image considered when picking a pair
+type pairCandidate struct {
+	id        int
+	filePath  string
+	content   []byte
+	fetched   bool
+	luminance float64
+}
+
+// runFetchRandomPairCmd picks a number of random candidates, measures their luminance and saves
+// the brightest one for light mode and the darkest one for dark mode
+// It returns the identifiers and paths of the light and dark images, in this order
+func runFetchRandomPairCmd(
+	input string,
+	output string,
+	overwrite bool,
+	candidates int,
+) ([]int, []string, error) {
+	if candidates < 2 {
+		return nil, nil, fmt.Errorf("at least 2 candidates are needed to pick a pair of images")
+	}
+
+	// Both images cannot be saved to the same file
+	if output != "" {
+		if stat, err := os.Stat(output); err != nil || !stat.IsDir() {
+			return nil, nil, fmt.Errorf(
+				"output must be an existing directory when picking a pair of images",
+			)
+		}
+	}
+
+	p, err := newInputPicker(input)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	picked, err := pickPairCandidates(p, output, overwrite, candidates)
+
+	// Images found missing are dropped from the shuffle bag even if no pair was picked
+	if saveErr := p.save(); err == nil {
+		err = saveErr
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if len(picked) < 2 {
+		return nil, nil, fmt.Errorf("not enough valid images to pick a pair")
+	}
+
+	sort.Slice(picked, func(i, j int) bool {
+		return picked[i].luminance > picked[j].luminance
+	})
+
+	var ids []int
+	var filePaths []string
+	for _, candidate := range []pairCandidate{picked[0], picked[len(picked)-1]} {
+		if candidate.fetched {
+			if err := lib.WriteFile(candidate.content, candidate.filePath); err != nil {
+				return nil, nil, err
+			}
+		}
+
+		ids = append(ids, candidate.id)
+		filePaths = append(filePaths, candidate.filePath)
+	}
+
+	return ids, filePaths, nil
+}
+
+// pickPairCandidates picks distinct random images until the given number of candidates is reached
+// or attempts run out
+func pickPairCandidates(
+	p *picker,
+	output string,
+	overwrite bool,
+	candidates int,
+) ([]pairCandidate, error) {
+	var picked []pairCandidate
+	for attempt := 0; len(picked) < candidates && attempt < candidates*maxPairAttempts; attempt++ {
+		// Picking fails once all images were considered
+		randomId, err := p.pick()
+		if err != nil {
+			break
+		}
+
+		candidate, err := loadPairCandidate(randomId, output, overwrite)
+		if err != nil {
+			if os.IsTimeout(err) {
+				return nil, err
+			}
+
+			p.reject(randomId, err)
+			continue
+		}
+
+		// Each image is considered once
+		p.exclude(randomId)
+		picked = append(picked, *candidate)
+	}
+
+	return picked, nil
+}
+
+// loadPairCandidate reads or fetches the image with given identifier and measures its luminance
+func loadPairCandidate(id int, output string, overwrite bool) (*pairCandidate, error) {
+	filePath, err := lib.ResolveAbsFilePath(output, strconv.Itoa(id)+".jpeg")
+	if err != nil {
+		return nil, err
+	}
+
+	candidate := pairCandidate{id: id, filePath: filePath}
+
+	// Only fetch file if it does not yet exist or if overwrite is set
+	if lib.FileExists(filePath) == false || overwrite {
+		asset := lib.Asset{Id: id}
+		if candidate.content, err = asset.GetContent(); err != nil {
+			return nil, err
+		}
+		indexAsset(&asset)
+		candidate.fetched = true
+	} else if candidate.content, err = os.ReadFile(filePath); err != nil {
+		return nil, err
+	}
+
+	img, err := jpeg.Decode(bytes.NewReader(candidate.content))
+	if err != nil {
+		return nil, fmt.Errorf("[%d] failed to decode image: %s", id, err)
+	}
+
+	candidate.luminance = imaging.MeanLuminance(img)
+
+	return &candidate, nil
+}
+
+// FetchRandomPair downloads a light and a dark random image and returns the paths of the saved
+// files, in this order
+// It allows other commands to reuse the random pair fetch behaviour
+func FetchRandomPair(input string, output string, overwrite bool) ([]string, error) {
+	_, filePaths, err := runFetchRandomPairCmd(input, output, overwrite, defaultPairCandidates)
+	return filePaths, err
+}
@@ -0,0 +1,70 @@
+package fetch
+
+import (
+	"image"
+	"image/jpeg"
+	"os"
+	"path"
+	"testing"
+)
+
+func preparePairImage(t *testing.T, filePath string, gray uint8) {
+	img := image.NewGray(image.Rect(0, 0, 32, 32))
+	for i := range img.Pix {
+		img.Pix[i] = gray
+	}
+
+	file, err := os.Create(filePath)
+	if err != nil {
+		t.Fatalf("Failed to prepare image: %v", err)
+	}
+	defer file.Close()
+
+	if err := jpeg.Encode(file, img, nil); err != nil {
+		t.Fatalf("Failed to prepare image: %v", err)
+	}
+}
+
+func TestFetchRandomPairFromExistingFiles(t *testing.T) {
+	ts.test = t
+	ts.prepareInputFile(inputIds[:3])
+	defer os.Remove(inputFile)
+
+	out := t.TempDir()
+	preparePairImage(t, path.Join(out, "1003.jpeg"), 255)
+	preparePairImage(t, path.Join(out, "1004.jpeg"), 0)
+	preparePairImage(t, path.Join(out, "1006.jpeg"), 128)
+
+	_, filePaths, err := runFetchRandomPairCmd(inputFile, out, false, 3)
+	ts.checkError("fetch", err, nil)
+
+	if len(filePaths) != 2 {
+		t.Fatalf("Expected 2 files, got %d", len(filePaths))
+	}
+
+	if want := path.Join(out, "1003.jpeg"); filePaths[0] != want {
+		t.Fatalf("Expected light image to be %s, got %s", want, filePaths[0])
+	}
+
+	if want := path.Join(out, "1004.jpeg"); filePaths[1] != want {
+		t.Fatalf("Expected dark image to be %s, got %s", want, filePaths[1])
+	}
+}
+
+func TestFetchRandomPairFailOutputFile(t *testing.T) {
+	ts.test = t
+
+	_, _, err := runFetchRandomPairCmd("", path.Join(t.TempDir(), "custom-out.jpeg"), false, 3)
+	if err == nil {
+		t.Fatal("Expected error, got success")
+	}
+}
+
+func TestFetchRandomPairFailNotEnoughCandidates(t *testing.T) {
+	ts.test = t
+
+	_, _, err := runFetchRandomPairCmd("", t.TempDir(), false, 1)
+	if err == nil {
+		t.Fatal("Expected error, got success")
+	}
+}
@@ -24,17 +24,21 @@ package fetch
 import (
 	"encoding/json"
 	"fmt"
-	"math/rand"
 	"os"
 	"strconv"
+	"strings"
 
 	"earth-view/lib"
 
 	"github.com/spf13/cobra"
 )
 
+const defaultPairCandidates = 6
+
 var (
-	input string
+	candidates int
+	input      string
+	pair       bool
 
 	randomCmd = &cobra.Command{
 		Use:     "random",
@@ -56,14 +60,58 @@ Description:
   from the known range of possible identifiers. If the selected identifier is
   not valid, another one will be chosen, until a valid identifier is found.
 
-%s`, helpText.process, helpText.output),
+  When '--pair' flag is provided, two images are downloaded: a bright one for
+  light mode and a dark one for dark mode. To choose them, a number of random
+  images set by the '--candidates' flag is considered and their mean luminance
+  is measured. The brightest and darkest images are saved, and their paths are
+  output on two lines, light image first. Other candidates are discarded.
+
+%s
+
+%s
+
+  When '--pair' flag is provided, '--output' must be a directory.
+
+%s
+
+%s
+
+%s
+
+%s`, selectionHelp, helpText.process, helpText.output, helpText.fit, helpText.caption, helpText.derive, helpText.preview),
 		DisableFlagsInUseLine: true,
 		SilenceUsage:          true,
 		Args:                  cobra.MaximumNArgs(0),
 		Run: func(cmd *cobra.Command, args []string) {
-			filePath, err := runFetchRandomCmd(input, output, overwrite)
+			derivation, err := parseDerivation()
+			cobra.CheckErr(err)
+
+			if pair {
+				ids, filePaths, err := runFetchRandomPairCmd(input, output, overwrite, candidates)
+				cobra.CheckErr(err)
+
+				filePaths, derivedPaths, err := derivation.apply(ids, filePaths, overwrite)
+				cobra.CheckErr(err)
+
+				filePaths = outputPaths(filePaths, derivedPaths)
+				fmt.Println(strings.Join(filePaths, "\n"))
+				cobra.CheckErr(renderPreviews(filePaths...))
+				return
+			}
+
+			id, filePath, err := runFetchRandomCmd(input, output, overwrite)
+			cobra.CheckErr(err)
+
+			filePaths, derivedPaths, err := derivation.apply(
+				[]int{id},
+				[]string{filePath},
+				overwrite,
+			)
 			cobra.CheckErr(err)
-			fmt.Println(filePath)
+
+			filePaths = outputPaths(filePaths, derivedPaths)
+			fmt.Println(strings.Join(filePaths, "\n"))
+			cobra.CheckErr(renderPreviews(filePaths...))
 		},
 	}
 )
@@ -72,72 +120,141 @@ func init() {
 	fetchCmd.AddCommand(randomCmd)
 
 	randomCmd.Flags().StringVarP(&input, "input", "i", "", "input file to choose an image from")
+	randomCmd.Flags().BoolVar(&pair, "pair", false, "fetch a light and a dark image")
+	randomCmd.Flags().
+		IntVar(&candidates, "candidates", defaultPairCandidates, "number of images compared to pick a pair")
+	AddSelectionFlags(randomCmd.Flags())
 	addCommonFlags(randomCmd.Flags())
 }
 
-func runFetchRandomCmd(input string, output string, overwrite bool) (string, error) {
+// runFetchRandomCmd downloads a random image and returns its identifier and the path of the saved
+// file
+func runFetchRandomCmd(input string, output string, overwrite bool) (int, string, error) {
+	p, err := newInputPicker(input)
+	if err != nil {
+		return -1, "", err
+	}
+
 	asset := lib.Asset{}
-	filePath, err := fetchRandomAsset(&asset, input, output, overwrite)
+	filePath, err := fetchRandomAsset(p, &asset, output, overwrite)
+
+	// Images found missing are dropped from the shuffle bag even if no image was fetched
+	if saveErr := p.save(); err == nil {
+		err = saveErr
+	}
 	if err != nil {
-		return "", err
+		return -1, "", err
 	}
 
 	// Only write file if it does not yet exist or if overwrite is set
 	if lib.FileExists(filePath) == false || overwrite {
 		err := lib.WriteFile(asset.Content, filePath)
-		return filePath, err
+		return asset.Id, filePath, err
 	}
 
-	return filePath, nil
+	return asset.Id, filePath, nil
 }
 
 func pickRandomId(input string) (int, error) {
+	ids, err := readInputIds(input)
+	if err != nil {
+		return -1, err
+	}
+
+	return selectId(ids)
+}
+
+// newInputPicker returns a picker choosing among the identifiers of the input file, or all the
+// known possible identifiers if no input file is provided
+func newInputPicker(input string) (*picker, error) {
+	ids, err := readInputIds(input)
+	if err != nil {
+		return nil, err
+	}
+
+	return newPicker(ids)
+}
+
+// readInputIds returns the identifiers listed in the input file, or all the known possible
+// identifiers if no input file is provided
+func readInputIds(input string) ([]int, error) {
 	if input == "" {
-		return lib.KnownIdLowerBoundary + rand.Intn(
-			lib.KnownIdUpperBoundary-lib.KnownIdLowerBoundary+1,
-		), nil
+		ids := make([]int, 0, lib.KnownIdUpperBoundary-lib.KnownIdLowerBoundary+1)
+		for id := lib.KnownIdLowerBoundary; id <= lib.KnownIdUpperBoundary; id++ {
+			ids = append(ids, id)
+		}
+
+		return ids, nil
 	}
 
 	content, err := os.ReadFile(input)
 	if err != nil {
-		return -1, err
+		return nil, err
 	}
 
 	var ids []int
 	if err := json.Unmarshal(content, &ids); err != nil {
-		return -1, err
+		return nil, err
 	}
 
-	return ids[rand.Intn(len(ids))], nil
+	return ids, nil
 }
 
+// fetchRandomAsset picks random identifiers until an image is fetched, and returns the path to save
+// it to
 func fetchRandomAsset(
+	p *picker,
 	asset *lib.Asset,
-	input string,
 	output string,
 	overwrite bool,
 ) (string, error) {
-	randomId, err := pickRandomId(input)
-	if err != nil {
-		return "", err
-	}
+	var fetchErr error
+	for {
+		randomId, err := p.pick()
+		if err != nil && fetchErr != nil {
+			// All images were tried, the last failure explains why
+			return "", fetchErr
+		} else if err != nil {
+			return "", err
+		}
 
-	filePath, err := lib.ResolveAbsFilePath(output, strconv.Itoa(randomId)+".jpeg")
-	if err != nil {
-		return "", err
-	}
+		filePath, err := lib.ResolveAbsFilePath(output, strconv.Itoa(randomId)+".jpeg")
+		if err != nil {
+			return "", err
+		}
 
-	// Only fetch file if it does not yet exist or if overwrite is set
-	if lib.FileExists(filePath) == false || overwrite {
 		asset.Id = randomId
+
+		// Only fetch file if it does not yet exist or if overwrite is set
+		if lib.FileExists(filePath) && !overwrite {
+			return filePath, nil
+		}
+
 		if _, err := asset.GetContent(); err != nil {
 			if os.IsTimeout(err) {
 				return "", err
 			}
 
-			return fetchRandomAsset(asset, input, output, overwrite)
+			p.reject(randomId, err)
+			fetchErr = err
+			continue
 		}
+		indexAsset(asset)
+
+		return filePath, nil
 	}
+}
+
+// FetchRandom downloads a random image and returns the path of the saved file
+// It allows other commands to reuse the random fetch behaviour
+func FetchRandom(input string, output string, overwrite bool) (string, error) {
+	_, filePath, err := runFetchRandomCmd(input, output, overwrite)
+	return filePath, err
+}
 
-	return filePath, nil
+// ReadInputIds returns the identifiers listed in the input file, or all the known possible
+// identifiers if no input file is provided
+// It allows other commands to choose among the same identifiers
+func ReadInputIds(input string) ([]int, error) {
+	return readInputIds(input)
 }
@@ -48,7 +48,7 @@ func TestFetchRandomSuccessFromFile(t *testing.T) {
 	ts.test = t
 	ts.prepareInputFile(inputIds)
 
-	filePath, err := runFetchRandomCmd(inputFile, ts.out, true)
+	_, filePath, err := runFetchRandomCmd(inputFile, ts.out, true)
 	ts.checkError("fetch", err, func() { os.Remove(inputFile) })
 	os.Remove(filePath)
 	os.Remove(inputFile)
@@ -57,7 +57,7 @@ func TestFetchRandomSuccessFromFile(t *testing.T) {
 func TestFetchRandomSuccessFromRange(t *testing.T) {
 	ts.test = t
 
-	filePath, err := runFetchRandomCmd("", ts.out, true)
+	_, filePath, err := runFetchRandomCmd("", ts.out, true)
 	ts.checkError("fetch", err, nil)
 	os.Remove(filePath)
 }
@@ -65,7 +65,7 @@ func TestFetchRandomSuccessFromRange(t *testing.T) {
 func TestFetchRandomFailNoInputFile(t *testing.T) {
 	ts.test = t
 
-	filePath, err := runFetchRandomCmd(inputFile, ts.out, true)
+	_, filePath, err := runFetchRandomCmd(inputFile, ts.out, true)
 	if err == nil {
 		t.Fatal("Expected error, got success")
 	}
@@ -78,7 +78,7 @@ func TestFetchRandomCustomOutput(t *testing.T) {
 	ts.out = path.Join(os.TempDir(), "custom-out.jpeg")
 	os.Remove(ts.out)
 
-	filePath, err := runFetchRandomCmd("", ts.out, true)
+	_, filePath, err := runFetchRandomCmd("", ts.out, true)
 	ts.checkError("fetch", err, nil)
 
 	if filePath != ts.out {
@@ -96,7 +96,7 @@ func TestFetchRandomOverwrite(t *testing.T) {
 	ts.out = path.Join(os.TempDir(), "custom-out.jpeg")
 	ts.prepareInputFile(inputIds)
 
-	filePath, err := runFetchRandomCmd(inputFile, ts.out, true)
+	_, filePath, err := runFetchRandomCmd(inputFile, ts.out, true)
 	ts.checkError("fetch", err, nil)
 
 	clean := func() {
@@ -110,7 +110,7 @@ func TestFetchRandomOverwrite(t *testing.T) {
 
 	// Prepare an alternate input file to avoid picking the same random id
 	ts.prepareInputFile(altInputIds)
-	_, err = runFetchRandomCmd(inputFile, ts.out, true)
+	_, _, err = runFetchRandomCmd(inputFile, ts.out, true)
 	ts.checkError("fetch", err, clean)
 
 	stat, err = os.Stat(filePath)
@@ -129,7 +129,7 @@ func TestFetchRandomNoOverwrite(t *testing.T) {
 	ts.test = t
 	ts.out = path.Join(os.TempDir(), "custom-out.jpeg")
 
-	filePath, err := runFetchRandomCmd("", ts.out, true)
+	_, filePath, err := runFetchRandomCmd("", ts.out, true)
 	ts.checkError("fetch", err, nil)
 
 	clean := func() { os.Remove(filePath) }
@@ -138,7 +138,7 @@ func TestFetchRandomNoOverwrite(t *testing.T) {
 	ts.checkError("stat", err, clean)
 	initialModTime := stat.ModTime()
 
-	_, err = runFetchRandomCmd("", ts.out, false)
+	_, _, err = runFetchRandomCmd("", ts.out, false)
 	ts.checkError("fetch", err, clean)
 
 	stat, err = os.Stat(filePath)
@@ -0,0 +1,73 @@
+/*
+Copyright © 2024 Nicolas Goudry <goudry.nicolas@gmail.com>
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package fetch
+
+import (
+	"fmt"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"earth-view/lib"
+	"earth-view/lib/imaging"
+)
+
+// Restore downloads again the image with given identifier if its file was removed, e.g. by garbage
+// collection. Resized images are resized again from the original image, which is downloaded to its
+// default path next to them. Other derived images cannot be restored since the flags they were
+// derived with are not recorded
+// It allows commands navigating through history to show images which were removed
+func Restore(id int, filePath string) error {
+	if lib.FileExists(filePath) {
+		return nil
+	}
+
+	original := strconv.Itoa(id)
+	name := strings.TrimSuffix(filepath.Base(filePath), filepath.Ext(filePath))
+	if name == original {
+		_, err := Fetch(original, filePath, false)
+		return err
+	}
+
+	width, height, err := imaging.ParseSize(strings.TrimPrefix(name, original+"-"))
+	if err != nil || !strings.HasPrefix(name, original+"-") {
+		return fmt.Errorf(
+			"image %s does not exist anymore and cannot be derived again from image %d",
+			filePath,
+			id,
+		)
+	}
+
+	originalPath, err := Fetch(
+		original,
+		filepath.Join(filepath.Dir(filePath), original+".jpeg"),
+		false,
+	)
+	if err != nil {
+		return err
+	}
+
+	size := fitSize{width, height}
+	_, err = deriveImage(originalPath, size.suffix(), false, size.resize)
+
+	return err
+}
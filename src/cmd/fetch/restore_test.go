@@ -0,0 +1,36 @@
+package fetch
+
+import (
+	"path/filepath"
+	"testing"
+
+	"earth-view/lib"
+)
+
+func TestRestore(t *testing.T) {
+	// The original image exists, so that it is not downloaded again
+	original := writeTestImage(t, "1003.jpeg")
+	dir := filepath.Dir(original)
+
+	if err := Restore(1003, original); err != nil {
+		t.Fatalf("Expected existing image to be kept, got error: %s", err)
+	}
+
+	resized := filepath.Join(dir, "1003-90x60.jpeg")
+	if err := Restore(1003, resized); err != nil {
+		t.Fatalf("Expected resized image to be restored, got error: %s", err)
+	}
+
+	if width, height := decodeSize(t, resized); width != 90 || height != 60 {
+		t.Fatalf("Expected restored image to be 90x60, got %dx%d", width, height)
+	}
+
+	captioned := filepath.Join(dir, "1003-90x60-caption.jpeg")
+	if err := Restore(1003, captioned); err == nil {
+		t.Fatalf("Expected error when restoring captioned image")
+	}
+
+	if lib.FileExists(captioned) {
+		t.Fatalf("Expected captioned image not to be written")
+	}
+}
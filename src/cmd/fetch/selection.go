@@ -0,0 +1,399 @@
+/*
+Copyright © 2024 Nicolas Goudry <goudry.nicolas@gmail.com>
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package fetch
+
+import (
+	"errors"
+	"fmt"
+	"math/rand"
+	"slices"
+	"strconv"
+	"time"
+
+	"earth-view/lib"
+	"earth-view/lib/index"
+
+	"github.com/spf13/pflag"
+)
+
+var (
+	favoriteWeight float64
+	filterOpts     index.FilterOptions
+	noRepeatWindow string
+	shuffle        bool
+
+	selectionHelp = `  The '--country', '--region', '--continent', '--bbox' and '--near' flags
+  restrict the selection to images taken at the given location. They can be
+  combined, in which case images must match all of them. The '--country',
+  '--region' and '--continent' flags can be repeated to match any of the values.
+  The '--bbox' flag expects the 'minLon,minLat,maxLon,maxLat' edges of a
+  bounding box in decimal degrees. The '--near' flag expects 'lat,lng'
+  coordinates, and is combined with the '--radius' flag (e.g. '500km', the
+  default, '20mi' or '800m').
+
+  Location filters rely on metadata stored locally, in the index saved in
+  '$XDG_DATA_HOME/earth-view/index.json', so that selection works offline. The
+  index is filled when downloading images, or all at once by the 'index build'
+  command. Images missing from the index are never picked when a location
+  filter is used.
+
+  The '--min-brightness', '--max-brightness', '--max-haze' and '--hue' flags
+  restrict the selection using statistics measured on images, to leave out dark
+  or washed out ones. Brightness is the mean luminance, from 0 (black) to 1
+  (white). Haze is the share of bright and pale pixels, typical of haze and
+  clouds, from 0 to 1. The '--hue' flag can be repeated and expects one of red,
+  orange, yellow, green, cyan, blue, purple, pink or gray, the dominant hue of
+  images without enough saturated colors. Statistics are measured and stored in
+  the index by the 'index build' command, including for images indexed when
+  they were downloaded. Images without statistics are never picked when one of
+  these flags is used.
+
+  Images banned with the 'ban' command are never picked. Images liked with the
+  'like' command are more likely to be picked, by the factor set with the
+  '--favorite-weight' flag.
+
+  The '--no-repeat-window' flag prevents picking recently shown images, as
+  recorded in history by the 'set' and 'daemon' commands. It is either a number
+  of backgrounds (e.g. '50') or a duration (e.g. '24h'). If all images were
+  recently shown, the window is ignored.
+
+  The '--shuffle' flag makes the selection go through all images before picking
+  any of them again. The state of the current cycle is saved in
+  '$XDG_STATE_HOME/earth-view/shuffle.json' and survives between runs. It starts
+  over when the images to choose from change. Identifiers found not to exist
+  are remembered there and never picked again.`
+)
+
+// AddSelectionFlags registers the flags restricting the random selection of images in the given
+// flag set. It allows other commands picking random images to share the same flags
+func AddSelectionFlags(f *pflag.FlagSet) {
+	f.StringVar(&filterOpts.Bbox, "bbox", "", "only pick images within given bounding box")
+	f.StringArrayVar(
+		&filterOpts.Continents,
+		"continent",
+		nil,
+		"only pick images from given continent",
+	)
+	f.StringArrayVar(&filterOpts.Countries, "country", nil, "only pick images from given country")
+	f.StringVar(&filterOpts.Near, "near", "", "only pick images near given coordinates")
+	f.StringVar(&filterOpts.Radius, "radius", "", "maximum distance from coordinates set by --near")
+	f.StringArrayVar(&filterOpts.Regions, "region", nil, "only pick images from given region")
+	f.StringVar(
+		&filterOpts.MinBrightness,
+		"min-brightness",
+		"",
+		"only pick images with at least given brightness, from 0 to 1",
+	)
+	f.StringVar(
+		&filterOpts.MaxBrightness,
+		"max-brightness",
+		"",
+		"only pick images with at most given brightness, from 0 to 1",
+	)
+	f.StringVar(
+		&filterOpts.MaxHaze,
+		"max-haze",
+		"",
+		"only pick images with at most given share of hazy pixels, from 0 to 1",
+	)
+	f.StringArrayVar(&filterOpts.Hues, "hue", nil, "only pick images with given dominant hue")
+	f.Float64Var(
+		&favoriteWeight,
+		"favorite-weight",
+		2,
+		"factor of the chance to pick favorite images",
+	)
+	f.StringVar(
+		&noRepeatWindow,
+		"no-repeat-window",
+		"",
+		"do not pick images shown among the given number of last backgrounds or duration",
+	)
+	f.BoolVar(&shuffle, "shuffle", false, "go through all images before picking one again")
+}
+
+// picker picks random identifiers among the given ones, according to the selection flags
+// Preferences, history and the shuffle bag are read once, so that picking another image when one
+// cannot be fetched stays cheap. The shuffle bag is only written by save
+type picker struct {
+	ids        []int
+	allowed    []int
+	candidates []int
+	weight     func(int) float64
+	bag        *lib.ShuffleBag
+}
+
+// selectId picks a random identifier among the given ones, according to the selection flags
+func selectId(ids []int) (int, error) {
+	p, err := newPicker(ids)
+	if err != nil {
+		return -1, err
+	}
+
+	id, err := p.pick()
+	if err != nil {
+		return -1, err
+	}
+
+	return id, p.save()
+}
+
+// newPicker returns a picker choosing among the given identifiers
+func newPicker(ids []int) (*picker, error) {
+	if len(ids) == 0 {
+		return nil, fmt.Errorf("no image to choose from")
+	}
+
+	if favoriteWeight <= 0 {
+		return nil, fmt.Errorf(
+			"invalid favorite weight: %g. Expected a positive number",
+			favoriteWeight,
+		)
+	}
+
+	allowed, err := filterIds(ids)
+	if err != nil {
+		return nil, err
+	}
+
+	prefs, err := lib.LoadPreferences()
+	if err != nil {
+		return nil, err
+	}
+
+	allowed = slices.DeleteFunc(allowed, prefs.IsBanned)
+	if len(allowed) == 0 {
+		return nil, fmt.Errorf("all images are banned")
+	}
+
+	recentIds, err := recentlyShownIds(noRepeatWindow, time.Now())
+	if err != nil {
+		return nil, err
+	}
+
+	p := &picker{
+		ids:     ids,
+		allowed: allowed,
+		// Candidates exclude recently shown images, all allowed images being picked otherwise
+		candidates: slices.DeleteFunc(slices.Clone(allowed), func(id int) bool {
+			return recentIds[id]
+		}),
+		weight: func(id int) float64 {
+			if prefs.IsFavorite(id) {
+				return favoriteWeight
+			}
+
+			return 1
+		},
+	}
+
+	if shuffle {
+		if p.bag, err = lib.LoadShuffleBag(); err != nil {
+			return nil, err
+		}
+
+		// Images missing upstream are never picked again
+		p.allowed = slices.DeleteFunc(p.allowed, p.bag.IsMissing)
+		p.candidates = slices.DeleteFunc(p.candidates, p.bag.IsMissing)
+	}
+
+	return p, nil
+}
+
+// pick picks a random identifier
+func (p *picker) pick() (int, error) {
+	if len(p.allowed) == 0 {
+		return -1, fmt.Errorf("no valid image to choose from")
+	}
+
+	candidates := p.candidates
+	if len(candidates) == 0 {
+		candidates = p.allowed
+	}
+
+	if p.bag != nil {
+		return p.pickFromShuffleBag(candidates), nil
+	}
+
+	return weightedChoice(candidates, p.weight), nil
+}
+
+// exclude excludes an identifier from the next picks
+func (p *picker) exclude(id int) {
+	same := func(other int) bool { return other == id }
+	p.allowed = slices.DeleteFunc(p.allowed, same)
+	p.candidates = slices.DeleteFunc(p.candidates, same)
+}
+
+// reject excludes an identifier which could not be fetched from the next picks
+// Identifiers missing upstream are also dropped from the shuffle bag for good
+func (p *picker) reject(id int, err error) {
+	p.exclude(id)
+
+	if p.bag != nil && errors.Is(err, lib.ErrNotFound) {
+		p.bag.Drop(id)
+	}
+}
+
+// save writes the shuffle bag state, if the '--shuffle' flag is set
+func (p *picker) save() error {
+	if p.bag == nil {
+		return nil
+	}
+
+	return p.bag.Save()
+}
+
+// filterIds returns the identifiers of images matching the location and statistics filters
+func filterIds(ids []int) ([]int, error) {
+	filter, err := index.NewFilter(filterOpts)
+	if err != nil {
+		return nil, err
+	}
+
+	if filter.IsEmpty() {
+		return slices.Clone(ids), nil
+	}
+
+	idx, err := index.Load()
+	if err != nil {
+		return nil, err
+	}
+
+	matching := filter.Apply(idx, ids)
+	if len(matching) == 0 {
+		return nil, fmt.Errorf(
+			"no image matches filters among the %d indexed images",
+			idx.Len(),
+		)
+	}
+
+	return matching, nil
+}
+
+// pickFromShuffleBag picks a random identifier among candidates which were not yet picked in the
+// current cycle through the identifiers of the picker
+// Allowed identifiers are the ones which can be picked at all, candidates being the preferred ones
+func (p *picker) pickFromShuffleBag(candidates []int) int {
+	p.bag.Refill(p.ids)
+
+	// Start a new cycle if only banned images remain in the current one
+	eligible := intersect(p.bag.Remaining, p.allowed)
+	if len(eligible) == 0 {
+		p.bag.Remaining = nil
+		p.bag.Refill(p.ids)
+		eligible = intersect(p.bag.Remaining, p.allowed)
+	}
+
+	// Candidates exclude recently shown images, which matters when a new cycle starts
+	choices := intersect(eligible, candidates)
+	if len(choices) == 0 {
+		choices = eligible
+	}
+
+	id := weightedChoice(choices, p.weight)
+	p.bag.Take(id)
+
+	return id
+}
+
+// weightedChoice picks a random identifier, the chance of each one being proportional to its weight
+func weightedChoice(ids []int, weight func(int) float64) int {
+	total := 0.0
+	for _, id := range ids {
+		total += weight(id)
+	}
+
+	r := rand.Float64() * total
+	for _, id := range ids {
+		r -= weight(id)
+		if r < 0 {
+			return id
+		}
+	}
+
+	return ids[len(ids)-1]
+}
+
+// recentlyShownIds returns the identifiers of images shown within the given window, which is
+// either a number of backgrounds or a duration
+func recentlyShownIds(window string, now time.Time) (map[int]bool, error) {
+	if window == "" {
+		return nil, nil
+	}
+
+	count, countErr := strconv.Atoi(window)
+	duration, durationErr := time.ParseDuration(window)
+	if (countErr != nil && durationErr != nil) || count < 0 || duration < 0 {
+		return nil, fmt.Errorf(
+			"invalid no-repeat window: %s. Expected a number of backgrounds or a duration",
+			window,
+		)
+	}
+
+	history, err := lib.LoadHistory()
+	if err != nil {
+		return nil, err
+	}
+
+	recentIds := make(map[int]bool)
+	for i := len(history.Entries) - 1; i >= 0; i-- {
+		entry := history.Entries[i]
+
+		if countErr == nil && len(history.Entries)-i > count {
+			break
+		}
+
+		if durationErr == nil && now.Sub(entry.Time) > duration {
+			break
+		}
+
+		if entry.Id != 0 {
+			recentIds[entry.Id] = true
+		}
+	}
+
+	return recentIds, nil
+}
+
+// intersect returns the identifiers of a which are also in b
+func intersect(a []int, b []int) []int {
+	inB := make(map[int]bool, len(b))
+	for _, id := range b {
+		inB[id] = true
+	}
+
+	var result []int
+	for _, id := range a {
+		if inB[id] {
+			result = append(result, id)
+		}
+	}
+
+	return result
+}
+
+// SelectId picks a random identifier among the given ones, according to the selection flags
+// It allows other commands to reuse the selection behaviour
+func SelectId(ids []int) (int, error) {
+	return selectId(ids)
+}
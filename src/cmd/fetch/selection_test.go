@@ -0,0 +1,221 @@
+package fetch
+
+import (
+	"fmt"
+	"slices"
+	"testing"
+	"time"
+
+	"earth-view/lib"
+	"earth-view/lib/index"
+)
+
+func setSelection(t *testing.T, window string, shuffleBag bool) {
+	prevWindow, prevShuffle := noRepeatWindow, shuffle
+	noRepeatWindow, shuffle = window, shuffleBag
+
+	t.Cleanup(func() {
+		noRepeatWindow, shuffle = prevWindow, prevShuffle
+	})
+}
+
+func recordShown(t *testing.T, ids ...int) {
+	for _, id := range ids {
+		if err := lib.RecordHistory(fmt.Sprintf("/tmp/%d.jpeg", id), "feh"); err != nil {
+			t.Fatalf("Expected no error while recording history, got %v", err)
+		}
+	}
+}
+
+func TestSelectIdNoRepeatCount(t *testing.T) {
+	t.Setenv("XDG_STATE_HOME", t.TempDir())
+	setSelection(t, "2", false)
+	recordShown(t, 1003, 1004, 1006)
+
+	for i := 0; i < 50; i++ {
+		id, err := selectId(inputIds)
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+
+		if id == 1004 || id == 1006 {
+			t.Fatalf("Expected recently shown %d not to be picked", id)
+		}
+	}
+}
+
+func TestSelectIdNoRepeatDuration(t *testing.T) {
+	t.Setenv("XDG_STATE_HOME", t.TempDir())
+	setSelection(t, "1h", false)
+	recordShown(t, 1003, 1004, 1006)
+
+	for i := 0; i < 50; i++ {
+		id, _ := selectId(inputIds)
+		if id != 1007 {
+			t.Fatalf("Expected 1007 to be picked, got %d", id)
+		}
+	}
+}
+
+func TestSelectIdNoRepeatAllShown(t *testing.T) {
+	t.Setenv("XDG_STATE_HOME", t.TempDir())
+	setSelection(t, "10", false)
+	recordShown(t, inputIds...)
+
+	id, err := selectId(inputIds)
+	if err != nil || !slices.Contains(inputIds, id) {
+		t.Fatalf("Expected an id among %v, got %d (%v)", inputIds, id, err)
+	}
+}
+
+func TestSelectIdInvalidWindow(t *testing.T) {
+	t.Setenv("XDG_STATE_HOME", t.TempDir())
+	setSelection(t, "often", false)
+
+	if _, err := selectId(inputIds); err == nil {
+		t.Fatalf("Expected error for invalid window")
+	}
+}
+
+func TestSelectIdShuffle(t *testing.T) {
+	t.Setenv("XDG_STATE_HOME", t.TempDir())
+	setSelection(t, "", true)
+
+	for cycle := 0; cycle < 3; cycle++ {
+		var picked []int
+		for range inputIds {
+			id, err := selectId(inputIds)
+			if err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+
+			if slices.Contains(picked, id) {
+				t.Fatalf("Expected %d not to be picked twice in a cycle, got %v", id, picked)
+			}
+
+			picked = append(picked, id)
+		}
+	}
+
+	// Changing the catalog starts a new cycle
+	id, _ := selectId(altInputIds)
+	if !slices.Contains(altInputIds, id) {
+		t.Fatalf("Expected an id among %v, got %d", altInputIds, id)
+	}
+}
+
+func TestPickerReject(t *testing.T) {
+	t.Setenv("XDG_STATE_HOME", t.TempDir())
+	setSelection(t, "", true)
+
+	p, err := newPicker(inputIds)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	// Rejected images are never picked again by the same picker
+	p.reject(1003, fmt.Errorf("[1003] fetch failed: %w", lib.ErrNotFound))
+	p.reject(1004, fmt.Errorf("[1004] fetch failed: received HTTP 500"))
+	for i := 0; i < 2*len(inputIds); i++ {
+		if id, err := p.pick(); err != nil || id == 1003 || id == 1004 {
+			t.Fatalf("Expected rejected images not to be picked, got %d (%v)", id, err)
+		}
+	}
+
+	p.exclude(1006)
+	p.exclude(1007)
+	if _, err := p.pick(); err == nil {
+		t.Fatalf("Expected error when all images were excluded")
+	}
+
+	if err := p.save(); err != nil {
+		t.Fatalf("Expected shuffle bag to be saved, got %v", err)
+	}
+
+	// Only images missing upstream are dropped for good, across cycles
+	for i := 0; i < 3*len(inputIds); i++ {
+		if id, err := selectId(inputIds); err != nil || id == 1003 {
+			t.Fatalf("Expected missing image not to be picked again, got %d (%v)", id, err)
+		}
+	}
+}
+
+func TestRecentlyShownIds(t *testing.T) {
+	t.Setenv("XDG_STATE_HOME", t.TempDir())
+	recordShown(t, 1003, 1004)
+
+	recent, _ := recentlyShownIds("1m", time.Now().Add(time.Hour))
+	if len(recent) != 0 {
+		t.Fatalf("Expected no recent ids, got %v", recent)
+	}
+}
+
+func TestSelectIdPreferences(t *testing.T) {
+	t.Setenv("XDG_STATE_HOME", t.TempDir())
+	setSelection(t, "", false)
+
+	prefs := &lib.Preferences{}
+	prefs.Ban(lib.Preference{Id: 1003})
+	prefs.Ban(lib.Preference{Id: 1004})
+	prefs.Like(lib.Preference{Id: 1006})
+	if err := prefs.Save(); err != nil {
+		t.Fatalf("Expected no error while saving preferences, got %v", err)
+	}
+
+	prevWeight := favoriteWeight
+	favoriteWeight = 100
+	defer func() { favoriteWeight = prevWeight }()
+
+	favorites := 0
+	for i := 0; i < 200; i++ {
+		id, err := selectId(inputIds)
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+
+		if id == 1003 || id == 1004 {
+			t.Fatalf("Expected banned %d not to be picked", id)
+		}
+
+		if id == 1006 {
+			favorites++
+		}
+	}
+
+	if favorites < 150 {
+		t.Fatalf("Expected favorite to be picked most of the time, got %d/200", favorites)
+	}
+
+	if _, err := selectId([]int{1003, 1004}); err == nil {
+		t.Fatalf("Expected error when all images are banned")
+	}
+}
+
+func TestSelectIdLocation(t *testing.T) {
+	t.Setenv("XDG_STATE_HOME", t.TempDir())
+	t.Setenv("XDG_DATA_HOME", t.TempDir())
+	setSelection(t, "", false)
+
+	idx := index.New()
+	idx.Add(index.Entry{Id: 1003, Country: "France", Lat: 48.2, Lng: -3.0})
+	idx.Add(index.Entry{Id: 1004, Country: "Australia", Lat: -20.9, Lng: 142.7})
+	if err := idx.Save(); err != nil {
+		t.Fatalf("Expected no error while saving index, got %v", err)
+	}
+
+	prevFilterOpts := filterOpts
+	filterOpts = index.FilterOptions{Continents: []string{"Europe"}}
+	defer func() { filterOpts = prevFilterOpts }()
+
+	for i := 0; i < 20; i++ {
+		id, err := selectId(inputIds)
+		if err != nil || id != 1003 {
+			t.Fatalf("Expected 1003 to be picked, got %d (%v)", id, err)
+		}
+	}
+
+	filterOpts = index.FilterOptions{Countries: []string{"Spain"}}
+	if _, err := selectId(inputIds); err == nil {
+		t.Fatalf("Expected error when no image matches filters")
+	}
+}
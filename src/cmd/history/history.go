@@ -0,0 +1,141 @@
+/*
+Copyright © 2024 Nicolas Goudry <goudry.nicolas@gmail.com>
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package history
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"earth-view/cmd"
+	"earth-view/lib"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	jsonOutput bool
+	limit      int
+
+	historyCmd = &cobra.Command{
+		Use:   "history",
+		Short: "List shown backgrounds",
+		Long: `List the backgrounds shown on the desktop.
+
+Description:
+  This command lists the backgrounds set by the 'set' and 'daemon' commands,
+  most recent last. History is stored in JSON lines format in
+  '$XDG_STATE_HOME/earth-view/history.jsonl'.
+
+  The current background is marked with a '*'. It may not be the most recent
+  one when navigating through history with the 'prev' and 'next' commands.
+
+  By default, only the 20 most recent backgrounds are listed. This behaviour can
+  be changed by using the '--limit' flag, 0 listing the whole history.`,
+		DisableFlagsInUseLine: true,
+		SilenceUsage:          true,
+		Args:                  cobra.MaximumNArgs(0),
+		Run: func(_ *cobra.Command, _ []string) {
+			out, err := runHistoryCmd(limit, jsonOutput)
+			cobra.CheckErr(err)
+
+			if out != "" {
+				fmt.Println(out)
+			}
+		},
+	}
+)
+
+func init() {
+	cmd.RootCmd.AddCommand(historyCmd)
+
+	historyCmd.Flags().BoolVar(&jsonOutput, "json", false, "output entries in JSON lines format")
+	historyCmd.Flags().IntVarP(&limit, "limit", "n", 20, "maximum number of entries to list")
+}
+
+func runHistoryCmd(limit int, jsonOutput bool) (string, error) {
+	history, err := lib.LoadHistory()
+	if err != nil {
+		return "", err
+	}
+
+	start := 0
+	if limit > 0 && len(history.Entries) > limit {
+		start = len(history.Entries) - limit
+	}
+
+	var out strings.Builder
+
+	if jsonOutput {
+		for _, entry := range history.Entries[start:] {
+			line, err := json.Marshal(entry)
+			if err != nil {
+				return "", err
+			}
+
+			out.Write(line)
+			out.WriteByte('\n')
+		}
+
+		return strings.TrimSuffix(out.String(), "\n"), nil
+	}
+
+	if len(history.Entries) == 0 {
+		return "", nil
+	}
+
+	writer := tabwriter.NewWriter(&out, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(writer, "\tTIME\tID\tBACKEND\tPATH")
+
+	for i := start; i < len(history.Entries); i++ {
+		entry := history.Entries[i]
+
+		marker := ""
+		if i == history.Position {
+			marker = "*"
+		}
+
+		id := "-"
+		if entry.Id != 0 {
+			id = strconv.Itoa(entry.Id)
+		}
+
+		fmt.Fprintf(
+			writer,
+			"%s\t%s\t%s\t%s\t%s\n",
+			marker,
+			entry.Time.Local().Format(time.DateTime),
+			id,
+			entry.Backend,
+			entry.Path,
+		)
+	}
+
+	if err := writer.Flush(); err != nil {
+		return "", err
+	}
+
+	return strings.TrimSuffix(out.String(), "\n"), nil
+}
@@ -0,0 +1,155 @@
+/*
+Copyright © 2024 Nicolas Goudry <goudry.nicolas@gmail.com>
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package history
+
+import (
+	"errors"
+	"fmt"
+	"os"
+
+	"earth-view/cmd"
+	"earth-view/cmd/fetch"
+	"earth-view/cmd/set"
+	"earth-view/lib"
+	"earth-view/lib/wallpaper"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	backendFlags set.BackendFlags
+
+	navigateHelp = `  The background is set again with the backend selected by the '--backend'
+  flag, or detected from the environment, which may differ from the one
+  recorded in history. If the image file does not exist anymore, for example
+  because it was garbage collected, it is downloaded again, and resized again
+  if it was resized. Captioned and lock screen images cannot be derived again,
+  and an error is reported instead.
+
+  If a '.current' symbolic link exists in the image directory, it is updated.
+
+  The path of the image is printed to the standard output.`
+
+	prevCmd = &cobra.Command{
+		Use:   "prev",
+		Short: "Set previous background",
+		Long: fmt.Sprintf(`Set the previous background from history.
+
+Description:
+  This command moves back in the history of shown backgrounds and sets the
+  matching background. It can be repeated to go further back.
+
+%s
+
+Backends:
+%s`, navigateHelp, set.BackendHelp),
+		DisableFlagsInUseLine: true,
+		SilenceUsage:          true,
+		Args:                  cobra.MaximumNArgs(0),
+		Run: func(_ *cobra.Command, _ []string) {
+			filePath, err := runNavigateCmd(-1, wallpaper.ExecRunner{})
+			cobra.CheckErr(err)
+			fmt.Println(filePath)
+		},
+	}
+
+	nextCmd = &cobra.Command{
+		Use:   "next",
+		Short: "Set next background",
+		Long: fmt.Sprintf(`Set the next background from history.
+
+Description:
+  This command moves forward in the history of shown backgrounds, after going
+  back with the 'prev' command, and sets the matching background.
+
+%s
+
+Backends:
+%s`, navigateHelp, set.BackendHelp),
+		DisableFlagsInUseLine: true,
+		SilenceUsage:          true,
+		Args:                  cobra.MaximumNArgs(0),
+		Run: func(_ *cobra.Command, _ []string) {
+			filePath, err := runNavigateCmd(1, wallpaper.ExecRunner{})
+			cobra.CheckErr(err)
+			fmt.Println(filePath)
+		},
+	}
+)
+
+func init() {
+	cmd.RootCmd.AddCommand(prevCmd)
+	cmd.RootCmd.AddCommand(nextCmd)
+
+	// Both commands share the same flags values since only one of them runs
+	backendFlags.AddFlags(prevCmd.Flags())
+	backendFlags.AddFlags(nextCmd.Flags())
+}
+
+func runNavigateCmd(offset int, runner wallpaper.Runner) (string, error) {
+	opts, err := backendFlags.Options()
+	if err != nil {
+		return "", err
+	}
+
+	backend, err := backendFlags.Backend(runner)
+	if err != nil {
+		return "", err
+	}
+
+	history, err := lib.LoadHistory()
+	if err != nil {
+		return "", err
+	}
+
+	previousPosition := history.Position
+
+	entry, err := history.Move(offset)
+	if err != nil {
+		return "", err
+	}
+
+	// The background is set when it is not tracked, which is not fatal
+	err = show(backend, opts, entry)
+	if errors.Is(err, wallpaper.ErrNotTracked) {
+		fmt.Fprintf(os.Stderr, "%s\n", err)
+	} else if err != nil {
+		// Stay on the current entry since its background is still shown
+		history.Move(previousPosition - history.Position)
+		return "", err
+	}
+
+	return entry.Path, nil
+}
+
+// show sets the background recorded in the given history entry
+func show(backend wallpaper.Backend, opts wallpaper.Options, entry lib.HistoryEntry) error {
+	if entry.Id != 0 {
+		if err := fetch.Restore(entry.Id, entry.Path); err != nil {
+			return err
+		}
+	} else if !lib.FileExists(entry.Path) {
+		return fmt.Errorf("image %s does not exist anymore", entry.Path)
+	}
+
+	return wallpaper.Show(backend, entry.Path, opts, false, "")
+}
@@ -0,0 +1,164 @@
+/*
+Copyright © 2024 Nicolas Goudry <goudry.nicolas@gmail.com>
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package index
+
+import (
+	"fmt"
+	"os"
+
+	"earth-view/cmd/fetch"
+	libindex "earth-view/lib/index"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	batchSize int
+	input     string
+	output    string
+	prune     bool
+	quiet     bool
+	refresh   bool
+	retry     int
+
+	buildCmd = &cobra.Command{
+		Use:   "build",
+		Short: "Build images metadata index",
+		Long: `Build the local index of Google Earth View images metadata.
+
+Description:
+  This command fetches the metadata of images from gstatic.com and stores their
+  country, region, coordinates, attribution, image dimensions and statistics in
+  the index. The image data is not saved.
+
+  When '--input' flag is provided, the command expects it to be fed with a file
+  containing the output of the 'list' command. Otherwise, the known range of
+  possible identifiers is used.
+
+  The build is incremental: only images missing from the index, or indexed when
+  downloading them and not measured yet, are fetched. Images which do not exist
+  are remembered in '$XDG_DATA_HOME/earth-view/missing.json' so that they are
+  not fetched again. This behaviour can be changed by using the '--refresh'
+  flag, which fetches all images again. The '--prune' flag removes images which
+  are not part of the input from the index.
+
+  By default, the index is saved in '$XDG_DATA_HOME/earth-view/index.json'. This
+  behaviour can be changed by using the '--output' flag, for example to generate
+  an index to embed in the binary.`,
+		DisableFlagsInUseLine: true,
+		SilenceUsage:          true,
+		Args:                  cobra.MaximumNArgs(0),
+		Run: func(_ *cobra.Command, _ []string) {
+			out, err := runBuildCmd(input, output, libindex.BuildOptions{
+				Refresh: refresh,
+				Prune:   prune,
+				Workers: batchSize,
+				Retry:   retry,
+			}, quiet)
+			cobra.CheckErr(err)
+
+			if !quiet {
+				fmt.Println(out)
+			}
+		},
+	}
+)
+
+func init() {
+	indexCmd.AddCommand(buildCmd)
+
+	buildCmd.Flags().
+		IntVarP(&batchSize, "batch-size", "b", 20, "number of parallel calls to gstatic.com")
+	buildCmd.Flags().StringVarP(&input, "input", "i", "", "input file to index images from")
+	buildCmd.Flags().StringVarP(&output, "output", "o", "", "write index to given file")
+	buildCmd.Flags().BoolVar(&prune, "prune", false, "remove images missing from input")
+	buildCmd.Flags().BoolVarP(&quiet, "quiet", "q", false, "do not output anything")
+	buildCmd.Flags().BoolVar(&refresh, "refresh", false, "fetch already indexed images again")
+	buildCmd.Flags().
+		IntVarP(&retry, "retry", "r", 3, "number of retries before skipping an image in case of non 200 HTTP status code")
+}
+
+func runBuildCmd(
+	input string,
+	output string,
+	opts libindex.BuildOptions,
+	quiet bool,
+) (string, error) {
+	ids, err := fetch.ReadInputIds(input)
+	if err != nil {
+		return "", err
+	}
+
+	if opts.Missing, err = libindex.LoadMissing(); err != nil {
+		return "", err
+	}
+
+	var idx *libindex.Index
+	if output == "" {
+		output = libindex.Path()
+		idx, err = libindex.Load()
+	} else {
+		idx, err = libindex.LoadFile(output)
+	}
+	if err != nil {
+		return "", err
+	}
+
+	if !quiet {
+		opts.OnProgress = func(done int, total int) {
+			fmt.Fprintf(os.Stderr, "\rIndexing images: %d/%d", done, total)
+			if done == total {
+				fmt.Fprintln(os.Stderr)
+			}
+		}
+	}
+
+	result := idx.Build(ids, opts)
+
+	// Identifiers from the known range are mostly missing, they are not reported as errors
+	if len(result.Errors) > 0 && !quiet {
+		fmt.Fprintln(os.Stderr, "Encountered the following errors:")
+
+		for _, err := range result.Errors {
+			fmt.Fprintf(os.Stderr, "  %v\n", err)
+		}
+		fmt.Fprintln(os.Stderr, "")
+	}
+
+	if err := idx.SaveFile(output); err != nil {
+		return "", err
+	}
+
+	if err := libindex.SaveMissing(opts.Missing); err != nil {
+		return "", err
+	}
+
+	return fmt.Sprintf(
+		"Indexed %d images (%d added, %d updated, %d removed, %d failed) in %s",
+		idx.Len(),
+		result.Added,
+		result.Updated,
+		result.Removed,
+		len(result.Errors),
+		output,
+	), nil
+}
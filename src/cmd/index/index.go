@@ -0,0 +1,51 @@
+/*
+Copyright © 2024 Nicolas Goudry <goudry.nicolas@gmail.com>
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package index
+
+import (
+	"earth-view/cmd"
+
+	"github.com/spf13/cobra"
+)
+
+var indexCmd = &cobra.Command{
+	Use:   "index",
+	Short: "Manage images metadata index",
+	Long: `Manage the local index of Google Earth View images metadata.
+
+Description:
+  The index stores the location, attribution and dimensions of images, so that
+  they can be selected by location or searched without fetching them. It is
+  saved in '$XDG_DATA_HOME/earth-view/index.json' and filled when downloading
+  images, or all at once by the 'index build' command.
+
+  The binary can also be built with an embedded index, by generating it in the
+  'lib/index' directory of the source tree and using the 'embedindex' build
+  tag. The index file, if any, is then loaded on top of the embedded index.`,
+	DisableFlagsInUseLine: true,
+	SilenceUsage:          true,
+	Args:                  cobra.MaximumNArgs(0),
+}
+
+func init() {
+	cmd.RootCmd.AddCommand(indexCmd)
+}
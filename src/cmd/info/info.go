@@ -0,0 +1,298 @@
+/*
+Copyright © 2024 Nicolas Goudry <goudry.nicolas@gmail.com>
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package info
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"image/jpeg"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+
+	"earth-view/cmd"
+	"earth-view/lib"
+	"earth-view/lib/geo"
+	"earth-view/lib/imaging"
+	"earth-view/lib/index"
+	"earth-view/lib/preview"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	dir             string
+	jsonOutput      bool
+	previewProtocol string
+
+	infoCmd = &cobra.Command{
+		Use:   "info identifier|file|current",
+		Short: "Show image information",
+		Long: fmt.Sprintf(`Show the location and metadata of a Google Earth View image.
+
+Description:
+  This command shows where an image was taken: its country and region, its
+  coordinates in decimal degrees and in degrees, minutes and seconds, its
+  attribution and links to Google Maps, Google Earth and OpenStreetMap. Images
+  measured by the 'index build' command also show their statistics, such as
+  brightness and haze (see 'fetch random --help').
+
+  The image is either given by its identifier, by the path of an image file
+  saved by the 'fetch' commands, or by 'current' for the current background.
+  The current background is resolved through the '.current' symbolic link of
+  the directory set by the '--dir' flag, defaulting to the current working
+  directory, or from history if there is no such link.
+
+  Metadata is read from the local index. If the image is not indexed yet, its
+  metadata is retrieved from gstatic.com and added to the index.
+
+%s
+
+  When the image is given by its identifier, it is downloaded to be previewed
+  but it is not saved.`, preview.ProtocolHelp),
+		DisableFlagsInUseLine: true,
+		SilenceUsage:          true,
+		Args: func(cmd *cobra.Command, args []string) error {
+			if err := cobra.ExactArgs(1)(cmd, args); err != nil {
+				return fmt.Errorf("missing required argument 'identifier|file|current'")
+			}
+
+			return nil
+		},
+		Run: func(_ *cobra.Command, args []string) {
+			out, err := runInfoCmd(args[0], dir, jsonOutput)
+			cobra.CheckErr(err)
+			fmt.Println(out)
+
+			if previewProtocol != "" {
+				cobra.CheckErr(runPreview(args[0], dir, previewProtocol))
+			}
+		},
+	}
+)
+
+func init() {
+	cmd.RootCmd.AddCommand(infoCmd)
+
+	infoCmd.Flags().StringVar(&dir, "dir", ".", "directory holding the '.current' link")
+	infoCmd.Flags().BoolVar(&jsonOutput, "json", false, "output information in JSON format")
+	infoCmd.Flags().StringVar(&previewProtocol, "preview", "", "render image in the terminal")
+	infoCmd.Flags().Lookup("preview").NoOptDefVal = string(preview.Auto)
+}
+
+// Info holds the information shown about an image
+type Info struct {
+	Id          int     `json:"id"`
+	Path        string  `json:"path,omitempty"`
+	Country     string  `json:"country,omitempty"`
+	Region      string  `json:"region,omitempty"`
+	Continent   string  `json:"continent,omitempty"`
+	Lat         float64 `json:"lat"`
+	Lng         float64 `json:"lng"`
+	Dms         string  `json:"dms"`
+	Attribution string  `json:"attribution,omitempty"`
+	MapsLink    string  `json:"mapsLink"`
+	EarthLink   string  `json:"earthLink"`
+	OsmLink     string  `json:"osmLink"`
+	// Stats are missing for images indexed without their content
+	Stats *imaging.Stats `json:"stats,omitempty"`
+}
+
+// resolveTarget returns the identifier of the given image and the path of its file, if any
+func resolveTarget(target string, dir string) (int, string, error) {
+	if id, err := strconv.Atoi(target); err == nil {
+		return id, "", nil
+	}
+
+	filePath := target
+	if target == "current" {
+		currentLink := filepath.Join(dir, ".current")
+
+		if _, err := os.Lstat(currentLink); err == nil {
+			filePath = currentLink
+		} else {
+			history, err := lib.LoadHistory()
+			if err != nil {
+				return -1, "", err
+			}
+
+			entry, ok := history.Current()
+			if !ok {
+				return -1, "", fmt.Errorf("no current background found in %s or in history", dir)
+			}
+
+			filePath = entry.Path
+		}
+	}
+
+	if resolved, err := filepath.EvalSymlinks(filePath); err == nil {
+		filePath = resolved
+	}
+
+	if absPath, err := filepath.Abs(filePath); err == nil {
+		filePath = absPath
+	}
+
+	id, ok := lib.IdFromPath(filePath)
+	if !ok {
+		return -1, "", fmt.Errorf("not a Google Earth View image file: %s", filePath)
+	}
+
+	return id, filePath, nil
+}
+
+// ResolveTarget returns the identifier of the given image and the path of its file, if any
+// It allows other commands to accept the same images as the 'info' command
+func ResolveTarget(target string, dir string) (int, string, error) {
+	return resolveTarget(target, dir)
+}
+
+func runInfoCmd(target string, dir string, jsonOutput bool) (string, error) {
+	id, filePath, err := resolveTarget(target, dir)
+	if err != nil {
+		return "", err
+	}
+
+	entry, err := index.Lookup(id)
+	if err != nil {
+		return "", err
+	}
+
+	point := entry.Point()
+	info := Info{
+		Id:          id,
+		Path:        filePath,
+		Country:     entry.Country,
+		Region:      entry.Region,
+		Continent:   geo.ContinentOf(entry.Country),
+		Lat:         entry.Lat,
+		Lng:         entry.Lng,
+		Dms:         point.FormatDMS(),
+		Attribution: entry.Attribution,
+		OsmLink:     geo.OsmLink(point, entry.Zoom),
+		Stats:       entry.Stats,
+	}
+
+	info.MapsLink, info.EarthLink = entry.Links()
+
+	if jsonOutput {
+		out, err := json.MarshalIndent(info, "", "  ")
+		return string(out), err
+	}
+
+	return formatInfo(info)
+}
+
+// runPreview renders the given image in the terminal, downloading it if there is no image file
+func runPreview(target string, dir string, previewProtocol string) error {
+	protocol, err := preview.ParseProtocol(previewProtocol)
+	if err != nil {
+		return err
+	}
+
+	id, filePath, err := resolveTarget(target, dir)
+	if err != nil {
+		return err
+	}
+
+	if filePath != "" && lib.FileExists(filePath) {
+		return preview.RenderFile(os.Stdout, filePath, protocol)
+	}
+
+	asset := lib.Asset{Id: id}
+	content, err := asset.GetContent()
+	if err != nil {
+		return err
+	}
+
+	img, err := jpeg.Decode(bytes.NewReader(content))
+	if err != nil {
+		return fmt.Errorf("[%d] failed to decode image: %s", id, err)
+	}
+
+	return preview.RenderImage(os.Stdout, img, protocol)
+}
+
+// formatInfo formats information as aligned labels and values
+func formatInfo(info Info) (string, error) {
+	var location []string
+	for _, part := range []string{info.Region, info.Country} {
+		if part != "" {
+			location = append(location, part)
+		}
+	}
+
+	locationText := strings.Join(location, ", ")
+	if locationText == "" {
+		locationText = "-"
+	}
+
+	if info.Continent != "" {
+		locationText += " (" + info.Continent + ")"
+	}
+
+	rows := [][2]string{
+		{"Identifier", strconv.Itoa(info.Id)},
+		{"Location", locationText},
+		{"Coordinates", fmt.Sprintf("%.6f, %.6f", info.Lat, info.Lng)},
+		{"", info.Dms},
+		{"Attribution", info.Attribution},
+		{"Google Maps", info.MapsLink},
+		{"Google Earth", info.EarthLink},
+		{"OpenStreetMap", info.OsmLink},
+	}
+
+	if info.Stats != nil {
+		rows = append(rows, [2]string{"Statistics", fmt.Sprintf(
+			"brightness %.2f, contrast %.2f, colorfulness %.1f, haze %.2f, hue %s",
+			info.Stats.Brightness,
+			info.Stats.Contrast,
+			info.Stats.Colorfulness,
+			info.Stats.Haze,
+			info.Stats.Hue,
+		)})
+	}
+
+	if info.Path != "" {
+		rows = append(rows, [2]string{"File", info.Path})
+	}
+
+	var out strings.Builder
+
+	writer := tabwriter.NewWriter(&out, 0, 0, 2, ' ', 0)
+	for _, row := range rows {
+		if row[0] == "Attribution" && row[1] == "" {
+			continue
+		}
+
+		fmt.Fprintf(writer, "%s\t%s\n", row[0], row[1])
+	}
+
+	if err := writer.Flush(); err != nil {
+		return "", err
+	}
+
+	return strings.TrimSuffix(out.String(), "\n"), nil
+}
@@ -0,0 +1,110 @@
+package info
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"earth-view/lib"
+	"earth-view/lib/index"
+)
+
+func setup(t *testing.T) string {
+	t.Setenv("XDG_DATA_HOME", t.TempDir())
+	t.Setenv("XDG_STATE_HOME", t.TempDir())
+
+	idx := index.New()
+	idx.Add(index.Entry{
+		Id:        1003,
+		Country:   "France",
+		Region:    "Brittany",
+		Lat:       48.2,
+		Lng:       -3,
+		MapsLink:  "https://www.google.com/maps/@48.2,-3,14z/data=!3m1!1e3",
+		EarthLink: "https://earth.google.com/web/@48.2,-3,0a,1500d,35y,0h,0t,0r",
+	})
+	idx.Add(index.Entry{Id: 1004, Country: "Chile", Lat: -21.2, Lng: -69.6, Zoom: 15})
+	if err := idx.Save(); err != nil {
+		t.Fatalf("Expected no error while saving index, got %v", err)
+	}
+
+	dir := t.TempDir()
+	for _, name := range []string{"1003.jpeg", "1004.jpeg"} {
+		if err := os.WriteFile(filepath.Join(dir, name), nil, 0644); err != nil {
+			t.Fatalf("Expected no error while creating image file, got %v", err)
+		}
+	}
+
+	return dir
+}
+
+func TestInfo(t *testing.T) {
+	dir := setup(t)
+
+	out, err := runInfoCmd("1003", dir, false)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	for _, expected := range []string{
+		"Brittany, France (Europe)",
+		`48°12'00.0"N 3°00'00.0"W`,
+		"https://www.openstreetmap.org/?mlat=48.200000&mlon=-3.000000",
+		"https://www.google.com/maps/@48.2,-3,14z/data=!3m1!1e3",
+		"https://earth.google.com/web/@48.2,-3,0a,1500d,35y,0h,0t,0r",
+	} {
+		if !strings.Contains(out, expected) {
+			t.Fatalf("Expected output to contain %q, got %q", expected, out)
+		}
+	}
+
+	out, _ = runInfoCmd(filepath.Join(dir, "1004.jpeg"), dir, true)
+
+	var info Info
+	if err := json.Unmarshal([]byte(out), &info); err != nil {
+		t.Fatalf("Expected JSON output, got %q (%v)", out, err)
+	}
+
+	if info.Id != 1004 || info.Country != "Chile" || !strings.Contains(info.MapsLink, ",15z/") {
+		t.Fatalf("Expected information about 1004, got %+v", info)
+	}
+
+	// Images derived by the fetch commands are named after the original image
+	out, err = runInfoCmd(filepath.Join(dir, "1003-1920x1080-caption.jpeg"), dir, false)
+	if err != nil || !strings.Contains(out, "Brittany, France") {
+		t.Fatalf("Expected information about 1003 for derived image, got %q (%v)", out, err)
+	}
+
+	if _, err := runInfoCmd(filepath.Join(dir, "wallpaper.png"), dir, false); err == nil {
+		t.Fatalf("Expected error for file which is not an Earth View image")
+	}
+}
+
+func TestInfoCurrent(t *testing.T) {
+	dir := setup(t)
+
+	if _, err := runInfoCmd("current", dir, false); err == nil {
+		t.Fatalf("Expected error without current background")
+	}
+
+	// History is used when there is no current link
+	if err := lib.RecordHistory(filepath.Join(dir, "1004.jpeg"), "feh"); err != nil {
+		t.Fatalf("Expected no error while recording history, got %v", err)
+	}
+
+	info, _ := runInfoCmd("current", dir, true)
+	if !strings.Contains(info, `"id": 1004`) {
+		t.Fatalf("Expected current background from history, got %q", info)
+	}
+
+	if err := lib.LinkCurrent(dir, filepath.Join(dir, "1003.jpeg")); err != nil {
+		t.Fatalf("Expected no error while linking current image, got %v", err)
+	}
+
+	info, _ = runInfoCmd("current", dir, true)
+	if !strings.Contains(info, `"id": 1003`) {
+		t.Fatalf("Expected current background from link, got %q", info)
+	}
+}
@@ -5,6 +5,8 @@ import (
 	"strings"
 	"time"
 
+	"earth-view/lib/theme"
+
 	"github.com/charmbracelet/bubbles/progress"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
@@ -12,21 +14,6 @@ import (
 
 const padding = 2
 
-var (
-	red = lipgloss.AdaptiveColor{
-		Light: "#d20f39",
-		Dark:  "#f38ba8",
-	}
-	green = lipgloss.AdaptiveColor{
-		Light: "#40a02b",
-		Dark:  "#a6e3a1",
-	}
-	orange = lipgloss.AdaptiveColor{
-		Light: "#fe640b",
-		Dark:  "#fab387",
-	}
-)
-
 // Defer program end to allow progress bar to go to 100% before UI is cleared
 func finalPause() tea.Cmd {
 	return tea.Tick(time.Second, func(_ time.Time) tea.Msg {
@@ -112,7 +99,7 @@ func (m model) View() string {
 	if m.abort {
 		return lipgloss.NewStyle().
 			PaddingBottom(1).
-			Foreground(red).
+			Foreground(theme.Red).
 			Render("Operation aborted before end")
 	}
 
@@ -127,15 +114,15 @@ func (m model) View() string {
 			lipgloss.NewStyle().
 				PaddingLeft(padding).
 				PaddingBottom(1).
-				Foreground(green).
+				Foreground(theme.Green).
 				Render("Found: "+strconv.Itoa(m.success)),
 			separator,
 			lipgloss.NewStyle().
-				Foreground(orange).
+				Foreground(theme.Orange).
 				Render("Skipped: "+strconv.Itoa(m.skipped)),
 			separator,
 			lipgloss.NewStyle().
-				Foreground(red).
+				Foreground(theme.Red).
 				Render("Errors: "+strconv.Itoa(m.errored)),
 		),
 	)
@@ -0,0 +1,61 @@
+/*
+Copyright © 2024 Nicolas Goudry <goudry.nicolas@gmail.com>
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package mirror
+
+import (
+	"net/http"
+	"strings"
+
+	libmirror "earth-view/lib/mirror"
+)
+
+// handler serves the assets of a mirror with the layout of gstatic.com, each '<id>.json' file
+// holding the metadata of an image along with the image encoded in its 'dataUri' field
+type handler struct {
+	mirror *libmirror.Mirror
+}
+
+func (h handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		w.Header().Set("Allow", "GET, HEAD")
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	// The Chrome extension fetches assets from its own origin
+	w.Header().Set("Access-Control-Allow-Origin", "*")
+
+	value, ok := strings.CutSuffix(strings.TrimPrefix(r.URL.Path, "/"), ".json")
+	id, valid := libmirror.ParseId(value)
+	if !ok || !valid {
+		http.NotFound(w, r)
+		return
+	}
+
+	content, err := h.mirror.Asset(id)
+	if err != nil {
+		libmirror.ServeError(w, id, err)
+		return
+	}
+
+	libmirror.ServeContent(w, r, value+".json", content, libmirror.ImmutableCache)
+}
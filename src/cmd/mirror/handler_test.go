@@ -0,0 +1,167 @@
+package mirror
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"earth-view/lib"
+	"earth-view/lib/index"
+	libmirror "earth-view/lib/mirror"
+)
+
+var image = []byte("\xff\xd8\xff\xe0 image 1003")
+
+// newSeededServer serves a mirror holding a single asset, counting requests
+func newSeededServer(t *testing.T, requests *int) *httptest.Server {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "1003.jpeg"), image, 0644); err != nil {
+		t.Fatalf("Failed to write image: %s", err)
+	}
+
+	metadata := []byte(`{"country":"France","lat":48.85,"lng":2.35}`)
+	if err := os.WriteFile(filepath.Join(dir, "1003.json"), metadata, 0644); err != nil {
+		t.Fatalf("Failed to write metadata: %s", err)
+	}
+
+	m := libmirror.New(dir)
+	m.Fetch = func(id int) (*lib.Asset, error) {
+		return nil, fmt.Errorf("[%d] fetch failed: %w", id, lib.ErrNotFound)
+	}
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		*requests++
+		handler{mirror: m}.ServeHTTP(w, r)
+	}))
+	t.Cleanup(ts.Close)
+
+	return ts
+}
+
+func TestRoundTrip(t *testing.T) {
+	t.Setenv("XDG_DATA_HOME", t.TempDir())
+	t.Cleanup(func() { lib.SetBaseUrl(lib.DefaultBaseUrl) })
+
+	var upstreamRequests int
+	upstream := newSeededServer(t, &upstreamRequests)
+
+	// The second instance fetches missing assets from the first one, as set by '--base-url'
+	lib.SetBaseUrl(upstream.URL + "/")
+
+	m := newMirror(t.TempDir())
+	ts := httptest.NewServer(handler{mirror: m})
+	defer ts.Close()
+
+	for path, status := range map[string]int{
+		"/1003.json": http.StatusOK,
+		"/999.json":  http.StatusNotFound,
+	} {
+		res, err := http.Get(ts.URL + path)
+		if err != nil {
+			t.Fatalf("Expected response, got error: %s", err)
+		}
+		res.Body.Close()
+
+		if res.StatusCode != status {
+			t.Fatalf("Expected HTTP %d for %s, got HTTP %d", status, path, res.StatusCode)
+		}
+	}
+
+	if upstreamRequests != 2 {
+		t.Fatalf("Expected assets to be fetched from upstream, got %d requests", upstreamRequests)
+	}
+
+	if !lib.FileExists(m.ImagePath(1003)) || !lib.FileExists(m.MetadataPath(1003)) {
+		t.Fatalf("Expected asset to be saved in mirror")
+	}
+
+	idx, err := index.Load()
+	if err != nil {
+		t.Fatalf("Failed to load index: %s", err)
+	}
+	if entry, ok := idx.Get(1003); !ok || entry.Country != "France" {
+		t.Fatalf("Expected fetched asset to be indexed, got %v", entry)
+	}
+
+	// Clients of the second instance are served from its directory
+	lib.SetBaseUrl(ts.URL + "/")
+
+	for i := 0; i < 2; i++ {
+		asset := lib.Asset{Id: 1003}
+		content, err := asset.GetContent()
+		if err != nil {
+			t.Fatalf("Expected asset to be fetched from mirror, got error: %s", err)
+		}
+
+		if !bytes.Equal(content, image) {
+			t.Fatalf("Expected image to survive the round trip, got %q", content)
+		}
+		if asset.Metadata["country"] != "France" {
+			t.Fatalf("Expected metadata to survive the round trip, got %v", asset.Metadata)
+		}
+	}
+
+	if upstreamRequests != 2 {
+		t.Fatalf(
+			"Expected asset to be fetched once from upstream, got %d requests",
+			upstreamRequests,
+		)
+	}
+}
+
+func TestHandler(t *testing.T) {
+	var requests int
+	ts := newSeededServer(t, &requests)
+
+	res, err := http.Get(ts.URL + "/1003.json")
+	if err != nil {
+		t.Fatalf("Expected response, got error: %s", err)
+	}
+	res.Body.Close()
+
+	if res.StatusCode != http.StatusOK || res.Header.Get("Access-Control-Allow-Origin") != "*" {
+		t.Fatalf("Expected asset readable from any origin, got HTTP %d", res.StatusCode)
+	}
+
+	req, _ := http.NewRequest(http.MethodGet, ts.URL+"/1003.json", nil)
+	req.Header.Set("If-None-Match", res.Header.Get("ETag"))
+	if res, err = http.DefaultClient.Do(req); err != nil {
+		t.Fatalf("Expected response, got error: %s", err)
+	}
+	res.Body.Close()
+
+	if res.StatusCode != http.StatusNotModified {
+		t.Fatalf("Expected HTTP 304 for matching ETag, got HTTP %d", res.StatusCode)
+	}
+
+	for path, status := range map[string]int{
+		"/999.json":     http.StatusNotFound,
+		"/1003.jpeg":    http.StatusNotFound,
+		"/v3/1003.json": http.StatusNotFound,
+		"/catalog.json": http.StatusNotFound,
+	} {
+		res, err := http.Get(ts.URL + path)
+		if err != nil {
+			t.Fatalf("Expected response, got error: %s", err)
+		}
+		res.Body.Close()
+
+		if res.StatusCode != status {
+			t.Fatalf("Expected HTTP %d for %s, got HTTP %d", status, path, res.StatusCode)
+		}
+	}
+
+	res, err = http.Post(ts.URL+"/1003.json", "application/json", nil)
+	if err != nil {
+		t.Fatalf("Expected response, got error: %s", err)
+	}
+	res.Body.Close()
+
+	if res.StatusCode != http.StatusMethodNotAllowed {
+		t.Fatalf("Expected HTTP 405 for POST request, got HTTP %d", res.StatusCode)
+	}
+}
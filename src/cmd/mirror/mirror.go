@@ -0,0 +1,49 @@
+/*
+Copyright © 2024 Nicolas Goudry <goudry.nicolas@gmail.com>
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package mirror
+
+import (
+	"earth-view/cmd"
+
+	"github.com/spf13/cobra"
+)
+
+var mirrorCmd = &cobra.Command{
+	Use:   "mirror",
+	Short: "Manage a mirror of images assets",
+	Long: `Manage a local mirror of Google Earth View images assets.
+
+Description:
+  A mirror is a directory holding images, saved as '<id>.jpeg' files like the
+  fetch commands do, and their metadata, saved as '<id>.json' files. Assets
+  missing from the mirror are fetched on demand and saved for later use.
+
+  The same directory can be shared over HTTP by the 'serve' command, and served
+  as a replacement of gstatic.com by the 'mirror serve' command.`,
+	DisableFlagsInUseLine: true,
+	SilenceUsage:          true,
+	Args:                  cobra.MaximumNArgs(0),
+}
+
+func init() {
+	cmd.RootCmd.AddCommand(mirrorCmd)
+}
@@ -0,0 +1,128 @@
+/*
+Copyright © 2024 Nicolas Goudry <goudry.nicolas@gmail.com>
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package mirror
+
+import (
+	"context"
+	"errors"
+	"log"
+	"net/http"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"syscall"
+	"time"
+
+	"earth-view/lib"
+	"earth-view/lib/index"
+	libmirror "earth-view/lib/mirror"
+
+	"github.com/spf13/cobra"
+)
+
+const shutdownTimeout = 10 * time.Second
+
+var (
+	addr string
+	dir  string
+
+	serveCmd = &cobra.Command{
+		Use:   "serve",
+		Short: "Serve a mirror as a replacement of gstatic.com",
+		Long: `Serve a mirror of Google Earth View assets with the same layout as gstatic.com.
+
+Description:
+  This command starts an HTTP server listening on the address set by the
+  '--addr' flag, which serves the assets of the mirror saved in the directory
+  set by the '--dir' flag. Each asset is served as '/<id>.json', holding the
+  metadata of the image and the image itself encoded in its 'dataUri' field,
+  exactly like 'https://www.gstatic.com/prettyearth/assets/data/v3' does.
+
+  Assets missing from the mirror are fetched from the URL set by the
+  '--base-url' flag, defaulting to gstatic.com, and saved for later requests.
+
+  Other instances can then use the mirror instead of gstatic.com, by setting
+  its URL with the '--base-url' flag or the EARTH_VIEW_BASE_URL environment
+  variable, e.g. 'http://mirror.lan:8080'. Mirrors can be chained this way.`,
+		DisableFlagsInUseLine: true,
+		SilenceUsage:          true,
+		Args:                  cobra.MaximumNArgs(0),
+		Run: func(_ *cobra.Command, _ []string) {
+			cobra.CheckErr(runServeCmd(addr, dir))
+		},
+	}
+)
+
+func init() {
+	mirrorCmd.AddCommand(serveCmd)
+
+	serveCmd.Flags().StringVar(&addr, "addr", ":8080", "address to listen on")
+	serveCmd.Flags().StringVar(&dir, "dir", ".", "directory holding mirrored assets")
+}
+
+// newMirror returns the mirror stored in the given directory, recording fetched assets in the
+// index like the fetch commands do
+func newMirror(dir string) *libmirror.Mirror {
+	m := libmirror.New(dir)
+	m.OnFetch = func(asset *lib.Asset) {
+		if err := index.Record(asset); err != nil {
+			log.Printf("failed to record image metadata in index: %s", err)
+		}
+	}
+
+	return m
+}
+
+func runServeCmd(addr string, dir string) error {
+	absDir, err := filepath.Abs(dir)
+	if err != nil {
+		return err
+	}
+
+	srv := &http.Server{
+		Addr:              addr,
+		Handler:           handler{mirror: newMirror(absDir)},
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	go func() {
+		<-ctx.Done()
+
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+		defer cancel()
+
+		if err := srv.Shutdown(shutdownCtx); err != nil {
+			log.Printf("failed to shut down server: %s", err)
+		}
+	}()
+
+	log.Printf("serving mirror of %s on %s", absDir, addr)
+
+	if err := srv.ListenAndServe(); !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,170 @@
+/*
+Copyright © 2024 Nicolas Goudry <goudry.nicolas@gmail.com>
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package palette
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"image/jpeg"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+
+	"earth-view/cmd"
+	"earth-view/cmd/info"
+	"earth-view/lib"
+	libpalette "earth-view/lib/palette"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	colors      int
+	dir         string
+	format      string
+	light       bool
+	method      string
+	minContrast float64
+
+	paletteCmd = &cobra.Command{
+		Use:   "palette identifier|file|current",
+		Short: "Extract a color palette from an image",
+		Long: fmt.Sprintf(`Extract a color palette from a Google Earth View image.
+
+Description:
+  This command finds the dominant colors of an image and derives a palette from
+  them for terminal and desktop themes: a background, a foreground and the 16
+  terminal colors. The background is the darkest dominant color, darkened
+  further, and the foreground is the lightest one, lightened until its contrast
+  with the background meets WCAG level AAA. The '--light' flag swaps them for a
+  light theme. Terminal colors are the most saturated dominant colors, adjusted
+  to reach the contrast set by the '--min-contrast' flag, which defaults to
+  WCAG level AA.
+
+  The image is either given by its identifier, by the path of an image file
+  saved by the 'fetch' commands, or by 'current' for the current background,
+  as the 'info' command does. When the image is given by its identifier, it is
+  read from the directory set by the '--dir' flag if it is saved there, or
+  downloaded without being saved.
+
+  The number of dominant colors is set by the '--colors' flag, and the method
+  used to find them by the '--method' flag: %s. k-means gives
+  colors closer to the image, while median cut is faster and more even.
+
+  The palette is printed in the format set by the '--format' flag, among:
+  %s.
+  Outputs can be included in the configuration of terminals, e.g. with
+  'include' in kitty or 'import' in alacritty, to follow the background.`,
+			strings.Join(libpalette.Methods, ", "),
+			strings.Join(libpalette.Formats, ", "),
+		),
+		DisableFlagsInUseLine: true,
+		SilenceUsage:          true,
+		Args: func(cmd *cobra.Command, args []string) error {
+			if err := cobra.ExactArgs(1)(cmd, args); err != nil {
+				return fmt.Errorf("missing required argument 'identifier|file|current'")
+			}
+
+			return nil
+		},
+		Run: func(_ *cobra.Command, args []string) {
+			out, err := runPaletteCmd(args[0], dir, format)
+			cobra.CheckErr(err)
+			fmt.Println(out)
+		},
+	}
+)
+
+func init() {
+	cmd.RootCmd.AddCommand(paletteCmd)
+
+	paletteCmd.Flags().StringVar(&dir, "dir", ".", "directory holding downloaded images")
+	paletteCmd.Flags().StringVarP(&format, "format", "f", "json", "output format")
+	paletteCmd.Flags().IntVar(&colors, "colors", 8, "number of dominant colors")
+	paletteCmd.Flags().StringVar(&method, "method", "kmeans", "method finding dominant colors")
+	paletteCmd.Flags().BoolVar(&light, "light", false, "derive a palette with a light background")
+	paletteCmd.Flags().
+		Float64Var(&minContrast, "min-contrast", 4.5, "minimum contrast of terminal colors")
+}
+
+// readImage decodes the given image, from its file if there is one or downloading it otherwise
+func readImage(target string, dir string) (image.Image, error) {
+	id, filePath, err := info.ResolveTarget(target, dir)
+	if err != nil {
+		return nil, err
+	}
+
+	if filePath == "" {
+		filePath = filepath.Join(dir, strconv.Itoa(id)+".jpeg")
+	}
+
+	var content []byte
+	if lib.FileExists(filePath) {
+		if content, err = os.ReadFile(filePath); err != nil {
+			return nil, err
+		}
+	} else {
+		asset := lib.Asset{Id: id}
+		if content, err = asset.GetContent(); err != nil {
+			return nil, err
+		}
+	}
+
+	img, err := jpeg.Decode(bytes.NewReader(content))
+	if err != nil {
+		return nil, fmt.Errorf("[%d] failed to decode image: %s", id, err)
+	}
+
+	return img, nil
+}
+
+func runPaletteCmd(target string, dir string, format string) (string, error) {
+	if minContrast < 1 || minContrast > 21 {
+		return "", fmt.Errorf(
+			"invalid minimum contrast: %g. Expected a ratio from 1 to 21",
+			minContrast,
+		)
+	}
+
+	if !slices.Contains(libpalette.Formats, format) {
+		return "", fmt.Errorf(
+			"invalid format: %s. Supported formats are: %s",
+			format,
+			strings.Join(libpalette.Formats, ", "),
+		)
+	}
+
+	img, err := readImage(target, dir)
+	if err != nil {
+		return "", err
+	}
+
+	dominant, err := libpalette.Dominant(img, colors, method)
+	if err != nil {
+		return "", err
+	}
+
+	return libpalette.Export(libpalette.Build(dominant, !light, minContrast), format)
+}
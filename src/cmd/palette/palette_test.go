@@ -0,0 +1,57 @@
+package palette
+
+import (
+	"bytes"
+	"encoding/json"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestRunPaletteCmd(t *testing.T) {
+	img := image.NewRGBA(image.Rect(0, 0, 90, 60))
+	for y := 0; y < 60; y++ {
+		for x := 0; x < 90; x++ {
+			img.Set(x, y, color.RGBA{R: uint8(x * 2), G: 80, B: uint8(y * 3), A: 0xff})
+		}
+	}
+
+	var content bytes.Buffer
+	if err := jpeg.Encode(&content, img, nil); err != nil {
+		t.Fatalf("Failed to encode image: %s", err)
+	}
+
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "1003.jpeg"), content.Bytes(), 0644); err != nil {
+		t.Fatalf("Failed to write image: %s", err)
+	}
+
+	// Images given by identifier are read from the directory when saved there
+	out, err := runPaletteCmd("1003", dir, "json")
+	if err != nil {
+		t.Fatalf("Expected palette to be extracted, got error: %s", err)
+	}
+
+	var palette struct {
+		Background string   `json:"background"`
+		Colors     []string `json:"colors"`
+	}
+	if err := json.Unmarshal([]byte(out), &palette); err != nil {
+		t.Fatalf("Expected JSON output, got error: %s", err)
+	}
+
+	if len(palette.Colors) != 16 || palette.Colors[0] != palette.Background {
+		t.Fatalf("Expected 16 colors starting with the background, got %v", palette.Colors)
+	}
+
+	if _, err := runPaletteCmd(filepath.Join(dir, "1003.jpeg"), dir, "kitty"); err != nil {
+		t.Fatalf("Expected palette to be extracted from file, got error: %s", err)
+	}
+
+	if _, err := runPaletteCmd("1003", dir, "yaml"); err == nil {
+		t.Fatalf("Expected error for unknown format")
+	}
+}
@@ -24,9 +24,13 @@ package cmd
 import (
 	"os"
 
+	"earth-view/lib"
+
 	"github.com/spf13/cobra"
 )
 
+var baseUrl string
+
 var RootCmd = &cobra.Command{
 	Use: "earth-view",
 	Long: `earth-view interacts with Google Earth View image assets.
@@ -38,12 +42,30 @@ Description:
   a new tab is opened.
 
   The main goal of this program is to provide a convenient way to list the
-  available images and download them on the filesystem.`,
+  available images and download them on the filesystem.
+
+  Images are fetched from gstatic.com, unless another URL serving the same
+  files is set by the '--base-url' flag or the EARTH_VIEW_BASE_URL environment
+  variable, e.g. another instance running the 'mirror serve' command.`,
 	CompletionOptions: cobra.CompletionOptions{
 		DisableDefaultCmd: true,
 	},
 }
 
+func init() {
+	defaultBaseUrl := lib.DefaultBaseUrl
+	if url := os.Getenv(lib.BaseUrlEnv); url != "" {
+		defaultBaseUrl = url
+	}
+
+	RootCmd.PersistentFlags().
+		StringVar(&baseUrl, "base-url", defaultBaseUrl, "URL to fetch images metadata from")
+
+	cobra.OnInitialize(func() {
+		lib.SetBaseUrl(baseUrl)
+	})
+}
+
 func Execute() {
 	err := RootCmd.Execute()
 	if err != nil {
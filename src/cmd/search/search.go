@@ -0,0 +1,181 @@
+/*
+Copyright © 2024 Nicolas Goudry <goudry.nicolas@gmail.com>
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package search
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+
+	"earth-view/cmd"
+	"earth-view/lib/index"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	format string
+
+	formats = []string{"table", "json", "ids", "list"}
+
+	searchCmd = &cobra.Command{
+		Use:   "search [query...]",
+		Short: "Search images",
+		Long: `Search Google Earth View images by their metadata.
+
+Description:
+  This command searches the local index of images metadata, which is filled
+  when downloading images or by the 'index build' command. No request is made to
+  gstatic.com.
+
+  The query is made of terms separated by spaces, which must all match:
+  - 'text' matches images whose country, region, continent or attribution
+    contains the text
+  - 'field:value' matches images whose field is equal to the value
+  - 'field:~value' matches images whose field contains the value
+  - 'field:>n', 'field:>=n', 'field:<n' and 'field:<=n' compare numeric fields
+  Values containing spaces can be quoted, e.g. 'country:"United States"'.
+  Matching is case insensitive.
+
+  Available fields are: attribution, continent, country, height, id, lat, lng,
+  region, size (in bytes), width and zoom. Images measured by the 'index build'
+  command also have the brightness, contrast, colorfulness and haze numeric
+  fields, and the hue text field (see 'fetch random --help').
+
+  The output format is set by the '--format' flag:
+  - 'table': one image per line with its metadata (default)
+  - 'json': JSON array of images metadata
+  - 'ids': one identifier per line, to pipe into other commands
+  - 'list': JSON array of identifiers, usable as input of 'fetch random'`,
+		DisableFlagsInUseLine: true,
+		SilenceUsage:          true,
+		Run: func(_ *cobra.Command, args []string) {
+			out, err := runSearchCmd(strings.Join(args, " "), format)
+			cobra.CheckErr(err)
+
+			if out != "" {
+				fmt.Println(out)
+			}
+		},
+	}
+)
+
+func init() {
+	cmd.RootCmd.AddCommand(searchCmd)
+
+	searchCmd.Flags().
+		StringVarP(&format, "format", "f", "table", "output format: "+strings.Join(formats, ", "))
+}
+
+func runSearchCmd(query string, format string) (string, error) {
+	q, err := index.ParseQuery(query)
+	if err != nil {
+		return "", err
+	}
+
+	idx, err := index.Load()
+	if err != nil {
+		return "", err
+	}
+
+	if idx.Len() == 0 {
+		return "", fmt.Errorf("index is empty, build it with the 'index build' command")
+	}
+
+	results := q.Search(idx)
+
+	switch format {
+	case "table":
+		return formatTable(results)
+	case "json":
+		if results == nil {
+			results = []index.Entry{}
+		}
+
+		out, err := json.MarshalIndent(results, "", "  ")
+		return string(out), err
+	case "ids":
+		lines := make([]string, len(results))
+		for i, entry := range results {
+			lines[i] = strconv.Itoa(entry.Id)
+		}
+
+		return strings.Join(lines, "\n"), nil
+	case "list":
+		ids := make([]int, len(results))
+		for i, entry := range results {
+			ids[i] = entry.Id
+		}
+
+		out, err := json.Marshal(ids)
+		return string(out), err
+	}
+
+	return "", fmt.Errorf(
+		"invalid format: %s. Expected one of %s",
+		format,
+		strings.Join(formats, ", "),
+	)
+}
+
+// formatTable formats entries as a table, one entry per line
+func formatTable(entries []index.Entry) (string, error) {
+	if len(entries) == 0 {
+		return "", nil
+	}
+
+	var out strings.Builder
+
+	writer := tabwriter.NewWriter(&out, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(writer, "ID\tCOUNTRY\tREGION\tLAT\tLNG\tZOOM\tATTRIBUTION")
+
+	for _, entry := range entries {
+		fmt.Fprintf(
+			writer,
+			"%d\t%s\t%s\t%.4f\t%.4f\t%d\t%s\n",
+			entry.Id,
+			orDash(entry.Country),
+			orDash(entry.Region),
+			entry.Lat,
+			entry.Lng,
+			entry.Zoom,
+			orDash(entry.Attribution),
+		)
+	}
+
+	if err := writer.Flush(); err != nil {
+		return "", err
+	}
+
+	return strings.TrimSuffix(out.String(), "\n"), nil
+}
+
+// orDash returns the given value, or a dash if it is empty
+func orDash(value string) string {
+	if value == "" {
+		return "-"
+	}
+
+	return value
+}
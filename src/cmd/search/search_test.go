@@ -0,0 +1,48 @@
+package search
+
+import (
+	"strings"
+	"testing"
+
+	"earth-view/lib/index"
+)
+
+func TestSearch(t *testing.T) {
+	t.Setenv("XDG_DATA_HOME", t.TempDir())
+
+	if _, err := runSearchCmd("chile", "table"); err == nil {
+		t.Fatalf("Expected error with empty index")
+	}
+
+	idx := index.New()
+	idx.Add(index.Entry{Id: 1003, Country: "Chile", Region: "Atacama"})
+	idx.Add(index.Entry{Id: 1004, Country: "Chile", Region: "Santiago"})
+	idx.Add(index.Entry{Id: 1006, Country: "Iceland"})
+	if err := idx.Save(); err != nil {
+		t.Fatalf("Expected no error while saving index, got %v", err)
+	}
+
+	for format, expected := range map[string]string{
+		"ids":  "1003\n1004",
+		"list": "[1003,1004]",
+	} {
+		out, err := runSearchCmd("country:chile", format)
+		if err != nil || out != expected {
+			t.Fatalf("Expected %q with format %s, got %q (%v)", expected, format, out, err)
+		}
+	}
+
+	out, _ := runSearchCmd("iceland", "table")
+	if !strings.Contains(out, "1006") || strings.Contains(out, "1003") {
+		t.Fatalf("Expected table to only list 1006, got %q", out)
+	}
+
+	out, _ = runSearchCmd("greenland", "json")
+	if out != "[]" {
+		t.Fatalf("Expected empty JSON array, got %q", out)
+	}
+
+	if _, err := runSearchCmd("", "yaml"); err == nil {
+		t.Fatalf("Expected error for invalid format")
+	}
+}
@@ -0,0 +1,219 @@
+/*
+Copyright © 2024 Nicolas Goudry <goudry.nicolas@gmail.com>
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package serve
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+
+	"earth-view/lib"
+	"earth-view/lib/index"
+	"earth-view/lib/mirror"
+)
+
+// Cache policies of responses
+const (
+	// The catalog grows as images are fetched, clients revalidate it with its ETag
+	revalidateCache = "no-cache"
+	// Random images must be picked again on every request
+	noCache = "no-store"
+)
+
+// server serves images of a mirror over HTTP
+type server struct {
+	mirror *mirror.Mirror
+	// ids lists the identifiers of images to choose from
+	ids []int
+	// selectId picks a random identifier among the given ones
+	selectId func(ids []int) (int, error)
+	// selectMu serializes selections, which read and write the selection state files
+	selectMu sync.Mutex
+}
+
+func newServer(m *mirror.Mirror, ids []int, selectId func(ids []int) (int, error)) *server {
+	s := &server{mirror: m, ids: slices.Clone(ids), selectId: selectId}
+	slices.Sort(s.ids)
+	m.OnFetch = indexAsset
+
+	return s
+}
+
+// indexAsset records the metadata of an asset fetched from upstream in the index
+func indexAsset(asset *lib.Asset) {
+	if err := index.Record(asset); err != nil {
+		log.Printf("failed to record image metadata in index: %s", err)
+	}
+}
+
+func (s *server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		w.Header().Set("Allow", "GET, HEAD")
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	switch path := r.URL.Path; {
+	case path == "/random.jpg":
+		s.serveRandom(w, r)
+	case path == "/catalog.json":
+		s.serveCatalog(w, r)
+	case strings.HasPrefix(path, "/images/"):
+		name := strings.TrimPrefix(path, "/images/")
+		if id, ok := strings.CutSuffix(name, ".jpg"); ok {
+			s.serveImage(w, r, id)
+		} else if id, ok := strings.CutSuffix(name, ".json"); ok {
+			s.serveMetadata(w, r, id)
+		} else {
+			http.NotFound(w, r)
+		}
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+func (s *server) serveImage(w http.ResponseWriter, r *http.Request, value string) {
+	id, ok := mirror.ParseId(value)
+	if !ok {
+		http.NotFound(w, r)
+		return
+	}
+
+	content, err := s.mirror.Image(id)
+	if err != nil {
+		mirror.ServeError(w, id, err)
+		return
+	}
+
+	mirror.ServeContent(w, r, value+".jpg", content, mirror.ImmutableCache)
+}
+
+func (s *server) serveMetadata(w http.ResponseWriter, r *http.Request, value string) {
+	id, ok := mirror.ParseId(value)
+	if !ok {
+		http.NotFound(w, r)
+		return
+	}
+
+	content, err := s.mirror.Metadata(id)
+	if err != nil {
+		mirror.ServeError(w, id, err)
+		return
+	}
+
+	mirror.ServeContent(w, r, value+".json", content, mirror.ImmutableCache)
+}
+
+// requestFilter returns the filter set by the query parameters of the request, which are named
+// after the selection flags
+func requestFilter(r *http.Request) (*index.Filter, error) {
+	query := r.URL.Query()
+
+	return index.NewFilter(index.FilterOptions{
+		Bbox:          query.Get("bbox"),
+		Continents:    query["continent"],
+		Countries:     query["country"],
+		Near:          query.Get("near"),
+		Radius:        query.Get("radius"),
+		Regions:       query["region"],
+		MinBrightness: query.Get("min-brightness"),
+		MaxBrightness: query.Get("max-brightness"),
+		MaxHaze:       query.Get("max-haze"),
+		Hues:          query["hue"],
+	})
+}
+
+func (s *server) serveRandom(w http.ResponseWriter, r *http.Request) {
+	filter, err := requestFilter(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	ids := slices.Clone(s.ids)
+	if !filter.IsEmpty() {
+		idx, err := index.Load()
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		ids = filter.Apply(idx, ids)
+	}
+
+	// Identifiers missing upstream are skipped until an image is found
+	for len(ids) > 0 {
+		s.selectMu.Lock()
+		id, err := s.selectId(ids)
+		s.selectMu.Unlock()
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusNotFound)
+			return
+		}
+
+		content, err := s.mirror.Image(id)
+		if mirror.IsNotFound(err) {
+			ids = slices.DeleteFunc(ids, func(other int) bool { return other == id })
+			continue
+		}
+		if err != nil {
+			mirror.ServeError(w, id, err)
+			return
+		}
+
+		w.Header().Set("Content-Location", fmt.Sprintf("/images/%d.jpg", id))
+		w.Header().Set("X-Earth-View-Id", strconv.Itoa(id))
+		mirror.ServeContent(w, r, "random.jpg", content, noCache)
+		return
+	}
+
+	http.Error(w, "no image matches filters", http.StatusNotFound)
+}
+
+// serveCatalog writes the indexed metadata of the images to choose from, as a JSON array
+func (s *server) serveCatalog(w http.ResponseWriter, r *http.Request) {
+	idx, err := index.Load()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	entries := []index.Entry{}
+	for _, entry := range idx.Entries() {
+		if _, found := slices.BinarySearch(s.ids, entry.Id); found {
+			entries = append(entries, entry)
+		}
+	}
+
+	content, err := json.Marshal(entries)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	mirror.ServeContent(w, r, "catalog.json", content, revalidateCache)
+}
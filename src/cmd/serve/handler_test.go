@@ -0,0 +1,250 @@
+package serve
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"earth-view/lib"
+	"earth-view/lib/index"
+	"earth-view/lib/mirror"
+)
+
+var countries = map[int]string{1003: "France", 1004: "Iceland"}
+
+// newTestServer serves a mirror in a temporary directory, picking the first identifier randomly
+func newTestServer(t *testing.T) *httptest.Server {
+	return newTestServerWith(t, func(ids []int) (int, error) { return ids[0], nil })
+}
+
+// newTestServerWith serves a mirror in a temporary directory, picking identifiers with selectId
+func newTestServerWith(t *testing.T, selectId func(ids []int) (int, error)) *httptest.Server {
+	t.Setenv("XDG_DATA_HOME", t.TempDir())
+	t.Setenv("XDG_STATE_HOME", t.TempDir())
+
+	m := mirror.New(t.TempDir())
+	m.Fetch = func(id int) (*lib.Asset, error) {
+		if id == 1005 {
+			return nil, fmt.Errorf("[%d] fetch failed: received HTTP 500", id)
+		}
+
+		country, ok := countries[id]
+		if !ok {
+			return nil, fmt.Errorf("[%d] fetch failed: %w", id, lib.ErrNotFound)
+		}
+
+		return &lib.Asset{
+			Id: id,
+			Metadata: map[string]interface{}{
+				"country": country,
+				"dataUri": "data:image/jpeg;base64,",
+			},
+			Content: []byte(fmt.Sprintf("image %d", id)),
+		}, nil
+	}
+
+	ts := httptest.NewServer(newServer(m, []int{1004, 999, 1003}, selectId))
+	t.Cleanup(ts.Close)
+
+	return ts
+}
+
+func get(t *testing.T, url string, header http.Header) (*http.Response, string) {
+	req, err := http.NewRequest(http.MethodGet, url, nil)
+	if err != nil {
+		t.Fatalf("Failed to create request: %s", err)
+	}
+	for key, values := range header {
+		req.Header[key] = values
+	}
+
+	res, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("Expected response, got error: %s", err)
+	}
+	defer res.Body.Close()
+
+	body, err := io.ReadAll(res.Body)
+	if err != nil {
+		t.Fatalf("Failed to read response body: %s", err)
+	}
+
+	return res, string(body)
+}
+
+func TestImage(t *testing.T) {
+	ts := newTestServer(t)
+
+	res, body := get(t, ts.URL+"/images/1003.jpg", nil)
+	if res.StatusCode != http.StatusOK || body != "image 1003" {
+		t.Fatalf("Expected image, got HTTP %d: %s", res.StatusCode, body)
+	}
+
+	if contentType := res.Header.Get("Content-Type"); contentType != "image/jpeg" {
+		t.Fatalf("Expected JPEG content type, got %s", contentType)
+	}
+	if !strings.Contains(res.Header.Get("Cache-Control"), "immutable") {
+		t.Fatalf("Expected image to be cacheable, got %s", res.Header.Get("Cache-Control"))
+	}
+
+	etag := res.Header.Get("ETag")
+	if etag == "" {
+		t.Fatalf("Expected ETag header")
+	}
+
+	res, _ = get(t, ts.URL+"/images/1003.jpg", http.Header{"If-None-Match": {etag}})
+	if res.StatusCode != http.StatusNotModified {
+		t.Fatalf("Expected HTTP 304 for matching ETag, got HTTP %d", res.StatusCode)
+	}
+}
+
+func TestMetadata(t *testing.T) {
+	ts := newTestServer(t)
+
+	res, body := get(t, ts.URL+"/images/1004.json", nil)
+	if res.StatusCode != http.StatusOK {
+		t.Fatalf("Expected metadata, got HTTP %d: %s", res.StatusCode, body)
+	}
+
+	var metadata map[string]interface{}
+	if err := json.Unmarshal([]byte(body), &metadata); err != nil {
+		t.Fatalf("Expected JSON metadata, got error: %s", err)
+	}
+
+	if _, ok := metadata["dataUri"]; ok || metadata["country"] != "Iceland" {
+		t.Fatalf("Expected metadata without encoded image, got %v", metadata)
+	}
+}
+
+func TestRandom(t *testing.T) {
+	ts := newTestServer(t)
+
+	res, body := get(t, ts.URL+"/random.jpg", nil)
+	if res.StatusCode != http.StatusOK || body != "image 1003" {
+		t.Fatalf("Expected first existing image, got HTTP %d: %s", res.StatusCode, body)
+	}
+
+	if id := res.Header.Get("X-Earth-View-Id"); id != "1003" {
+		t.Fatalf("Expected identifier header to be 1003, got %s", id)
+	}
+	if cacheControl := res.Header.Get("Cache-Control"); cacheControl != noCache {
+		t.Fatalf("Expected random image not to be cached, got %s", cacheControl)
+	}
+
+	// Location filters rely on the index, filled when images are fetched
+	res, _ = get(t, ts.URL+"/random.jpg?country=iceland", nil)
+	if res.StatusCode != http.StatusNotFound {
+		t.Fatalf("Expected HTTP 404 for image missing from index, got HTTP %d", res.StatusCode)
+	}
+
+	get(t, ts.URL+"/images/1004.jpg", nil)
+
+	res, body = get(t, ts.URL+"/random.jpg?country=France&country=Iceland", nil)
+	if res.StatusCode != http.StatusOK || body != "image 1003" {
+		t.Fatalf("Expected image matching filters, got HTTP %d: %s", res.StatusCode, body)
+	}
+
+	// Images are only measured when building the index
+	res, _ = get(t, ts.URL+"/random.jpg?country=France&max-haze=1", nil)
+	if res.StatusCode != http.StatusNotFound {
+		t.Fatalf("Expected HTTP 404 for image without statistics, got HTTP %d", res.StatusCode)
+	}
+
+	for _, query := range []string{"radius=10km", "hue=magenta", "min-brightness=2"} {
+		res, _ = get(t, ts.URL+"/random.jpg?"+query, nil)
+		if res.StatusCode != http.StatusBadRequest {
+			t.Fatalf("Expected HTTP 400 for invalid filters, got HTTP %d", res.StatusCode)
+		}
+	}
+}
+
+func TestRandomConcurrent(t *testing.T) {
+	var running, overlaps atomic.Int32
+	ts := newTestServerWith(t, func(ids []int) (int, error) {
+		if running.Add(1) > 1 {
+			overlaps.Add(1)
+		}
+		defer running.Add(-1)
+
+		time.Sleep(5 * time.Millisecond)
+		return ids[len(ids)-1], nil
+	})
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if res, err := http.Get(ts.URL + "/random.jpg"); err == nil {
+				res.Body.Close()
+			}
+		}()
+	}
+	wg.Wait()
+
+	if overlaps.Load() > 0 {
+		t.Fatalf(
+			"Expected random selections not to run concurrently, got %d overlaps",
+			overlaps.Load(),
+		)
+	}
+}
+
+func TestCatalog(t *testing.T) {
+	ts := newTestServer(t)
+
+	get(t, ts.URL+"/images/1003.jpg", nil)
+
+	res, body := get(t, ts.URL+"/catalog.json", nil)
+	if res.StatusCode != http.StatusOK {
+		t.Fatalf("Expected catalog, got HTTP %d: %s", res.StatusCode, body)
+	}
+
+	var entries []index.Entry
+	if err := json.Unmarshal([]byte(body), &entries); err != nil {
+		t.Fatalf("Expected JSON catalog, got error: %s", err)
+	}
+
+	if len(entries) != 1 || entries[0].Id != 1003 || entries[0].Country != "France" {
+		t.Fatalf("Expected catalog to hold fetched image, got %v", entries)
+	}
+
+	res, _ = get(t, ts.URL+"/catalog.json", http.Header{"If-None-Match": {res.Header.Get("ETag")}})
+	if res.StatusCode != http.StatusNotModified {
+		t.Fatalf("Expected HTTP 304 for unchanged catalog, got HTTP %d", res.StatusCode)
+	}
+}
+
+func TestErrors(t *testing.T) {
+	ts := newTestServer(t)
+
+	for path, status := range map[string]int{
+		"/images/999.jpg":   http.StatusNotFound,
+		"/images/1005.jpg":  http.StatusBadGateway,
+		"/images/abc.jpg":   http.StatusNotFound,
+		"/images/01003.jpg": http.StatusNotFound,
+		"/images/1003.png":  http.StatusNotFound,
+		"/unknown":          http.StatusNotFound,
+	} {
+		if res, _ := get(t, ts.URL+path, nil); res.StatusCode != status {
+			t.Fatalf("Expected HTTP %d for %s, got HTTP %d", status, path, res.StatusCode)
+		}
+	}
+
+	res, err := http.Post(ts.URL+"/random.jpg", "text/plain", nil)
+	if err != nil {
+		t.Fatalf("Expected response, got error: %s", err)
+	}
+	res.Body.Close()
+
+	if res.StatusCode != http.StatusMethodNotAllowed {
+		t.Fatalf("Expected HTTP 405 for POST request, got HTTP %d", res.StatusCode)
+	}
+}
@@ -0,0 +1,137 @@
+/*
+Copyright © 2024 Nicolas Goudry <goudry.nicolas@gmail.com>
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package serve
+
+import (
+	"context"
+	"errors"
+	"log"
+	"net/http"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"syscall"
+	"time"
+
+	"earth-view/cmd"
+	"earth-view/cmd/fetch"
+	"earth-view/lib/mirror"
+
+	"github.com/spf13/cobra"
+)
+
+const shutdownTimeout = 10 * time.Second
+
+var (
+	addr  string
+	dir   string
+	input string
+
+	serveCmd = &cobra.Command{
+		Use:   "serve",
+		Short: "Serve images over HTTP",
+		Long: `Serve Google Earth View images over HTTP from a local mirror.
+
+Description:
+  This command starts an HTTP server listening on the address set by the
+  '--addr' flag, which serves images saved in the directory set by the '--dir'
+  flag. Images missing from the directory are fetched from gstatic.com on the
+  first request, and saved for later requests. This lets several machines share
+  one cache of images.
+
+  Endpoints:
+    /random.jpg        a random image
+    /images/{id}.jpg   the image with given identifier
+    /images/{id}.json  the metadata of the image, without the encoded image
+    /catalog.json      the indexed metadata of the images to choose from
+
+  Random images are chosen among the identifiers of the input file set by the
+  '--input' flag, expected to hold the output of the 'list' command, or among
+  all the known possible identifiers. The identifier of the chosen image is
+  sent in the 'X-Earth-View-Id' response header.
+
+  The 'country', 'region', 'continent', 'bbox', 'near', 'radius',
+  'min-brightness', 'max-brightness', 'max-haze' and 'hue' query parameters of
+  '/random.jpg' restrict the selection like the flags of the same name, e.g.
+  '/random.jpg?continent=Europe&hue=blue'. They are combined with the selection
+  flags set when starting the server, which are described in the help of the
+  'fetch random' command.
+
+  Responses carry an ETag, and images and metadata may be cached by clients
+  as they never change.`,
+		DisableFlagsInUseLine: true,
+		SilenceUsage:          true,
+		Args:                  cobra.MaximumNArgs(0),
+		Run: func(_ *cobra.Command, _ []string) {
+			cobra.CheckErr(runServeCmd(addr, dir, input))
+		},
+	}
+)
+
+func init() {
+	cmd.RootCmd.AddCommand(serveCmd)
+
+	serveCmd.Flags().StringVar(&addr, "addr", ":8080", "address to listen on")
+	serveCmd.Flags().StringVar(&dir, "dir", ".", "directory holding mirrored images")
+	serveCmd.Flags().StringVarP(&input, "input", "i", "", "input file to choose images from")
+	fetch.AddSelectionFlags(serveCmd.Flags())
+}
+
+func runServeCmd(addr string, dir string, input string) error {
+	ids, err := fetch.ReadInputIds(input)
+	if err != nil {
+		return err
+	}
+
+	absDir, err := filepath.Abs(dir)
+	if err != nil {
+		return err
+	}
+
+	srv := &http.Server{
+		Addr:              addr,
+		Handler:           newServer(mirror.New(absDir), ids, fetch.SelectId),
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	go func() {
+		<-ctx.Done()
+
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+		defer cancel()
+
+		if err := srv.Shutdown(shutdownCtx); err != nil {
+			log.Printf("failed to shut down server: %s", err)
+		}
+	}()
+
+	log.Printf("serving %s on %s", absDir, addr)
+
+	if err := srv.ListenAndServe(); !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+
+	return nil
+}
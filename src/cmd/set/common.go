@@ -0,0 +1,120 @@
+/*
+Copyright © 2024 Nicolas Goudry <goudry.nicolas@gmail.com>
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package set
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"earth-view/lib/wallpaper"
+
+	"github.com/spf13/pflag"
+)
+
+// BackendHelp describes how the backend setting the background is selected
+var BackendHelp = fmt.Sprintf(
+	`  The background is set with the program matching the desktop environment,
+  detected from the XDG_CURRENT_DESKTOP, HYPRLAND_INSTANCE_SIGNATURE and
+  SWAYSOCK environment variables:
+
+  - gnome: gsettings
+  - kde: plasma-apply-wallpaperimage
+  - hyprpaper: hyprpaper, through its IPC socket
+  - swaybg: swaybg, which keeps running until the next background is set
+  - feh: feh, used when no other backend is detected
+
+  The swww backend is never detected and must be selected explicitly. It starts
+  swww-daemon if needed and animates background changes with the transition
+  set by the '--transition-*' flags.
+
+  Detection can be overridden with the '--backend' flag. Supported backends
+  are: %s.`,
+	strings.Join(wallpaper.Names(), ", "),
+)
+
+// BackendFlags holds the flags configuring how the background is set
+// It allows other commands setting the background to share the same flags
+type BackendFlags struct {
+	backend            string
+	display            string
+	noXinerama         bool
+	transitionDuration time.Duration
+	transitionFps      int
+	transitionType     string
+}
+
+// AddFlags registers the backend flags in the given flag set
+func (b *BackendFlags) AddFlags(f *pflag.FlagSet) {
+	f.StringVarP(&b.backend, "backend", "b", "auto", "program used to set the background")
+	f.StringVarP(
+		&b.display,
+		"display",
+		"d",
+		"fill",
+		"display mode, one of: "+strings.Join(wallpaper.Displays, ", "),
+	)
+	f.BoolVar(&b.noXinerama, "no-xinerama", false, "stretch a single image across all screens")
+	f.DurationVar(
+		&b.transitionDuration,
+		"transition-duration",
+		0,
+		"duration of the transition between backgrounds",
+	)
+	f.IntVar(
+		&b.transitionFps,
+		"transition-fps",
+		0,
+		"frame rate of the transition between backgrounds",
+	)
+	f.StringVar(
+		&b.transitionType,
+		"transition-type",
+		"",
+		"type of the transition between backgrounds",
+	)
+}
+
+// Options returns the backend options matching the flags
+func (b *BackendFlags) Options() (wallpaper.Options, error) {
+	opts := wallpaper.Options{
+		Display:  b.display,
+		Xinerama: !b.noXinerama,
+		Transition: wallpaper.Transition{
+			Type:     b.transitionType,
+			Duration: b.transitionDuration,
+			Fps:      b.transitionFps,
+		},
+	}
+
+	return opts, wallpaper.ValidateOptions(opts)
+}
+
+// Backend creates the backend selected by the flags, or detects it from the environment
+func (b *BackendFlags) Backend(runner wallpaper.Runner) (wallpaper.Backend, error) {
+	if b.backend == "auto" {
+		return wallpaper.Detect(os.Getenv, runner), nil
+	}
+
+	return wallpaper.New(b.backend, runner)
+}
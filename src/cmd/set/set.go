@@ -0,0 +1,143 @@
+/*
+Copyright © 2024 Nicolas Goudry <goudry.nicolas@gmail.com>
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package set
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"earth-view/cmd"
+	"earth-view/cmd/fetch"
+	"earth-view/lib/wallpaper"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	backendFlags BackendFlags
+	input        string
+	output       string
+	overwrite    bool
+	pair         bool
+
+	setCmd = &cobra.Command{
+		Use:   "set file|identifier|random",
+		Short: "Set desktop background",
+		Long: fmt.Sprintf(`Set the desktop background to a Google Earth View image.
+
+Description:
+  This command sets the desktop background to either an image file, the image
+  with the given identifier or a random image. Images which are not yet on the
+  filesystem are downloaded first, the same way as the 'fetch' and
+  'fetch random' commands do. The path of the image is then printed to the
+  standard output.
+
+  When 'random' is given, the '--input' flag can be used to provide a file
+  containing the output of the 'list' command. The '--pair' flag can also be
+  provided to pick a bright image for light mode and a dark image for dark mode,
+  as the 'fetch random --pair' command does. Both paths are then printed, light
+  image first. Only the gnome backend supports a separate dark mode image, other
+  backends use the light image.
+
+  Downloaded images are saved in the current working directory by default. This
+  behaviour can be changed by using the '--output' flag. Existing images are not
+  overwritten, unless the '--overwrite' flag is set.
+
+  Each background set is recorded in the history log, which can be browsed with
+  the 'history', 'prev' and 'next' commands.
+
+Backends:
+%s`, BackendHelp),
+		DisableFlagsInUseLine: true,
+		SilenceUsage:          true,
+		Args: func(cmd *cobra.Command, args []string) error {
+			if err := cobra.ExactArgs(1)(cmd, args); err != nil {
+				return fmt.Errorf("expected exactly one of 'file', 'identifier' or 'random'")
+			}
+
+			return nil
+		},
+		Run: func(_ *cobra.Command, args []string) {
+			filePaths, err := runSetCmd(args[0], wallpaper.ExecRunner{})
+			cobra.CheckErr(err)
+			fmt.Println(strings.Join(filePaths, "\n"))
+		},
+	}
+)
+
+func init() {
+	cmd.RootCmd.AddCommand(setCmd)
+
+	backendFlags.AddFlags(setCmd.Flags())
+	fetch.AddSelectionFlags(setCmd.Flags())
+	setCmd.Flags().StringVarP(&input, "input", "i", "", "input file to choose a random image from")
+	setCmd.Flags().
+		StringVarP(&output, "output", "o", "", "write downloaded image to given file or directory")
+	setCmd.Flags().
+		BoolVar(&overwrite, "overwrite", false, "overwrite downloaded image if it exists")
+	setCmd.Flags().BoolVar(&pair, "pair", false, "pick a light and a dark random image")
+}
+
+func runSetCmd(source string, runner wallpaper.Runner) ([]string, error) {
+	opts, err := backendFlags.Options()
+	if err != nil {
+		return nil, err
+	}
+
+	if pair && source != "random" {
+		return nil, fmt.Errorf("--pair can only be provided along with 'random'")
+	}
+
+	backend, err := backendFlags.Backend(runner)
+	if err != nil {
+		return nil, err
+	}
+
+	var filePaths []string
+	if pair {
+		filePaths, err = fetch.FetchRandomPair(input, output, overwrite)
+		if err != nil {
+			return nil, err
+		}
+
+		opts.DarkFile = filePaths[1]
+	} else {
+		filePath, err := fetch.Resolve(source, input, output, overwrite)
+		if err != nil {
+			return nil, err
+		}
+
+		filePaths = []string{filePath}
+	}
+
+	// The background is set when it is not tracked, which is not fatal
+	err = wallpaper.Show(backend, filePaths[0], opts, true, "")
+	if errors.Is(err, wallpaper.ErrNotTracked) {
+		fmt.Fprintf(os.Stderr, "%s\n", err)
+	} else if err != nil {
+		return nil, err
+	}
+
+	return filePaths, nil
+}
@@ -0,0 +1,159 @@
+/*
+Copyright © 2024 Nicolas Goudry <goudry.nicolas@gmail.com>
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package thumbs
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"image/jpeg"
+	"os"
+	"runtime"
+	"sync"
+
+	"earth-view/cmd/fetch"
+	"earth-view/lib"
+	"earth-view/lib/index"
+	"earth-view/lib/mirror"
+
+	"github.com/spf13/pflag"
+)
+
+var (
+	dir     string
+	input   string
+	kernel  string
+	quality int
+
+	sourceHelp = `  Images are read from the directory set by the '--dir' flag, defaulting to the
+  current working directory. All its images are used, unless the '--input'
+  flag is set to a file holding the output of the 'list' command, in which case
+  the listed images are used and the missing ones are fetched into the
+  directory.`
+)
+
+func addCommonFlags(f *pflag.FlagSet) {
+	f.StringVar(&dir, "dir", ".", "directory holding downloaded images")
+	f.StringVarP(&input, "input", "i", "", "input file listing images to use")
+	f.StringVar(&kernel, "kernel", "catmullrom", "resampling method")
+	f.IntVar(&quality, "quality", 90, "JPEG quality, from 1 to 100")
+}
+
+// newMirror returns the mirror of the image directory, recording fetched images in the index
+func newMirror(dir string) *mirror.Mirror {
+	m := mirror.New(dir)
+	m.OnFetch = func(asset *lib.Asset) {
+		if err := index.Record(asset); err != nil {
+			fmt.Fprintf(os.Stderr, "failed to record image metadata in index: %s\n", err)
+		}
+	}
+
+	return m
+}
+
+// resolveIds returns the identifiers of the images listed in the input file, or of the images of
+// the mirror if no input file is provided
+func resolveIds(m *mirror.Mirror, input string) ([]int, error) {
+	ids, err := m.Ids()
+	if input != "" {
+		ids, err = fetch.ReadInputIds(input)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if len(ids) == 0 {
+		return nil, fmt.Errorf("no image found in %s", m.Dir)
+	}
+
+	return ids, nil
+}
+
+// readImage decodes an image of the mirror, fetching it if it is missing
+func readImage(m *mirror.Mirror, id int) (image.Image, error) {
+	content, err := m.Image(id)
+	if err != nil {
+		return nil, err
+	}
+
+	img, err := jpeg.Decode(bytes.NewReader(content))
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode image %d: %s", id, err)
+	}
+
+	return img, nil
+}
+
+// encodeJpeg encodes an image to JPEG with the quality set by flags
+func encodeJpeg(img image.Image) ([]byte, error) {
+	if quality < 1 || quality > 100 {
+		return nil, fmt.Errorf("invalid JPEG quality: %d. Expected a number from 1 to 100", quality)
+	}
+
+	var out bytes.Buffer
+	if err := jpeg.Encode(&out, img, &jpeg.Options{Quality: quality}); err != nil {
+		return nil, err
+	}
+
+	return out.Bytes(), nil
+}
+
+// processAll calls the function for each identifier, using all processors
+// Images missing upstream are skipped with a warning, and the first other error is returned
+func processAll(ids []int, process func(i int, id int) error) error {
+	jobs := make(chan int)
+	errs := make(chan error, len(ids))
+
+	var wg sync.WaitGroup
+	for w := 0; w < runtime.NumCPU(); w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			for i := range jobs {
+				err := process(i, ids[i])
+				if mirror.IsNotFound(err) {
+					fmt.Fprintf(os.Stderr, "skipping image %d: %s\n", ids[i], err)
+					continue
+				}
+
+				errs <- err
+			}
+		}()
+	}
+
+	for i := range ids {
+		jobs <- i
+	}
+	close(jobs)
+
+	wg.Wait()
+	close(errs)
+
+	for err := range errs {
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,278 @@
+/*
+Copyright © 2024 Nicolas Goudry <goudry.nicolas@gmail.com>
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package thumbs
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"earth-view/cmd"
+	"earth-view/lib"
+	"earth-view/lib/imaging"
+	"earth-view/lib/index"
+
+	"github.com/spf13/cobra"
+	"golang.org/x/image/draw"
+	"golang.org/x/image/font"
+)
+
+const (
+	// maxJpegSize is the maximum width and height of JPEG images
+	maxJpegSize = 65535
+	sheetGap    = 12
+	captionSize = 14
+)
+
+// Colors of contact sheets, from the Catppuccin Mocha palette
+var (
+	sheetBackground = color.RGBA{R: 0x1e, G: 0x1e, B: 0x2e, A: 0xff}
+	captionColor    = color.RGBA{R: 0xcd, G: 0xd6, B: 0xf4, A: 0xff}
+	mutedColor      = color.RGBA{R: 0xa6, G: 0xad, B: 0xc8, A: 0xff}
+)
+
+var (
+	columns    int
+	noCaptions bool
+	sheetPath  string
+	tile       string
+
+	contactSheetCmd = &cobra.Command{
+		Use:   "contact-sheet",
+		Short: "Compose a mosaic of images",
+		Long: fmt.Sprintf(`Compose a contact sheet showing Google Earth View images in a grid.
+
+Description:
+  This command composes a JPEG mosaic of images, each one scaled and cropped to
+  the size set by the '--tile' flag, in as many rows of the number of columns
+  set by the '--columns' flag as needed. The mosaic is saved to the file set by
+  the '--output' flag, whose path is printed to the standard output.
+
+  Each image is captioned with its identifier and location, as found in the
+  local index, unless the '--no-captions' flag is set.
+
+%s
+
+  The '--kernel' flag sets the resampling method, among %s, from
+  the fastest to the sharpest.`, sourceHelp, strings.Join(imaging.InterpolatorNames, ", ")),
+		DisableFlagsInUseLine: true,
+		SilenceUsage:          true,
+		Args:                  cobra.MaximumNArgs(0),
+		Run: func(_ *cobra.Command, _ []string) {
+			filePath, err := runContactSheetCmd(dir, input, sheetPath, tile, columns, !noCaptions)
+			cobra.CheckErr(err)
+			fmt.Println(filePath)
+		},
+	}
+)
+
+func init() {
+	cmd.RootCmd.AddCommand(contactSheetCmd)
+
+	addCommonFlags(contactSheetCmd.Flags())
+	contactSheetCmd.Flags().
+		StringVarP(&sheetPath, "output", "o", "contact-sheet.jpeg", "file to save the mosaic to")
+	contactSheetCmd.Flags().IntVar(&columns, "columns", 6, "number of images per row")
+	contactSheetCmd.Flags().StringVar(&tile, "tile", "300x200", "size of images as WxH")
+	contactSheetCmd.Flags().BoolVar(&noCaptions, "no-captions", false, "do not caption images")
+}
+
+// sheetLayout holds the dimensions of a contact sheet
+type sheetLayout struct {
+	columns       int
+	tileWidth     int
+	tileHeight    int
+	captionHeight int
+}
+
+// size returns the size of a contact sheet holding the given number of images
+func (l sheetLayout) size(count int) (int, int) {
+	rows := (count + l.columns - 1) / l.columns
+
+	return l.columns*(l.tileWidth+sheetGap) + sheetGap,
+		rows*(l.tileHeight+l.captionHeight+sheetGap) + sheetGap
+}
+
+// tileRect returns the area of the image at the given position in the contact sheet
+func (l sheetLayout) tileRect(i int) image.Rectangle {
+	x := sheetGap + i%l.columns*(l.tileWidth+sheetGap)
+	y := sheetGap + i/l.columns*(l.tileHeight+l.captionHeight+sheetGap)
+
+	return image.Rect(x, y, x+l.tileWidth, y+l.tileHeight)
+}
+
+// composeSheet draws the tiles in a grid, with their captions if a font face is given
+func composeSheet(
+	tiles []image.Image,
+	captions [][2]string,
+	layout sheetLayout,
+	face *captionFace,
+) *image.RGBA {
+	width, height := layout.size(len(tiles))
+
+	sheet := image.NewRGBA(image.Rect(0, 0, width, height))
+	draw.Draw(sheet, sheet.Bounds(), image.NewUniform(sheetBackground), image.Point{}, draw.Src)
+
+	for i, tile := range tiles {
+		rect := layout.tileRect(i)
+		draw.Draw(sheet, rect, tile, tile.Bounds().Min, draw.Src)
+
+		if face == nil {
+			continue
+		}
+
+		baseline := rect.Max.Y + face.lineHeight
+		for j, text := range captions[i] {
+			c := captionColor
+			if j > 0 {
+				c = mutedColor
+			}
+
+			text = imaging.Ellipsize(face.face, text, layout.tileWidth)
+			imaging.DrawText(sheet, face.face, rect.Min.X, baseline, text, c)
+			baseline += face.lineHeight
+		}
+	}
+
+	return sheet
+}
+
+func runContactSheetCmd(
+	dir string,
+	input string,
+	sheetPath string,
+	tile string,
+	columns int,
+	captions bool,
+) (string, error) {
+	if columns <= 0 {
+		return "", fmt.Errorf("invalid number of columns: %d. Expected a positive number", columns)
+	}
+
+	interpolator, err := imaging.ParseInterpolator(kernel)
+	if err != nil {
+		return "", err
+	}
+
+	tileWidth, tileHeight, err := imaging.ParseSize(tile)
+	if err != nil {
+		return "", err
+	}
+
+	layout := sheetLayout{columns: columns, tileWidth: tileWidth, tileHeight: tileHeight}
+
+	var face *captionFace
+	if captions {
+		if face, err = newCaptionFace(); err != nil {
+			return "", err
+		}
+
+		layout.captionHeight = face.lineHeight*2 + face.lineHeight/2
+	}
+
+	absDir, err := filepath.Abs(dir)
+	if err != nil {
+		return "", err
+	}
+
+	m := newMirror(absDir)
+	ids, err := resolveIds(m, input)
+	if err != nil {
+		return "", err
+	}
+
+	if width, height := layout.size(len(ids)); width > maxJpegSize || height > maxJpegSize {
+		return "", fmt.Errorf(
+			"contact sheet of %d images would be too large (%dx%d), use more columns or fewer images",
+			len(ids),
+			width,
+			height,
+		)
+	}
+
+	tiles := make([]image.Image, len(ids))
+	err = processAll(ids, func(i int, id int) error {
+		img, err := readImage(m, id)
+		if err != nil {
+			return err
+		}
+
+		tiles[i] = imaging.Fill(img, tileWidth, tileHeight, interpolator)
+
+		return nil
+	})
+	if err != nil {
+		return "", err
+	}
+
+	// Images missing upstream are left out
+	idx, err := index.Load()
+	if err != nil {
+		return "", err
+	}
+
+	var shown []image.Image
+	var texts [][2]string
+	for i, tile := range tiles {
+		if tile == nil {
+			continue
+		}
+
+		entry, _ := idx.Get(ids[i])
+		shown = append(shown, tile)
+		texts = append(texts, [2]string{strconv.Itoa(ids[i]), entry.Location()})
+	}
+
+	if len(shown) == 0 {
+		return "", fmt.Errorf("no image to compose")
+	}
+
+	content, err := encodeJpeg(composeSheet(shown, texts, layout, face))
+	if err != nil {
+		return "", err
+	}
+
+	absPath, err := lib.ResolveAbsFilePath(sheetPath, "contact-sheet.jpeg")
+	if err != nil {
+		return "", err
+	}
+
+	return absPath, lib.WriteFile(content, absPath)
+}
+
+// captionFace is the font face of captions, along with the height of their lines
+type captionFace struct {
+	face       font.Face
+	lineHeight int
+}
+
+func newCaptionFace() (*captionFace, error) {
+	face, err := imaging.NewFace(captionSize)
+	if err != nil {
+		return nil, err
+	}
+
+	return &captionFace{face: face, lineHeight: face.Metrics().Height.Ceil()}, nil
+}
@@ -0,0 +1,188 @@
+/*
+Copyright © 2024 Nicolas Goudry <goudry.nicolas@gmail.com>
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package thumbs
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+
+	"earth-view/cmd"
+	"earth-view/lib"
+	"earth-view/lib/imaging"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	output    string
+	overwrite bool
+	quiet     bool
+	sizes     []string
+
+	thumbsCmd = &cobra.Command{
+		Use:   "thumbs",
+		Short: "Generate thumbnails of images",
+		Long: fmt.Sprintf(`Generate thumbnails of Google Earth View images.
+
+Description:
+  This command scales images down to the sizes set by the '--size' flag, which
+  can be repeated, keeping their aspect ratio. Thumbnails are saved as JPEG in
+  a subdirectory per size of the directory set by the '--output' flag, e.g.
+  'thumbs/480x320/1003.jpeg', and their paths are printed to the standard
+  output unless the '--quiet' flag is set.
+
+%s
+
+  Existing thumbnails are not generated again, unless the '--overwrite' flag
+  is set.
+
+  The '--kernel' flag sets the resampling method, among %s, from
+  the fastest to the sharpest.`, sourceHelp, strings.Join(imaging.InterpolatorNames, ", ")),
+		DisableFlagsInUseLine: true,
+		SilenceUsage:          true,
+		Args:                  cobra.MaximumNArgs(0),
+		Run: func(_ *cobra.Command, _ []string) {
+			filePaths, err := runThumbsCmd(dir, input, output, sizes, overwrite)
+			cobra.CheckErr(err)
+
+			if !quiet && len(filePaths) > 0 {
+				fmt.Println(strings.Join(filePaths, "\n"))
+			}
+		},
+	}
+)
+
+func init() {
+	cmd.RootCmd.AddCommand(thumbsCmd)
+
+	addCommonFlags(thumbsCmd.Flags())
+	thumbsCmd.Flags().
+		StringVarP(&output, "output", "o", "thumbs", "directory to save thumbnails to")
+	thumbsCmd.Flags().
+		StringArrayVar(&sizes, "size", []string{"480x320"}, "maximum size of thumbnails as WxH")
+	thumbsCmd.Flags().BoolVar(&overwrite, "overwrite", false, "overwrite existing thumbnails")
+	thumbsCmd.Flags().BoolVarP(&quiet, "quiet", "q", false, "do not print thumbnail paths")
+}
+
+// thumbSize is a size of thumbnails, along with the directory they are saved to
+type thumbSize struct {
+	width  int
+	height int
+	dir    string
+}
+
+func runThumbsCmd(
+	dir string,
+	input string,
+	output string,
+	sizes []string,
+	overwrite bool,
+) ([]string, error) {
+	interpolator, err := imaging.ParseInterpolator(kernel)
+	if err != nil {
+		return nil, err
+	}
+
+	var thumbSizes []thumbSize
+	for _, size := range sizes {
+		width, height, err := imaging.ParseSize(size)
+		if err != nil {
+			return nil, err
+		}
+
+		sizeDir := filepath.Join(output, fmt.Sprintf("%dx%d", width, height))
+		if err := os.MkdirAll(sizeDir, 0755); err != nil {
+			return nil, err
+		}
+
+		thumbSizes = append(thumbSizes, thumbSize{width, height, sizeDir})
+	}
+
+	absDir, err := filepath.Abs(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	m := newMirror(absDir)
+	ids, err := resolveIds(m, input)
+	if err != nil {
+		return nil, err
+	}
+
+	var mu sync.Mutex
+	var filePaths []string
+
+	err = processAll(ids, func(_ int, id int) error {
+		var missing []string
+		for _, size := range thumbSizes {
+			filePath := filepath.Join(size.dir, strconv.Itoa(id)+".jpeg")
+			if overwrite || !lib.FileExists(filePath) {
+				missing = append(missing, filePath)
+			}
+		}
+
+		if len(missing) == 0 {
+			return nil
+		}
+
+		img, err := readImage(m, id)
+		if err != nil {
+			return err
+		}
+
+		for _, size := range thumbSizes {
+			filePath := filepath.Join(size.dir, strconv.Itoa(id)+".jpeg")
+			if !slices.Contains(missing, filePath) {
+				continue
+			}
+
+			content, err := encodeJpeg(imaging.Fit(img, size.width, size.height, interpolator))
+			if err != nil {
+				return err
+			}
+
+			if err := lib.WriteFile(content, filePath); err != nil {
+				return err
+			}
+
+			absPath, err := filepath.Abs(filePath)
+			if err != nil {
+				return err
+			}
+
+			mu.Lock()
+			filePaths = append(filePaths, absPath)
+			mu.Unlock()
+		}
+
+		return nil
+	})
+
+	slices.Sort(filePaths)
+
+	return filePaths, err
+}
@@ -0,0 +1,140 @@
+package thumbs
+
+import (
+	"bytes"
+	"image"
+	"image/jpeg"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"earth-view/lib/index"
+)
+
+func writeImages(t *testing.T, dir string, ids ...string) {
+	t.Helper()
+
+	var content bytes.Buffer
+	if err := jpeg.Encode(&content, image.NewRGBA(image.Rect(0, 0, 60, 40)), nil); err != nil {
+		t.Fatalf("Failed to encode image: %s", err)
+	}
+
+	for _, id := range ids {
+		if err := os.WriteFile(filepath.Join(dir, id+".jpeg"), content.Bytes(), 0644); err != nil {
+			t.Fatalf("Failed to write image %s: %s", id, err)
+		}
+	}
+}
+
+func decodeSize(t *testing.T, filePath string) (int, int) {
+	t.Helper()
+
+	file, err := os.Open(filePath)
+	if err != nil {
+		t.Fatalf("Expected %s to be written, got error: %s", filePath, err)
+	}
+	defer file.Close()
+
+	config, err := jpeg.DecodeConfig(file)
+	if err != nil {
+		t.Fatalf("Expected %s to be a JPEG image, got error: %s", filePath, err)
+	}
+
+	return config.Width, config.Height
+}
+
+func TestRunThumbsCmd(t *testing.T) {
+	t.Setenv("XDG_DATA_HOME", t.TempDir())
+
+	dir := t.TempDir()
+	writeImages(t, dir, "1003", "1004")
+
+	output := t.TempDir()
+	filePaths, err := runThumbsCmd(dir, "", output, []string{"30x20", "16x16"}, false)
+	if err != nil {
+		t.Fatalf("Expected thumbnails to be generated, got error: %s", err)
+	}
+
+	if len(filePaths) != 4 {
+		t.Fatalf("Expected 4 thumbnails, got %d", len(filePaths))
+	}
+
+	if width, height := decodeSize(t, filepath.Join(output, "16x16", "1004.jpeg")); width != 16 ||
+		height != 10 {
+		t.Fatalf("Expected 16x10 thumbnail, got %dx%d", width, height)
+	}
+
+	if filePaths, err = runThumbsCmd(dir, "", output, []string{"30x20"}, false); err != nil {
+		t.Fatalf("Expected no error, got %s", err)
+	}
+
+	if len(filePaths) != 0 {
+		t.Fatalf("Expected existing thumbnails to be kept, got %d new thumbnails", len(filePaths))
+	}
+
+	if _, err := runThumbsCmd(dir, "", output, []string{"30"}, false); err == nil {
+		t.Fatalf("Expected error for invalid size")
+	}
+}
+
+func TestRunContactSheetCmd(t *testing.T) {
+	t.Setenv("XDG_DATA_HOME", t.TempDir())
+
+	idx := index.New()
+	idx.Add(index.Entry{Id: 1003, Country: "France", Region: "Brittany"})
+	if err := idx.Save(); err != nil {
+		t.Fatalf("Failed to save index: %s", err)
+	}
+
+	dir := t.TempDir()
+	writeImages(t, dir, "1003", "1004", "1005")
+
+	sheetPath := filepath.Join(t.TempDir(), "sheet.jpeg")
+	filePath, err := runContactSheetCmd(dir, "", sheetPath, "40x30", 2, true)
+	if err != nil {
+		t.Fatalf("Expected contact sheet to be composed, got error: %s", err)
+	}
+
+	if filePath != sheetPath {
+		t.Fatalf("Expected %s, got %s", sheetPath, filePath)
+	}
+
+	face, err := newCaptionFace()
+	if err != nil {
+		t.Fatalf("Failed to load font: %s", err)
+	}
+
+	layout := sheetLayout{
+		columns:       2,
+		tileWidth:     40,
+		tileHeight:    30,
+		captionHeight: face.lineHeight*2 + face.lineHeight/2,
+	}
+	expectedWidth, expectedHeight := layout.size(3)
+	if width, height := decodeSize(t, filePath); width != expectedWidth ||
+		height != expectedHeight {
+		t.Fatalf(
+			"Expected %dx%d contact sheet, got %dx%d",
+			expectedWidth,
+			expectedHeight,
+			width,
+			height,
+		)
+	}
+
+	if _, err := runContactSheetCmd(dir, "", sheetPath, "40x30", 0, true); err == nil {
+		t.Fatalf("Expected error for invalid number of columns")
+	}
+}
+
+func TestSheetLayout(t *testing.T) {
+	layout := sheetLayout{columns: 3, tileWidth: 100, tileHeight: 50, captionHeight: 10}
+
+	if width, height := layout.size(4); width != 3*112+12 || height != 2*72+12 {
+		t.Fatalf("Expected 348x156, got %dx%d", width, height)
+	}
+
+	if rect := layout.tileRect(4); rect != image.Rect(124, 84, 224, 134) {
+		t.Fatalf("Expected tile at (124,84)-(224,134), got %v", rect)
+	}
+}
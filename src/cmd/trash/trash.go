@@ -0,0 +1,97 @@
+/*
+Copyright © 2024 Nicolas Goudry <goudry.nicolas@gmail.com>
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package trash
+
+import (
+	"fmt"
+	"os"
+
+	"earth-view/cmd"
+	"earth-view/lib"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	quiet bool
+
+	trashCmd = &cobra.Command{
+		Use:   "trash file...",
+		Short: "Move files to the trash",
+		Long: `Move files to the trash instead of deleting them.
+
+Description:
+  This command moves the given files to the trash, following the FreeDesktop.org
+  Trash specification. Trashed files can then be restored from any compliant
+  file manager.
+
+  Files living on the same filesystem as the home trash are moved to
+  '$XDG_DATA_HOME/Trash'. Files living on other filesystems are moved to the
+  '.Trash/$uid' directory at the top of their mount point if it exists and is
+  valid, or to the '.Trash-$uid' directory otherwise.
+
+  The path of each trashed file is printed to the standard output, unless the
+  '--quiet' flag is set.`,
+		DisableFlagsInUseLine: true,
+		SilenceUsage:          true,
+		Args: func(cmd *cobra.Command, args []string) error {
+			if err := cobra.MinimumNArgs(1)(cmd, args); err != nil {
+				return fmt.Errorf("missing required argument 'file'")
+			}
+
+			return nil
+		},
+		Run: func(_ *cobra.Command, args []string) {
+			cobra.CheckErr(runTrashCmd(args, quiet))
+		},
+	}
+)
+
+func init() {
+	cmd.RootCmd.AddCommand(trashCmd)
+
+	trashCmd.Flags().BoolVarP(&quiet, "quiet", "q", false, "do not output trashed files paths")
+}
+
+func runTrashCmd(files []string, quiet bool) error {
+	var failed bool
+
+	// Try to trash all files before reporting failure
+	for _, file := range files {
+		trashedPath, err := lib.Trash(file)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			failed = true
+			continue
+		}
+
+		if quiet == false {
+			fmt.Println(trashedPath)
+		}
+	}
+
+	if failed {
+		return fmt.Errorf("some files could not be trashed")
+	}
+
+	return nil
+}
@@ -0,0 +1,98 @@
+/*
+Copyright © 2024 Nicolas Goudry <goudry.nicolas@gmail.com>
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package worldmap
+
+import (
+	"math"
+)
+
+// brailleDots holds the bit of each dot of a braille character, by row then column
+var brailleDots = [4][2]uint8{
+	{0x01, 0x08},
+	{0x02, 0x10},
+	{0x04, 0x20},
+	{0x40, 0x80},
+}
+
+// canvas is a drawing surface made of braille characters, each cell holding 2x4 dots
+type canvas struct {
+	cols  int
+	rows  int
+	cells []uint8
+}
+
+func newCanvas(cols int, rows int) *canvas {
+	return &canvas{cols: cols, rows: rows, cells: make([]uint8, cols*rows)}
+}
+
+// width returns the number of dots of the canvas horizontally
+func (c *canvas) width() int {
+	return c.cols * 2
+}
+
+// height returns the number of dots of the canvas vertically
+func (c *canvas) height() int {
+	return c.rows * 4
+}
+
+// set turns the dot at the given position on, ignoring positions outside of the canvas
+func (c *canvas) set(x int, y int) {
+	if x < 0 || y < 0 || x >= c.width() || y >= c.height() {
+		return
+	}
+
+	c.cells[y/4*c.cols+x/2] |= brailleDots[y%4][x%2]
+}
+
+// line draws a line between two positions, which may be outside of the canvas
+func (c *canvas) line(x0 float64, y0 float64, x1 float64, y1 float64) {
+	w, h := float64(c.width()), float64(c.height())
+	if math.Max(x0, x1) < 0 || math.Min(x0, x1) >= w || math.Max(y0, y1) < 0 ||
+		math.Min(y0, y1) >= h {
+		return
+	}
+
+	steps := int(math.Ceil(math.Max(math.Abs(x1-x0), math.Abs(y1-y0))))
+	if steps == 0 {
+		c.set(int(math.Floor(x0)), int(math.Floor(y0)))
+		return
+	}
+
+	for i := 0; i <= steps; i++ {
+		t := float64(i) / float64(steps)
+		c.set(int(math.Floor(x0+(x1-x0)*t)), int(math.Floor(y0+(y1-y0)*t)))
+	}
+}
+
+// empty returns whether no dot of the cell is on
+func (c *canvas) empty(col int, row int) bool {
+	return c.cells[row*c.cols+col] == 0
+}
+
+// rune returns the braille character of the cell, or a space if it is empty
+func (c *canvas) rune(col int, row int) rune {
+	if c.empty(col, row) {
+		return ' '
+	}
+
+	return rune(0x2800 + int(c.cells[row*c.cols+col]))
+}
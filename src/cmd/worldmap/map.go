@@ -0,0 +1,109 @@
+/*
+Copyright © 2024 Nicolas Goudry <goudry.nicolas@gmail.com>
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package worldmap
+
+import (
+	"fmt"
+	"path/filepath"
+	"strconv"
+
+	"earth-view/cmd"
+	"earth-view/cmd/fetch"
+	"earth-view/lib/index"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/spf13/cobra"
+)
+
+var (
+	dir string
+
+	mapCmd = &cobra.Command{
+		Use:   "map",
+		Short: "Explore images on a world map",
+		Long: `Explore where Google Earth View images come from on a world map.
+
+Description:
+  This command plots the images of the local index, which is filled when
+  downloading images or by the 'index build' command, on a world map drawn in
+  the terminal with braille characters. It works offline, except for
+  downloading images.
+
+  Each image is shown as a dot, or as a bigger dot when several images share
+  the same spot of the map. The heatmap mode shades the map by the density of
+  images instead, on a logarithmic scale.
+
+  The image closest to the center of the map, marked by a cross, is selected
+  with the 'enter' key. Its location and attribution are then shown below the
+  map, and it can be downloaded in the directory set by the '--dir' flag,
+  defaulting to the current working directory.
+
+  Keys:
+    arrows  pan the map, also with h, j, k and l
+    + -     zoom in or out
+    0       show the whole world
+    enter   select the image closest to the center of the map
+    tab     select the next image in view, from west to east
+    c       center the map on the selected image
+    esc     clear selection
+    m       toggle heatmap mode
+    d       download the selected image
+    q       quit`,
+		DisableFlagsInUseLine: true,
+		SilenceUsage:          true,
+		Args:                  cobra.MaximumNArgs(0),
+		Run: func(_ *cobra.Command, _ []string) {
+			cobra.CheckErr(runMapCmd(dir))
+		},
+	}
+)
+
+func init() {
+	cmd.RootCmd.AddCommand(mapCmd)
+
+	mapCmd.Flags().StringVar(&dir, "dir", ".", "directory to download images to")
+}
+
+func runMapCmd(dir string) error {
+	idx, err := index.Load()
+	if err != nil {
+		return err
+	}
+
+	if idx.Len() == 0 {
+		return fmt.Errorf("index is empty, build it with the 'index build' command")
+	}
+
+	absDir, err := filepath.Abs(dir)
+	if err != nil {
+		return err
+	}
+
+	m := newModel(idx.Entries(), absDir)
+	m.download = func(id int) (string, error) {
+		return fetch.Fetch(strconv.Itoa(id), absDir, false)
+	}
+
+	_, err = tea.NewProgram(m, tea.WithAltScreen()).Run()
+
+	return err
+}
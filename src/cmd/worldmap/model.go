@@ -0,0 +1,442 @@
+package worldmap
+
+import (
+	"cmp"
+	"fmt"
+	"math"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+
+	"earth-view/lib"
+	"earth-view/lib/geo"
+	"earth-view/lib/index"
+	"earth-view/lib/theme"
+
+	"github.com/charmbracelet/bubbles/help"
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+const padding = 1
+
+// Characters of map cells holding images, by density level in heatmap mode
+var (
+	heatRunes  = []rune("░▒▓█")
+	heatColors = []lipgloss.AdaptiveColor{theme.Yellow, theme.Yellow, theme.Orange, theme.Red}
+)
+
+// keyMap holds the key bindings of the map
+type keyMap struct {
+	Up       key.Binding
+	Down     key.Binding
+	Left     key.Binding
+	Right    key.Binding
+	Pan      key.Binding
+	ZoomIn   key.Binding
+	ZoomOut  key.Binding
+	Reset    key.Binding
+	Select   key.Binding
+	Next     key.Binding
+	Previous key.Binding
+	Center   key.Binding
+	Clear    key.Binding
+	Heatmap  key.Binding
+	Download key.Binding
+	Quit     key.Binding
+}
+
+func (k keyMap) ShortHelp() []key.Binding {
+	return []key.Binding{
+		k.Pan, k.ZoomIn, k.ZoomOut, k.Select, k.Next, k.Center, k.Heatmap, k.Download, k.Quit,
+	}
+}
+
+func (k keyMap) FullHelp() [][]key.Binding {
+	return [][]key.Binding{k.ShortHelp()}
+}
+
+var keys = keyMap{
+	Up:    key.NewBinding(key.WithKeys("up", "k")),
+	Down:  key.NewBinding(key.WithKeys("down", "j")),
+	Left:  key.NewBinding(key.WithKeys("left", "h")),
+	Right: key.NewBinding(key.WithKeys("right", "l")),
+	Pan: key.NewBinding(
+		key.WithKeys("up", "down", "left", "right"),
+		key.WithHelp("←↓↑→", "pan"),
+	),
+	ZoomIn:   key.NewBinding(key.WithKeys("+", "="), key.WithHelp("+", "zoom in")),
+	ZoomOut:  key.NewBinding(key.WithKeys("-"), key.WithHelp("-", "zoom out")),
+	Reset:    key.NewBinding(key.WithKeys("0")),
+	Select:   key.NewBinding(key.WithKeys("enter"), key.WithHelp("enter", "select")),
+	Next:     key.NewBinding(key.WithKeys("tab", "n"), key.WithHelp("tab", "next")),
+	Previous: key.NewBinding(key.WithKeys("shift+tab", "N")),
+	Center:   key.NewBinding(key.WithKeys("c"), key.WithHelp("c", "center")),
+	Clear:    key.NewBinding(key.WithKeys("esc")),
+	Heatmap:  key.NewBinding(key.WithKeys("m"), key.WithHelp("m", "heatmap")),
+	Download: key.NewBinding(key.WithKeys("d"), key.WithHelp("d", "download")),
+	Quit:     key.NewBinding(key.WithKeys("q", "ctrl+c"), key.WithHelp("q", "quit")),
+}
+
+// Custom messages
+type downloadedMsg struct {
+	id       int
+	filePath string
+	err      error
+}
+
+// UI state
+type model struct {
+	entries   []index.Entry
+	view      viewport
+	heatmap   bool
+	selected  int
+	help      help.Model
+	dir       string
+	status    string
+	statusErr bool
+	width     int
+	height    int
+
+	// download fetches an image in the image directory and returns its path
+	download func(id int) (string, error)
+}
+
+func newModel(entries []index.Entry, dir string) model {
+	return model{
+		entries:  entries,
+		view:     world(),
+		selected: -1,
+		help:     help.New(),
+		dir:      dir,
+		width:    80,
+		height:   24,
+	}
+}
+
+// mapSize returns the number of columns and rows of the map
+func (m model) mapSize() (int, int) {
+	// Header, information, status and help lines
+	return max(m.width-padding*2, 1), max(m.height-4, 1)
+}
+
+// position returns the position in dots of an entry on the map
+func (m model) position(entry index.Entry) (float64, float64) {
+	cols, rows := m.mapSize()
+	p := entry.Point()
+	p.Lng = m.view.wrap(p.Lng)
+
+	return m.view.project(p, cols*2, rows*4)
+}
+
+// plot returns the indexes of the entries shown on the map, by cell
+func (m model) plot() map[int][]int {
+	cols, rows := m.mapSize()
+	cells := make(map[int][]int)
+
+	for i, entry := range m.entries {
+		x, y := m.position(entry)
+		if x < 0 || y < 0 || x >= float64(cols*2) || y >= float64(rows*4) {
+			continue
+		}
+
+		cell := int(y)/4*cols + int(x)/2
+		cells[cell] = append(cells[cell], i)
+	}
+
+	return cells
+}
+
+// inView returns the indexes of the entries shown on the map, from west to east
+func (m model) inView() []int {
+	var indexes []int
+	for _, cell := range m.plot() {
+		indexes = append(indexes, cell...)
+	}
+
+	slices.SortFunc(indexes, func(a, b int) int {
+		ax, ay := m.position(m.entries[a])
+		bx, by := m.position(m.entries[b])
+
+		if ax != bx {
+			return cmp.Compare(ax, bx)
+		}
+		if ay != by {
+			return cmp.Compare(ay, by)
+		}
+
+		return a - b
+	})
+
+	return indexes
+}
+
+// selectNearest selects the image shown closest to the center of the map
+func (m *model) selectNearest() {
+	cols, rows := m.mapSize()
+	cx, cy := float64(cols), float64(rows*2)
+
+	nearest, distance := -1, math.Inf(1)
+	for _, i := range m.inView() {
+		x, y := m.position(m.entries[i])
+		if d := math.Hypot(x-cx, y-cy); d < distance {
+			nearest, distance = i, d
+		}
+	}
+
+	if nearest < 0 {
+		m.status, m.statusErr = "No image in view", true
+		return
+	}
+
+	m.selected = nearest
+}
+
+// cycle selects the next or previous image shown on the map, from west to east
+func (m *model) cycle(step int) {
+	indexes := m.inView()
+	if len(indexes) == 0 {
+		m.status, m.statusErr = "No image in view", true
+		return
+	}
+
+	pos := slices.Index(indexes, m.selected)
+	switch {
+	case pos < 0 && step > 0:
+		pos = 0
+	case pos < 0:
+		pos = len(indexes) - 1
+	default:
+		pos = (pos + step + len(indexes)) % len(indexes)
+	}
+
+	m.selected = indexes[pos]
+}
+
+// imagePath returns the path of the image file in the image directory
+func (m model) imagePath(id int) string {
+	return filepath.Join(m.dir, strconv.Itoa(id)+".jpeg")
+}
+
+// downloadSelected fetches the selected image
+func (m *model) downloadSelected() tea.Cmd {
+	if m.selected < 0 {
+		m.status, m.statusErr = "No image selected", true
+		return nil
+	}
+
+	id := m.entries[m.selected].Id
+	m.status, m.statusErr = "Downloading...", false
+
+	return func() tea.Msg {
+		filePath, err := m.download(id)
+		return downloadedMsg{id: id, filePath: filePath, err: err}
+	}
+}
+
+func (m model) Init() tea.Cmd {
+	return nil
+}
+
+func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.KeyMsg:
+		cols, rows := m.mapSize()
+		m.status, m.statusErr = "", false
+
+		switch {
+		case key.Matches(msg, keys.Quit):
+			return m, tea.Quit
+		case key.Matches(msg, keys.Up):
+			m.view = m.view.pan(0, -0.25, cols*2, rows*4)
+		case key.Matches(msg, keys.Down):
+			m.view = m.view.pan(0, 0.25, cols*2, rows*4)
+		case key.Matches(msg, keys.Left):
+			m.view = m.view.pan(-0.125, 0, cols*2, rows*4)
+		case key.Matches(msg, keys.Right):
+			m.view = m.view.pan(0.125, 0, cols*2, rows*4)
+		case key.Matches(msg, keys.ZoomIn):
+			m.view = m.view.zoomBy(2)
+		case key.Matches(msg, keys.ZoomOut):
+			m.view = m.view.zoomBy(0.5)
+		case key.Matches(msg, keys.Reset):
+			m.view = world()
+		case key.Matches(msg, keys.Select):
+			m.selectNearest()
+		case key.Matches(msg, keys.Next):
+			m.cycle(1)
+		case key.Matches(msg, keys.Previous):
+			m.cycle(-1)
+		case key.Matches(msg, keys.Center):
+			if m.selected >= 0 {
+				m.view.center = m.entries[m.selected].Point()
+			}
+		case key.Matches(msg, keys.Clear):
+			m.selected = -1
+		case key.Matches(msg, keys.Heatmap):
+			m.heatmap = !m.heatmap
+		case key.Matches(msg, keys.Download):
+			return m, m.downloadSelected()
+		}
+
+	case tea.WindowSizeMsg:
+		m.width, m.height = msg.Width, msg.Height
+		m.help.Width = msg.Width
+
+	case downloadedMsg:
+		if msg.err != nil {
+			m.status, m.statusErr = msg.err.Error(), true
+			return m, nil
+		}
+
+		m.status, m.statusErr = fmt.Sprintf("Downloaded %d to %s", msg.id, msg.filePath), false
+	}
+
+	return m, nil
+}
+
+// heatLevel returns the density level of a cell holding the given number of images, relative to
+// the densest cell on a logarithmic scale
+func heatLevel(count int, densest int) int {
+	if densest <= 1 {
+		return 0
+	}
+
+	level := math.Log(float64(count)) / math.Log(float64(densest)) * float64(len(heatRunes)-1)
+
+	return int(math.Round(level))
+}
+
+// mapView draws the coastlines and images of the map
+func (m model) mapView() string {
+	cols, rows := m.mapSize()
+	c := newCanvas(cols, rows)
+
+	for _, shape := range geo.Coastlines() {
+		for _, offset := range []float64{-360, 0, 360} {
+			for i := 1; i < len(shape.Points); i++ {
+				a, b := shape.Points[i-1], shape.Points[i]
+				a.Lng += offset
+				b.Lng += offset
+
+				x0, y0 := m.view.project(a, c.width(), c.height())
+				x1, y1 := m.view.project(b, c.width(), c.height())
+				c.line(x0, y0, x1, y1)
+			}
+		}
+	}
+
+	cells := m.plot()
+	densest := 0
+	for _, indexes := range cells {
+		densest = max(densest, len(indexes))
+	}
+
+	var out strings.Builder
+	for row := 0; row < rows; row++ {
+		var run strings.Builder
+		var runColor lipgloss.AdaptiveColor
+
+		flush := func() {
+			if runColor == (lipgloss.AdaptiveColor{}) {
+				out.WriteString(run.String())
+			} else {
+				out.WriteString(lipgloss.NewStyle().Foreground(runColor).Render(run.String()))
+			}
+			run.Reset()
+		}
+
+		for col := 0; col < cols; col++ {
+			char, color := c.rune(col, row), theme.Green
+			if c.empty(col, row) {
+				color = lipgloss.AdaptiveColor{}
+			}
+
+			indexes := cells[row*cols+col]
+			switch {
+			case m.selected >= 0 && slices.Contains(indexes, m.selected):
+				char, color = '◉', theme.Red
+			case len(indexes) > 0 && m.heatmap:
+				level := heatLevel(len(indexes), densest)
+				char, color = heatRunes[level], heatColors[level]
+			case len(indexes) > 1:
+				char, color = '●', theme.Orange
+			case len(indexes) == 1:
+				char, color = '•', theme.Orange
+			case col == cols/2 && row == rows/2:
+				char, color = '+', theme.Blue
+			}
+
+			if color != runColor {
+				flush()
+				runColor = color
+			}
+			run.WriteRune(char)
+		}
+
+		flush()
+		if row < rows-1 {
+			out.WriteString("\n")
+		}
+	}
+
+	return out.String()
+}
+
+// infoView describes the selected image, or the part of the world shown on the map
+func (m model) infoView() string {
+	if m.selected < 0 {
+		return lipgloss.NewStyle().Foreground(theme.Muted).Render(fmt.Sprintf(
+			"Center %s, zoom x%g",
+			m.view.center.FormatDMS(),
+			m.view.zoom,
+		))
+	}
+
+	entry := m.entries[m.selected]
+
+	parts := []string{
+		lipgloss.NewStyle().Bold(true).Foreground(theme.Red).Render(strconv.Itoa(entry.Id)),
+		entry.Location(),
+		entry.Point().FormatDMS(),
+		entry.Attribution,
+	}
+	if lib.FileExists(m.imagePath(entry.Id)) {
+		parts = append(parts, lipgloss.NewStyle().Foreground(theme.Green).Render("downloaded"))
+	}
+
+	return strings.Join(
+		slices.DeleteFunc(parts, func(part string) bool { return part == "" }),
+		"  ",
+	)
+}
+
+func (m model) View() string {
+	mode := "points"
+	if m.heatmap {
+		mode = "heatmap"
+	}
+
+	title := lipgloss.NewStyle().Bold(true).Foreground(theme.Blue).Render("Earth View")
+	count := lipgloss.NewStyle().
+		Foreground(theme.Muted).
+		Render(fmt.Sprintf("%d/%d images in view, %s", len(m.inView()), len(m.entries), mode))
+
+	statusStyle := lipgloss.NewStyle().Padding(0, padding).Foreground(theme.Green)
+	if m.statusErr {
+		statusStyle = statusStyle.Foreground(theme.Red)
+	}
+
+	line := lipgloss.NewStyle().Padding(0, padding)
+
+	return lipgloss.JoinVertical(
+		lipgloss.Left,
+		line.Render(lipgloss.JoinHorizontal(lipgloss.Top, title, "  ", count)),
+		line.Render(m.mapView()),
+		line.Render(m.infoView()),
+		statusStyle.Render(m.status),
+		line.Render(m.help.View(keys)),
+	)
+}
@@ -0,0 +1,203 @@
+package worldmap
+
+import (
+	"errors"
+	"math"
+	"strings"
+	"testing"
+
+	"earth-view/lib/geo"
+	"earth-view/lib/index"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+var entries = []index.Entry{
+	{Id: 1003, Country: "France", Lat: 48.85, Lng: 2.35},
+	{Id: 1004, Country: "Iceland", Lat: 64.15, Lng: -21.94},
+	{Id: 1006, Country: "Chile", Region: "Atacama", Lat: -23.65, Lng: -70.4},
+}
+
+func newTestModel(t *testing.T) model {
+	m := newModel(entries, t.TempDir())
+	m.width, m.height = 120, 40
+	m.download = func(id int) (string, error) { return "", nil }
+
+	return m
+}
+
+// send runs the update function with the given messages, and the messages of resulting commands
+func send(m model, msgs ...tea.Msg) model {
+	for _, msg := range msgs {
+		updated, cmd := m.Update(msg)
+		m = updated.(model)
+
+		if cmd != nil {
+			if result := cmd(); result != nil {
+				m = send(m, result)
+			}
+		}
+	}
+
+	return m
+}
+
+func keyMsg(s string) tea.KeyMsg {
+	switch s {
+	case "enter":
+		return tea.KeyMsg{Type: tea.KeyEnter}
+	case "esc":
+		return tea.KeyMsg{Type: tea.KeyEsc}
+	case "tab":
+		return tea.KeyMsg{Type: tea.KeyTab}
+	case "right":
+		return tea.KeyMsg{Type: tea.KeyRight}
+	}
+
+	return tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(s)}
+}
+
+func TestCanvas(t *testing.T) {
+	c := newCanvas(2, 1)
+	c.set(0, 0)
+	c.set(1, 3)
+	c.set(10, 10)
+
+	if r := c.rune(0, 0); r != '⢁' {
+		t.Fatalf("Expected braille character with first and last dots, got %q", r)
+	}
+	if r := c.rune(1, 0); r != ' ' {
+		t.Fatalf("Expected empty cell to be a space, got %q", r)
+	}
+
+	c = newCanvas(2, 1)
+	c.line(0, 3, 3, 0)
+	if r := c.rune(0, 0); r != '⡠' {
+		t.Fatalf("Expected diagonal line in first cell, got %q", r)
+	}
+	if r := c.rune(1, 0); r != '⠊' {
+		t.Fatalf("Expected diagonal line in second cell, got %q", r)
+	}
+}
+
+func TestViewport(t *testing.T) {
+	v := world()
+
+	x, y := v.project(geo.Point{Lat: 90, Lng: -180}, 360, 180)
+	if x != 0 || y != 0 {
+		t.Fatalf("Expected north-west corner of the world at origin, got %f,%f", x, y)
+	}
+
+	v = v.zoomBy(4)
+	if x, _ := v.project(geo.Point{Lng: 45}, 360, 180); x != 360 {
+		t.Fatalf("Expected zoomed viewport to show a quarter of the world, got %f", x)
+	}
+
+	if v.zoomBy(1000).zoom != maxZoom || v.zoomBy(0.001).zoom != 1 {
+		t.Fatalf("Expected zoom to be clamped")
+	}
+
+	v.center.Lng = 170
+	v = v.pan(0.5, 0, 360, 180)
+	if math.Abs(v.center.Lng+145) > 1e-9 {
+		t.Fatalf("Expected panning across the antimeridian to wrap longitude, got %f", v.center.Lng)
+	}
+	if lng := v.wrap(170); lng != -190 {
+		t.Fatalf("Expected longitude to be wrapped close to the center, got %f", lng)
+	}
+
+	v = v.pan(0, -10, 360, 180)
+	if v.center.Lat != 90 {
+		t.Fatalf("Expected latitude to be clamped, got %f", v.center.Lat)
+	}
+}
+
+func TestHeatLevel(t *testing.T) {
+	if level := heatLevel(1, 1); level != 0 {
+		t.Fatalf("Expected lowest level when no cell is denser, got %d", level)
+	}
+	if level := heatLevel(100, 100); level != len(heatRunes)-1 {
+		t.Fatalf("Expected highest level for the densest cell, got %d", level)
+	}
+	if level := heatLevel(10, 100); level != 2 {
+		t.Fatalf("Expected logarithmic level, got %d", level)
+	}
+}
+
+func TestSelection(t *testing.T) {
+	m := newTestModel(t)
+
+	// Zooming in around Europe hides Chile
+	m.view.center = geo.Point{Lat: 60, Lng: -15}
+	m = send(m, keyMsg("+"), keyMsg("+"))
+	if n := len(m.inView()); n != 2 {
+		t.Fatalf("Expected 2 images in view, got %d", n)
+	}
+
+	m = send(m, keyMsg("enter"))
+	if m.selected < 0 || m.entries[m.selected].Id != 1004 {
+		t.Fatalf("Expected image closest to the center to be selected, got %d", m.selected)
+	}
+
+	m = send(m, keyMsg("tab"))
+	if m.entries[m.selected].Id != 1003 {
+		t.Fatalf("Expected next image to the east to be selected, got %d", m.selected)
+	}
+
+	m = send(m, keyMsg("tab"))
+	if m.entries[m.selected].Id != 1004 {
+		t.Fatalf("Expected selection to cycle, got %d", m.selected)
+	}
+
+	if view := m.View(); !strings.Contains(view, "1004") || !strings.Contains(view, "◉") {
+		t.Fatalf("Expected view to show selected image, got %s", view)
+	}
+
+	m = send(m, keyMsg("c"))
+	if m.view.center != entries[1].Point() {
+		t.Fatalf("Expected map to be centered on selected image, got %v", m.view.center)
+	}
+
+	m = send(m, keyMsg("esc"), keyMsg("0"))
+	if m.selected != -1 || m.view != world() {
+		t.Fatalf("Expected selection to be cleared and the whole world to be shown")
+	}
+}
+
+func TestHeatmap(t *testing.T) {
+	m := newTestModel(t)
+	m.entries = append(m.entries, entries[0], entries[0])
+
+	m = send(m, keyMsg("m"))
+	view := m.View()
+
+	if !strings.Contains(view, "█") || !strings.Contains(view, "░") {
+		t.Fatalf("Expected heatmap to shade cells by density, got %s", view)
+	}
+}
+
+func TestDownload(t *testing.T) {
+	m := newTestModel(t)
+
+	m = send(m, keyMsg("d"))
+	if !m.statusErr {
+		t.Fatalf("Expected error without selected image")
+	}
+
+	var downloaded int
+	m.download = func(id int) (string, error) {
+		downloaded = id
+		return "/tmp/1006.jpeg", nil
+	}
+
+	m = send(m, keyMsg("tab"), keyMsg("d"))
+	if downloaded != 1006 || m.statusErr || !strings.Contains(m.status, "1006") {
+		t.Fatalf("Expected westernmost image to be downloaded, got %d (%s)", downloaded, m.status)
+	}
+
+	m.download = func(int) (string, error) { return "", errors.New("offline") }
+	m = send(m, keyMsg("d"))
+	if !m.statusErr || m.status != "offline" {
+		t.Fatalf("Expected download error in status, got %s", m.status)
+	}
+}
@@ -0,0 +1,78 @@
+/*
+Copyright © 2024 Nicolas Goudry <goudry.nicolas@gmail.com>
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package worldmap
+
+import (
+	"math"
+
+	"earth-view/lib/geo"
+)
+
+const maxZoom = 64
+
+// viewport is the part of the world shown on the map, using an equirectangular projection
+type viewport struct {
+	center geo.Point
+	zoom   float64
+}
+
+// world returns the viewport showing the whole world
+func world() viewport {
+	return viewport{zoom: 1}
+}
+
+// scale returns the number of degrees per dot, so that the whole world fits in the given number
+// of dots when not zoomed
+func (v viewport) scale(width int, height int) float64 {
+	return math.Max(360/float64(width), 180/float64(height)) / v.zoom
+}
+
+// wrap returns the longitude of the point shifted by whole turns to be the closest to the center
+// of the viewport, so that the map can be panned across the antimeridian
+func (v viewport) wrap(lng float64) float64 {
+	return lng - 360*math.Round((lng-v.center.Lng)/360)
+}
+
+// project returns the position in dots of the point on a canvas of the given size
+func (v viewport) project(p geo.Point, width int, height int) (float64, float64) {
+	s := v.scale(width, height)
+
+	return float64(width)/2 + (p.Lng-v.center.Lng)/s, float64(height)/2 - (p.Lat-v.center.Lat)/s
+}
+
+// pan moves the viewport by the given fractions of its width and height
+func (v viewport) pan(dx float64, dy float64, width int, height int) viewport {
+	s := v.scale(width, height)
+
+	v.center.Lng += dx * float64(width) * s
+	v.center.Lng -= 360 * math.Floor((v.center.Lng+180)/360)
+	v.center.Lat = math.Max(-90, math.Min(90, v.center.Lat-dy*float64(height)*s))
+
+	return v
+}
+
+// zoomBy multiplies the zoom level by the given factor, within the supported range
+func (v viewport) zoomBy(factor float64) viewport {
+	v.zoom = math.Max(1, math.Min(maxZoom, v.zoom*factor))
+
+	return v
+}
@@ -24,6 +24,7 @@ package lib
 import (
 	"encoding/base64"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
@@ -32,6 +33,23 @@ import (
 	"time"
 )
 
+// ErrNotFound is returned when fetching an asset which does not exist
+var ErrNotFound = errors.New("asset not found")
+
+// baseUrl is the URL assets are fetched from
+var baseUrl = DefaultBaseUrl
+
+// SetBaseUrl sets the URL assets are fetched from, which serves '<id>.json' files like gstatic.com
+// does, e.g. another instance running the 'mirror serve' command
+func SetBaseUrl(url string) {
+	baseUrl = strings.TrimSuffix(url, "/")
+}
+
+// BaseUrl returns the URL assets are fetched from
+func BaseUrl() string {
+	return baseUrl
+}
+
 // Asset represents a Google Earth View asset
 type Asset struct {
 	Id       int
@@ -52,7 +70,7 @@ func (a *Asset) Fetch(retry int) error {
 	defer response.Body.Close()
 
 	if response.StatusCode == http.StatusNotFound {
-		return fmt.Errorf("[%d] fetch failed: asset not found", a.Id)
+		return fmt.Errorf("[%d] fetch failed: %w", a.Id, ErrNotFound)
 	}
 
 	if response.StatusCode != http.StatusOK {
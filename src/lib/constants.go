@@ -22,7 +22,8 @@ THE SOFTWARE.
 package lib
 
 const (
-	baseUrl              = "https://www.gstatic.com/prettyearth/assets/data/v3"
+	DefaultBaseUrl       = "https://www.gstatic.com/prettyearth/assets/data/v3"
+	BaseUrlEnv           = "EARTH_VIEW_BASE_URL"
 	KnownIdLowerBoundary = 1000
 	KnownIdUpperBoundary = 15000
 )
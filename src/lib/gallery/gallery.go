@@ -0,0 +1,261 @@
+/*
+Copyright © 2024 Nicolas Goudry <goudry.nicolas@gmail.com>
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package gallery
+
+import (
+	"bytes"
+	_ "embed"
+	"fmt"
+	"html/template"
+	"image"
+	"image/jpeg"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+
+	"earth-view/lib"
+	"earth-view/lib/geo"
+	"earth-view/lib/imaging"
+	"earth-view/lib/index"
+)
+
+// Maximum size of thumbnails in pixels
+const (
+	thumbWidth  = 480
+	thumbHeight = 320
+)
+
+var (
+	//go:embed templates.html
+	templatesHtml string
+	//go:embed style.css
+	styleCss []byte
+
+	templates = template.Must(template.New("gallery").Parse(templatesHtml))
+)
+
+// Item is an image of the gallery, along with what its pages show
+type Item struct {
+	index.Entry
+	Location  string
+	Continent string
+	Dms       string
+	MapsLink  string
+	EarthLink string
+	OsmLink   string
+}
+
+// MapY returns the vertical position of the image on the world map, which is the opposite of its
+// latitude as SVG coordinates grow downwards
+func (i Item) MapY() float64 {
+	return -i.Lat
+}
+
+// country is an option of the country filter
+type country struct {
+	Name  string
+	Count int
+}
+
+// page holds the data of a page template
+type page struct {
+	Title string
+	// Root is the relative path of the site root from the page
+	Root      string
+	Items     []Item
+	Countries []country
+	Item      Item
+	Previous  *Item
+	Next      *Item
+	Coastline string
+}
+
+// newItem returns the gallery item of an index entry
+func newItem(entry index.Entry) Item {
+	item := Item{
+		Entry:     entry,
+		Location:  entry.Location(),
+		Continent: geo.ContinentOf(entry.Country),
+		Dms:       entry.Point().FormatDMS(),
+		OsmLink:   geo.OsmLink(entry.Point(), entry.Zoom),
+	}
+	item.MapsLink, item.EarthLink = entry.Links()
+	if item.Location == "" {
+		item.Location = "Image " + strconv.Itoa(entry.Id)
+	}
+
+	return item
+}
+
+// countries returns the countries of the items with their number of images, sorted by name
+func countries(items []Item) []country {
+	counts := make(map[string]int)
+	for _, item := range items {
+		if item.Country != "" {
+			counts[item.Country]++
+		}
+	}
+
+	result := make([]country, 0, len(counts))
+	for name, count := range counts {
+		result = append(result, country{Name: name, Count: count})
+	}
+	slices.SortFunc(result, func(a, b country) int { return strings.Compare(a.Name, b.Name) })
+
+	return result
+}
+
+// coastlinePath returns the SVG path of the coastlines, in a coordinate system where x is the
+// longitude and y the opposite of the latitude
+func coastlinePath() string {
+	var path strings.Builder
+	for _, shape := range geo.Coastlines() {
+		for i, p := range shape.Points {
+			command := "L"
+			if i == 0 {
+				command = "M"
+			}
+
+			fmt.Fprintf(&path, "%s%g,%g", command, p.Lng, -p.Lat)
+		}
+	}
+
+	return path.String()
+}
+
+// render executes a page template into a file
+func render(filePath string, name string, data page) error {
+	var out bytes.Buffer
+	if err := templates.ExecuteTemplate(&out, name, data); err != nil {
+		return err
+	}
+
+	return lib.WriteFile(out.Bytes(), filePath)
+}
+
+// writeImage copies an image in the site along with its thumbnail, unless they already exist, and
+// returns its dimensions
+func writeImage(outDir string, id int, content []byte) (int, int, error) {
+	name := strconv.Itoa(id) + ".jpeg"
+	imagePath := filepath.Join(outDir, "images", name)
+	thumbPath := filepath.Join(outDir, "thumbs", name)
+
+	if !lib.FileExists(imagePath) {
+		if err := lib.WriteFile(content, imagePath); err != nil {
+			return 0, 0, err
+		}
+	}
+
+	if lib.FileExists(thumbPath) {
+		config, _, err := image.DecodeConfig(bytes.NewReader(content))
+		if err != nil {
+			return 0, 0, fmt.Errorf("failed to decode image %d: %s", id, err)
+		}
+
+		return config.Width, config.Height, nil
+	}
+
+	img, _, err := image.Decode(bytes.NewReader(content))
+	if err != nil {
+		return 0, 0, fmt.Errorf("failed to decode image %d: %s", id, err)
+	}
+
+	var thumb bytes.Buffer
+	err = jpeg.Encode(
+		&thumb,
+		imaging.Fit(img, thumbWidth, thumbHeight, imaging.DefaultInterpolator),
+		&jpeg.Options{Quality: 80},
+	)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	if err := lib.WriteFile(thumb.Bytes(), thumbPath); err != nil {
+		return 0, 0, err
+	}
+
+	return img.Bounds().Dx(), img.Bounds().Dy(), nil
+}
+
+// Generate writes a static site showing the given images in the output directory, with a
+// thumbnail grid filtered by country, a page per image and a world map of their locations
+// The content of images is read with the given function, and images and thumbnails already present
+// in the output directory are kept
+func Generate(
+	outDir string,
+	title string,
+	entries []index.Entry,
+	readImage func(id int) ([]byte, error),
+) error {
+	for _, dir := range []string{"images", "thumbs"} {
+		if err := os.MkdirAll(filepath.Join(outDir, dir), 0755); err != nil {
+			return err
+		}
+	}
+
+	items := make([]Item, len(entries))
+	for i, entry := range entries {
+		content, err := readImage(entry.Id)
+		if err != nil {
+			return err
+		}
+
+		if entry.Width, entry.Height, err = writeImage(outDir, entry.Id, content); err != nil {
+			return err
+		}
+
+		items[i] = newItem(entry)
+	}
+
+	if err := lib.WriteFile(styleCss, filepath.Join(outDir, "style.css")); err != nil {
+		return err
+	}
+
+	site := page{Title: title, Items: items, Countries: countries(items)}
+	if err := render(filepath.Join(outDir, "index.html"), "index", site); err != nil {
+		return err
+	}
+
+	site.Coastline = coastlinePath()
+	if err := render(filepath.Join(outDir, "map.html"), "map", site); err != nil {
+		return err
+	}
+
+	for i, item := range items {
+		data := page{Title: title, Root: "../", Item: item}
+		if i > 0 {
+			data.Previous = &items[i-1]
+		}
+		if i < len(items)-1 {
+			data.Next = &items[i+1]
+		}
+
+		filePath := filepath.Join(outDir, "images", strconv.Itoa(item.Id)+".html")
+		if err := render(filePath, "image", data); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
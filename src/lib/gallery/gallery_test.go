@@ -0,0 +1,128 @@
+package gallery
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"image/jpeg"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"earth-view/lib/index"
+)
+
+var entries = []index.Entry{
+	{
+		Id:          1003,
+		Country:     "France",
+		Region:      "Brittany",
+		Lat:         48.2,
+		Lng:         -3.1,
+		Attribution: "©2014 Google",
+		MapsLink:    "https://g.co/maps/brittany",
+	},
+	{Id: 1004, Country: "Iceland", Lat: 64.1, Lng: -21.9},
+	{Id: 1006, Country: "France", Lat: 43.6, Lng: 3.9},
+}
+
+func readImage(id int) ([]byte, error) {
+	var out bytes.Buffer
+	err := jpeg.Encode(&out, image.NewRGBA(image.Rect(0, 0, 960, 640)), nil)
+
+	return out.Bytes(), err
+}
+
+func readFile(t *testing.T, filePath string) string {
+	content, err := os.ReadFile(filePath)
+	if err != nil {
+		t.Fatalf("Expected %s to be generated, got error: %s", filePath, err)
+	}
+
+	return string(content)
+}
+
+func TestGenerate(t *testing.T) {
+	outDir := t.TempDir()
+
+	if err := Generate(outDir, "Our Earth", entries, readImage); err != nil {
+		t.Fatalf("Expected gallery to be generated, got error: %s", err)
+	}
+
+	index := readFile(t, filepath.Join(outDir, "index.html"))
+	for _, expected := range []string{
+		"<title>Our Earth</title>",
+		`<option value="France">France (2)</option>`,
+		`<option value="Iceland">Iceland (1)</option>`,
+		`<li data-country="France">`,
+		`<img src="thumbs/1003.jpeg" alt="Brittany, France"`,
+		`href="style.css"`,
+	} {
+		if !strings.Contains(index, expected) {
+			t.Fatalf("Expected index page to contain %q, got %s", expected, index)
+		}
+	}
+
+	page := readFile(t, filepath.Join(outDir, "images", "1004.html"))
+	for _, expected := range []string{
+		`<h1>Iceland</h1>`,
+		`href="1003.html" rel="prev"`,
+		`href="1006.html" rel="next"`,
+		`width="960" height="640"`,
+		`href="../style.css"`,
+		"https://www.openstreetmap.org/",
+	} {
+		if !strings.Contains(page, expected) {
+			t.Fatalf("Expected image page to contain %q, got %s", expected, page)
+		}
+	}
+
+	page = readFile(t, filepath.Join(outDir, "images", "1003.html"))
+	if !strings.Contains(page, "©2014 Google") {
+		t.Fatalf("Expected image page to show attribution, got %s", page)
+	}
+
+	if !strings.Contains(page, `href="https://g.co/maps/brittany"`) {
+		t.Fatalf("Expected image page to link to upstream map, got %s", page)
+	}
+
+	worldMap := readFile(t, filepath.Join(outDir, "map.html"))
+	if !strings.Contains(worldMap, `<path class="coast" d="M`) ||
+		!strings.Contains(worldMap, `<circle cx="-21.9" cy="-64.1"`) {
+		t.Fatalf("Expected map page to plot locations on coastlines, got %s", worldMap)
+	}
+
+	file, err := os.Open(filepath.Join(outDir, "thumbs", "1006.jpeg"))
+	if err != nil {
+		t.Fatalf("Expected thumbnail to be generated, got error: %s", err)
+	}
+	defer file.Close()
+
+	config, err := jpeg.DecodeConfig(file)
+	if err != nil || config.Width != thumbWidth || config.Height != thumbHeight {
+		t.Fatalf(
+			"Expected %dx%d thumbnail, got %dx%d (%v)",
+			thumbWidth,
+			thumbHeight,
+			config.Width,
+			config.Height,
+			err,
+		)
+	}
+
+	if content := readFile(t, filepath.Join(outDir, "style.css")); !strings.Contains(
+		content,
+		".grid",
+	) {
+		t.Fatalf("Expected stylesheet to be written")
+	}
+}
+
+func TestGenerateError(t *testing.T) {
+	failing := func(id int) ([]byte, error) { return nil, fmt.Errorf("image %d not found", id) }
+
+	if err := Generate(t.TempDir(), "Earth View", entries, failing); err == nil {
+		t.Fatalf("Expected error when an image cannot be read")
+	}
+}
@@ -0,0 +1,87 @@
+/*
+Copyright © 2024 Nicolas Goudry <goudry.nicolas@gmail.com>
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package geo
+
+import (
+	_ "embed"
+	"fmt"
+	"strings"
+	"sync"
+)
+
+var (
+	//go:embed coastlines.txt
+	coastlinesTxt string
+
+	coastlinesOnce sync.Once
+	coastlines     []Shape
+)
+
+// Shape is a named coastline, as a line going through its points
+type Shape struct {
+	Name   string
+	Points []Point
+}
+
+// parseCoastlines reads shapes from lines holding a name followed by longitude,latitude points
+func parseCoastlines(data string) ([]Shape, error) {
+	var shapes []Shape
+
+	for i, line := range strings.Split(data, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) == 0 || strings.HasPrefix(fields[0], "#") {
+			continue
+		}
+
+		shape := Shape{Name: fields[0]}
+		for _, field := range fields[1:] {
+			lng, lat, ok := strings.Cut(field, ",")
+			if !ok {
+				return nil, fmt.Errorf("line %d: invalid point %q", i+1, field)
+			}
+
+			p, err := ParsePoint(lat + "," + lng)
+			if err != nil {
+				return nil, fmt.Errorf("line %d: %s", i+1, err)
+			}
+
+			shape.Points = append(shape.Points, p)
+		}
+
+		shapes = append(shapes, shape)
+	}
+
+	return shapes, nil
+}
+
+// Coastlines returns the embedded low resolution coastlines of the world, suitable for drawing
+// maps of a few hundred pixels wide
+func Coastlines() []Shape {
+	coastlinesOnce.Do(func() {
+		var err error
+		if coastlines, err = parseCoastlines(coastlinesTxt); err != nil {
+			panic(err)
+		}
+	})
+
+	return coastlines
+}
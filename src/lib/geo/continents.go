@@ -0,0 +1,86 @@
+/*
+Copyright © 2024 Nicolas Goudry <goudry.nicolas@gmail.com>
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package geo
+
+import (
+	_ "embed"
+	"encoding/csv"
+	"slices"
+	"strings"
+	"sync"
+)
+
+var (
+	//go:embed continents.csv
+	continentsCsv string
+
+	continentsOnce sync.Once
+	continents     map[string]string
+
+	// Continents lists the continents countries are attached to
+	Continents = []string{
+		"Africa",
+		"Antarctica",
+		"Asia",
+		"Europe",
+		"North America",
+		"Oceania",
+		"South America",
+	}
+)
+
+// normalizeName returns a name suitable for case-insensitive comparison
+func normalizeName(name string) string {
+	return strings.ToLower(strings.TrimSpace(name))
+}
+
+// ContinentOf returns the continent of a country from its English name, as found in images
+// metadata, or an empty string if the country is unknown
+// Transcontinental countries are attached to a single continent
+func ContinentOf(country string) string {
+	continentsOnce.Do(func() {
+		records, err := csv.NewReader(strings.NewReader(continentsCsv)).ReadAll()
+		if err != nil {
+			panic(err)
+		}
+
+		continents = make(map[string]string, len(records))
+		for _, record := range records[1:] {
+			continents[normalizeName(record[0])] = record[1]
+		}
+	})
+
+	return continents[normalizeName(country)]
+}
+
+// ParseContinent returns the continent matching the given name, case-insensitively, and whether
+// it is a known continent
+func ParseContinent(name string) (string, bool) {
+	i := slices.IndexFunc(Continents, func(continent string) bool {
+		return normalizeName(continent) == normalizeName(name)
+	})
+	if i < 0 {
+		return "", false
+	}
+
+	return Continents[i], true
+}
@@ -0,0 +1,192 @@
+/*
+Copyright © 2024 Nicolas Goudry <goudry.nicolas@gmail.com>
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package geo
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// earthRadius is the mean radius of the Earth in kilometers
+const earthRadius = 6371.0
+
+// Point represents a location by its coordinates in decimal degrees
+type Point struct {
+	Lat float64
+	Lng float64
+}
+
+// Bbox represents a bounding box by its edges in decimal degrees
+// A box crossing the antimeridian has a western edge greater than its eastern edge
+type Bbox struct {
+	MinLon float64
+	MinLat float64
+	MaxLon float64
+	MaxLat float64
+}
+
+// parseCoordinates parses a comma separated list of the given number of decimal degrees
+func parseCoordinates(value string, count int) ([]float64, error) {
+	parts := strings.Split(value, ",")
+	if len(parts) != count {
+		return nil, fmt.Errorf("expected %d comma separated numbers", count)
+	}
+
+	coordinates := make([]float64, count)
+	for i, part := range parts {
+		coordinate, err := strconv.ParseFloat(strings.TrimSpace(part), 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid number: %s", part)
+		}
+
+		coordinates[i] = coordinate
+	}
+
+	return coordinates, nil
+}
+
+// ParsePoint parses a point formatted as 'lat,lng'
+func ParsePoint(value string) (Point, error) {
+	coordinates, err := parseCoordinates(value, 2)
+	if err != nil {
+		return Point{}, fmt.Errorf("invalid point: %s: %s", value, err)
+	}
+
+	point := Point{Lat: coordinates[0], Lng: coordinates[1]}
+	if math.Abs(point.Lat) > 90 || math.Abs(point.Lng) > 180 {
+		return Point{}, fmt.Errorf("invalid point: %s: coordinates out of range", value)
+	}
+
+	return point, nil
+}
+
+// ParseBbox parses a bounding box formatted as 'minLon,minLat,maxLon,maxLat'
+func ParseBbox(value string) (Bbox, error) {
+	coordinates, err := parseCoordinates(value, 4)
+	if err != nil {
+		return Bbox{}, fmt.Errorf("invalid bounding box: %s: %s", value, err)
+	}
+
+	bbox := Bbox{
+		MinLon: coordinates[0],
+		MinLat: coordinates[1],
+		MaxLon: coordinates[2],
+		MaxLat: coordinates[3],
+	}
+
+	if math.Abs(bbox.MinLon) > 180 || math.Abs(bbox.MaxLon) > 180 ||
+		math.Abs(bbox.MinLat) > 90 || math.Abs(bbox.MaxLat) > 90 {
+		return Bbox{}, fmt.Errorf("invalid bounding box: %s: coordinates out of range", value)
+	}
+
+	if bbox.MinLat > bbox.MaxLat {
+		return Bbox{}, fmt.Errorf(
+			"invalid bounding box: %s: minimum latitude is greater than maximum latitude",
+			value,
+		)
+	}
+
+	return bbox, nil
+}
+
+// Contains returns whether the point is inside the bounding box
+func (b Bbox) Contains(p Point) bool {
+	if p.Lat < b.MinLat || p.Lat > b.MaxLat {
+		return false
+	}
+
+	if b.MinLon <= b.MaxLon {
+		return p.Lng >= b.MinLon && p.Lng <= b.MaxLon
+	}
+
+	return p.Lng >= b.MinLon || p.Lng <= b.MaxLon
+}
+
+// ParseDistance parses a distance with its unit (m, km or mi) and returns it in kilometers
+// A number without unit is in kilometers
+func ParseDistance(value string) (float64, error) {
+	units := []struct {
+		suffix string
+		factor float64
+	}{
+		{"km", 1},
+		{"mi", 1.609344},
+		{"m", 0.001},
+		{"", 1},
+	}
+
+	for _, unit := range units {
+		number, found := strings.CutSuffix(strings.TrimSpace(value), unit.suffix)
+		if !found {
+			continue
+		}
+
+		distance, err := strconv.ParseFloat(strings.TrimSpace(number), 64)
+		if err != nil || distance < 0 {
+			break
+		}
+
+		return distance * unit.factor, nil
+	}
+
+	return 0, fmt.Errorf("invalid distance: %s. Expected a number followed by m, km or mi", value)
+}
+
+// Distance returns the great-circle distance between two points in kilometers
+func Distance(a Point, b Point) float64 {
+	lat1, lat2 := radians(a.Lat), radians(b.Lat)
+	dLat := lat2 - lat1
+	dLng := radians(b.Lng - a.Lng)
+
+	h := math.Sin(dLat/2)*math.Sin(dLat/2) +
+		math.Cos(lat1)*math.Cos(lat2)*math.Sin(dLng/2)*math.Sin(dLng/2)
+
+	return 2 * earthRadius * math.Asin(math.Min(1, math.Sqrt(h)))
+}
+
+// radians converts degrees to radians
+func radians(degrees float64) float64 {
+	return degrees * math.Pi / 180
+}
+
+// FormatDMS formats the point in degrees, minutes and seconds, e.g. 48°51'23.8"N 2°21'07.9"E
+func (p Point) FormatDMS() string {
+	return formatDMS(p.Lat, "N", "S") + " " + formatDMS(p.Lng, "E", "W")
+}
+
+// formatDMS formats a coordinate in degrees, minutes and seconds with its hemisphere
+func formatDMS(coordinate float64, positive string, negative string) string {
+	hemisphere := positive
+	if coordinate < 0 {
+		hemisphere = negative
+	}
+
+	// Round to the displayed precision first so that seconds never show as 60
+	tenths := int(math.Round(math.Abs(coordinate) * 36000))
+	degrees := tenths / 36000
+	minutes := tenths % 36000 / 600
+	seconds := float64(tenths%600) / 10
+
+	return fmt.Sprintf("%d°%02d'%04.1f\"%s", degrees, minutes, seconds, hemisphere)
+}
@@ -0,0 +1,117 @@
+package geo
+
+import (
+	"math"
+	"testing"
+)
+
+func TestDistance(t *testing.T) {
+	paris := Point{Lat: 48.8566, Lng: 2.3522}
+	london := Point{Lat: 51.5074, Lng: -0.1278}
+
+	if d := Distance(paris, london); math.Abs(d-344) > 5 {
+		t.Fatalf("Expected distance between Paris and London to be about 344km, got %f", d)
+	}
+}
+
+func TestParseDistance(t *testing.T) {
+	for _, tc := range []struct {
+		value    string
+		expected float64
+	}{
+		{"500km", 500},
+		{"500", 500},
+		{"1500m", 1.5},
+		{"10mi", 16.09344},
+	} {
+		d, err := ParseDistance(tc.value)
+		if err != nil || math.Abs(d-tc.expected) > 1e-9 {
+			t.Fatalf("Expected %s to be %fkm, got %f (%v)", tc.value, tc.expected, d, err)
+		}
+	}
+
+	for _, value := range []string{"", "km", "far", "-1km"} {
+		if _, err := ParseDistance(value); err == nil {
+			t.Fatalf("Expected error for distance %q", value)
+		}
+	}
+}
+
+func TestBbox(t *testing.T) {
+	europe, err := ParseBbox("-25,34,45,72")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if !europe.Contains(Point{Lat: 48.8566, Lng: 2.3522}) {
+		t.Fatalf("Expected Paris to be in Europe bounding box")
+	}
+
+	if europe.Contains(Point{Lat: -33.8688, Lng: 151.2093}) {
+		t.Fatalf("Expected Sydney not to be in Europe bounding box")
+	}
+
+	pacific, _ := ParseBbox("170,-50,-170,0")
+	if !pacific.Contains(Point{Lat: -17.7, Lng: 178}) ||
+		!pacific.Contains(Point{Lat: -14.3, Lng: -170.7}) {
+		t.Fatalf("Expected bounding box crossing the antimeridian to contain both sides")
+	}
+
+	for _, value := range []string{"1,2,3", "0,10,10,0", "0,0,200,10"} {
+		if _, err := ParseBbox(value); err == nil {
+			t.Fatalf("Expected error for bounding box %q", value)
+		}
+	}
+}
+
+func TestContinentOf(t *testing.T) {
+	for country, expected := range map[string]string{
+		"France":        "Europe",
+		"united states": "North America",
+		"Australia":     "Oceania",
+		"Nowhere":       "",
+	} {
+		if continent := ContinentOf(country); continent != expected {
+			t.Fatalf("Expected %s to be in %q, got %q", country, expected, continent)
+		}
+	}
+
+	if continent, ok := ParseContinent("south america"); !ok || continent != "South America" {
+		t.Fatalf("Expected continent to be parsed, got %q", continent)
+	}
+}
+
+func TestFormatDMS(t *testing.T) {
+	for _, tc := range []struct {
+		point    Point
+		expected string
+	}{
+		{Point{Lat: 48.8566, Lng: 2.3522}, `48°51'23.8"N 2°21'07.9"E`},
+		{Point{Lat: -33.8688, Lng: -70.5}, `33°52'07.7"S 70°30'00.0"W`},
+		{Point{Lat: 10.9999999, Lng: 0}, `11°00'00.0"N 0°00'00.0"E`},
+	} {
+		if dms := tc.point.FormatDMS(); dms != tc.expected {
+			t.Fatalf("Expected %+v to be formatted as %s, got %s", tc.point, tc.expected, dms)
+		}
+	}
+}
+
+func TestCoastlines(t *testing.T) {
+	shapes := Coastlines()
+	if len(shapes) == 0 {
+		t.Fatalf("Expected embedded coastlines")
+	}
+
+	for _, shape := range shapes {
+		if len(shape.Points) < 3 {
+			t.Fatalf("Expected shape %s to have at least 3 points, got %d", shape.Name, len(shape.Points))
+		}
+	}
+
+	if _, err := parseCoastlines("land 1,2 3"); err == nil {
+		t.Fatalf("Expected error for point without latitude")
+	}
+	if _, err := parseCoastlines("land 1,95"); err == nil {
+		t.Fatalf("Expected error for out of range latitude")
+	}
+}
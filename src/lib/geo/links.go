@@ -0,0 +1,74 @@
+/*
+Copyright © 2024 Nicolas Goudry <goudry.nicolas@gmail.com>
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package geo
+
+import (
+	"fmt"
+	"math"
+)
+
+// defaultZoom is the zoom level used for links when it is unknown
+const defaultZoom = 14
+
+// linkZoom returns the given zoom level, or the default one if it is unknown
+func linkZoom(zoom int) int {
+	if zoom <= 0 {
+		return defaultZoom
+	}
+
+	return zoom
+}
+
+// MapsLink returns a Google Maps satellite view link to the point at the given zoom level
+func MapsLink(p Point, zoom int) string {
+	return fmt.Sprintf(
+		"https://www.google.com/maps/@%.6f,%.6f,%dz/data=!3m1!1e3",
+		p.Lat,
+		p.Lng,
+		linkZoom(zoom),
+	)
+}
+
+// EarthLink returns a Google Earth link to the point, seen from a distance matching the zoom level
+func EarthLink(p Point, zoom int) string {
+	// The distance halves with each zoom level, from about the Earth circumference at level 0
+	distance := 40075016 / math.Pow(2, float64(linkZoom(zoom))) * 1.5
+
+	return fmt.Sprintf(
+		"https://earth.google.com/web/@%.6f,%.6f,0a,%.0fd,35y,0h,0t,0r",
+		p.Lat,
+		p.Lng,
+		distance,
+	)
+}
+
+// OsmLink returns an OpenStreetMap link with a marker on the point at the given zoom level
+func OsmLink(p Point, zoom int) string {
+	return fmt.Sprintf(
+		"https://www.openstreetmap.org/?mlat=%.6f&mlon=%.6f#map=%d/%.6f/%.6f",
+		p.Lat,
+		p.Lng,
+		min(linkZoom(zoom), 19),
+		p.Lat,
+		p.Lng,
+	)
+}
@@ -0,0 +1,191 @@
+/*
+Copyright © 2024 Nicolas Goudry <goudry.nicolas@gmail.com>
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package lib
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// HistoryEntry represents a background shown on the desktop
+type HistoryEntry struct {
+	Time    time.Time `json:"time"`
+	Id      int       `json:"id,omitempty"`
+	Path    string    `json:"path"`
+	Backend string    `json:"backend"`
+}
+
+// History is the log of shown backgrounds
+// Entries are stored as JSON lines in $XDG_STATE_HOME/earth-view/history.jsonl, along with the
+// position of the current entry which moves when navigating through history
+type History struct {
+	Entries  []HistoryEntry
+	Position int
+}
+
+// historyDir returns the directory holding history files
+func historyDir() string {
+	return filepath.Join(StateHome(), "earth-view")
+}
+
+// HistoryPath returns the path of the history log
+func HistoryPath() string {
+	return filepath.Join(historyDir(), "history.jsonl")
+}
+
+// historyPositionPath returns the path of the file holding the current history position
+func historyPositionPath() string {
+	return filepath.Join(historyDir(), "history.position")
+}
+
+// LoadHistory reads the history log and the current position
+// An empty history is returned if no background was shown yet
+func LoadHistory() (*History, error) {
+	h := &History{Position: -1}
+
+	file, err := os.Open(HistoryPath())
+	if errors.Is(err, os.ErrNotExist) {
+		return h, nil
+	} else if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	for line := 1; scanner.Scan(); line++ {
+		if strings.TrimSpace(scanner.Text()) == "" {
+			continue
+		}
+
+		var entry HistoryEntry
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+			return nil, fmt.Errorf("invalid history entry at line %d: %s", line, err)
+		}
+
+		h.Entries = append(h.Entries, entry)
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	h.Position = len(h.Entries) - 1
+
+	// The position is only saved after navigating, and is reset when a new entry is appended
+	if content, err := os.ReadFile(historyPositionPath()); err == nil {
+		position, err := strconv.Atoi(strings.TrimSpace(string(content)))
+		if err == nil && position >= 0 && position < len(h.Entries) {
+			h.Position = position
+		}
+	}
+
+	return h, nil
+}
+
+// Append logs a new shown background, which becomes the current entry
+func (h *History) Append(filePath string, backend string) error {
+	entry := HistoryEntry{
+		Time:    time.Now(),
+		Path:    filePath,
+		Backend: backend,
+	}
+	entry.Id, _ = IdFromPath(filePath)
+
+	line, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(historyDir(), 0700); err != nil {
+		return err
+	}
+
+	file, err := os.OpenFile(HistoryPath(), os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0600)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	if _, err := file.Write(append(line, '\n')); err != nil {
+		return err
+	}
+
+	h.Entries = append(h.Entries, entry)
+	h.Position = len(h.Entries) - 1
+
+	return h.savePosition()
+}
+
+// Current returns the current entry, if any
+func (h *History) Current() (HistoryEntry, bool) {
+	if h.Position < 0 || h.Position >= len(h.Entries) {
+		return HistoryEntry{}, false
+	}
+
+	return h.Entries[h.Position], true
+}
+
+// Move moves the current position by the given offset and returns the new current entry
+func (h *History) Move(offset int) (HistoryEntry, error) {
+	position := h.Position + offset
+
+	if position < 0 {
+		return HistoryEntry{}, fmt.Errorf("no previous background in history")
+	}
+
+	if position >= len(h.Entries) {
+		return HistoryEntry{}, fmt.Errorf("no next background in history")
+	}
+
+	h.Position = position
+	if err := h.savePosition(); err != nil {
+		return HistoryEntry{}, err
+	}
+
+	return h.Entries[h.Position], nil
+}
+
+// savePosition saves the current position
+func (h *History) savePosition() error {
+	if err := os.MkdirAll(historyDir(), 0700); err != nil {
+		return err
+	}
+
+	return os.WriteFile(historyPositionPath(), []byte(strconv.Itoa(h.Position)), 0600)
+}
+
+// RecordHistory logs a new shown background in history
+func RecordHistory(filePath string, backend string) error {
+	h, err := LoadHistory()
+	if err != nil {
+		return err
+	}
+
+	return h.Append(filePath, backend)
+}
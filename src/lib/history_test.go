@@ -0,0 +1,99 @@
+package lib
+
+import (
+	"os"
+	"testing"
+)
+
+func TestHistoryEmpty(t *testing.T) {
+	t.Setenv("XDG_STATE_HOME", t.TempDir())
+
+	history, err := LoadHistory()
+	if err != nil {
+		t.Fatalf("Expected empty history, got error: %v", err)
+	}
+
+	if _, ok := history.Current(); ok {
+		t.Fatal("Expected no current entry in empty history")
+	}
+
+	if _, err := history.Move(-1); err == nil {
+		t.Fatal("Expected error when moving in empty history, got success")
+	}
+}
+
+func TestHistoryNavigation(t *testing.T) {
+	t.Setenv("XDG_STATE_HOME", t.TempDir())
+
+	for _, filePath := range []string{"/tmp/1003.jpeg", "/tmp/1004.jpeg", "/tmp/custom.jpeg"} {
+		if err := RecordHistory(filePath, "feh"); err != nil {
+			t.Fatalf("Failed to record history: %v", err)
+		}
+	}
+
+	history, err := LoadHistory()
+	if err != nil {
+		t.Fatalf("Failed to load history: %v", err)
+	}
+
+	if len(history.Entries) != 3 || history.Position != 2 {
+		t.Fatalf(
+			"Expected 3 entries with last one current, got %d at %d",
+			len(history.Entries),
+			history.Position,
+		)
+	}
+
+	if history.Entries[0].Id != 1003 || history.Entries[2].Id != 0 {
+		t.Fatalf("Expected identifiers to be parsed from file names, got %+v", history.Entries)
+	}
+
+	if _, err := history.Move(1); err == nil {
+		t.Fatal("Expected error when moving past last entry, got success")
+	}
+
+	entry, err := history.Move(-2)
+	if err != nil || entry.Path != "/tmp/1003.jpeg" {
+		t.Fatalf("Expected first entry, got %+v (error: %v)", entry, err)
+	}
+
+	// Position is persisted
+	history, err = LoadHistory()
+	if err != nil {
+		t.Fatalf("Failed to load history: %v", err)
+	}
+
+	if entry, _ := history.Current(); entry.Path != "/tmp/1003.jpeg" {
+		t.Fatalf("Expected persisted position on first entry, got %+v", entry)
+	}
+
+	// Appending resets position to the new entry
+	if err := history.Append("/tmp/1006.jpeg", "gnome"); err != nil {
+		t.Fatalf("Failed to record history: %v", err)
+	}
+
+	history, err = LoadHistory()
+	if err != nil {
+		t.Fatalf("Failed to load history: %v", err)
+	}
+
+	if entry, _ := history.Current(); entry.Path != "/tmp/1006.jpeg" || entry.Backend != "gnome" {
+		t.Fatalf("Expected appended entry to be current, got %+v", entry)
+	}
+}
+
+func TestHistoryInvalidEntry(t *testing.T) {
+	t.Setenv("XDG_STATE_HOME", t.TempDir())
+
+	if err := os.MkdirAll(historyDir(), 0700); err != nil {
+		t.Fatalf("Failed to prepare history: %v", err)
+	}
+
+	if err := os.WriteFile(HistoryPath(), []byte("{\"path\":\"/tmp/1003.jpeg\"}\nnot json\n"), 0600); err != nil {
+		t.Fatalf("Failed to prepare history: %v", err)
+	}
+
+	if _, err := LoadHistory(); err == nil {
+		t.Fatal("Expected error, got success")
+	}
+}
@@ -0,0 +1,180 @@
+/*
+Copyright © 2024 Nicolas Goudry <goudry.nicolas@gmail.com>
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package imaging
+
+import (
+	"image"
+	"math"
+
+	"golang.org/x/image/draw"
+)
+
+// gaussianPasses is the number of box blurs approximating a Gaussian blur
+const gaussianPasses = 3
+
+// toRGBA returns a copy of the image with its top-left corner at the origin
+func toRGBA(img image.Image) *image.RGBA {
+	bounds := img.Bounds()
+	dst := image.NewRGBA(image.Rect(0, 0, bounds.Dx(), bounds.Dy()))
+	draw.Draw(dst, dst.Bounds(), img, bounds.Min, draw.Src)
+
+	return dst
+}
+
+// BoxBlur returns a copy of the image where each pixel is the mean of the pixels within the given
+// radius, edge pixels being repeated beyond the image bounds
+func BoxBlur(img image.Image, radius int) *image.RGBA {
+	dst := toRGBA(img)
+	if radius <= 0 {
+		return dst
+	}
+
+	tmp := image.NewRGBA(dst.Rect)
+	boxBlurPass(tmp, dst, radius, true)
+	boxBlurPass(dst, tmp, radius, false)
+
+	return dst
+}
+
+// GaussianBlur returns a copy of the image blurred with a Gaussian kernel of the given standard
+// deviation in pixels
+// The kernel is approximated by successive box blurs, which run in constant time per pixel
+// whatever the deviation
+func GaussianBlur(img image.Image, sigma float64) *image.RGBA {
+	dst := toRGBA(img)
+	if sigma <= 0 {
+		return dst
+	}
+
+	tmp := image.NewRGBA(dst.Rect)
+	for _, radius := range gaussianBoxes(sigma, gaussianPasses) {
+		boxBlurPass(tmp, dst, radius, true)
+		boxBlurPass(dst, tmp, radius, false)
+	}
+
+	return dst
+}
+
+// gaussianBoxes returns the radii of the box blurs whose succession approximates a Gaussian blur
+// of the given standard deviation, following 'Fast Almost-Gaussian Filtering' by Peter Kovesi
+func gaussianBoxes(sigma float64, passes int) []int {
+	n := float64(passes)
+
+	// Widths of the boxes are consecutive odd numbers around the ideal width
+	lower := int(math.Floor(math.Sqrt(12*sigma*sigma/n + 1)))
+	if lower%2 == 0 {
+		lower--
+	}
+	l := float64(lower)
+
+	smaller := int(math.Round((12*sigma*sigma - n*l*l - 4*n*l - 3*n) / (-4*l - 4)))
+
+	radii := make([]int, passes)
+	for i := range radii {
+		width := lower
+		if i >= smaller {
+			width += 2
+		}
+
+		radii[i] = (width - 1) / 2
+	}
+
+	return radii
+}
+
+// boxBlurPass sets each pixel of dst to the mean of the pixels of src within the radius along one
+// axis, edge pixels being repeated beyond the image bounds
+// Both images must have the same bounds, with their top-left corner at the origin
+func boxBlurPass(dst *image.RGBA, src *image.RGBA, radius int, horizontal bool) {
+	length, lines := src.Rect.Dx(), src.Rect.Dy()
+	step, lineStep := 4, src.Stride
+	if !horizontal {
+		length, lines = lines, length
+		step, lineStep = lineStep, step
+	}
+
+	window := 2*radius + 1
+	for line := 0; line < lines; line++ {
+		start := line * lineStep
+
+		// The sum of the window is updated as it slides along the line
+		var sum [4]int
+		for i := -radius; i <= radius; i++ {
+			offset := start + min(max(i, 0), length-1)*step
+			for c := 0; c < 4; c++ {
+				sum[c] += int(src.Pix[offset+c])
+			}
+		}
+
+		for i := 0; i < length; i++ {
+			offset := start + i*step
+			for c := 0; c < 4; c++ {
+				dst.Pix[offset+c] = uint8((sum[c] + window/2) / window)
+			}
+
+			added := start + min(i+radius+1, length-1)*step
+			removed := start + max(i-radius, 0)*step
+			for c := 0; c < 4; c++ {
+				sum[c] += int(src.Pix[added+c]) - int(src.Pix[removed+c])
+			}
+		}
+	}
+}
+
+// Dim darkens the image in place by the given amount, from 0 (unchanged) to 1 (black)
+func Dim(img *image.RGBA, amount float64) {
+	factor := 1 - math.Min(math.Max(amount, 0), 1)
+
+	for y := img.Rect.Min.Y; y < img.Rect.Max.Y; y++ {
+		row := img.Pix[img.PixOffset(img.Rect.Min.X, y):img.PixOffset(img.Rect.Max.X, y)]
+		for i := 0; i < len(row); i += 4 {
+			for c := 0; c < 3; c++ {
+				row[i+c] = uint8(math.Round(float64(row[i+c]) * factor))
+			}
+		}
+	}
+}
+
+// Vignette darkens the edges of the image in place, increasingly with the distance from its
+// center, by up to the given strength in its corners, from 0 (unchanged) to 1 (black)
+func Vignette(img *image.RGBA, strength float64) {
+	strength = math.Min(math.Max(strength, 0), 1)
+
+	center := image.Pt(img.Rect.Min.X+img.Rect.Dx()/2, img.Rect.Min.Y+img.Rect.Dy()/2)
+	halfWidth, halfHeight := float64(img.Rect.Dx())/2, float64(img.Rect.Dy())/2
+
+	for y := img.Rect.Min.Y; y < img.Rect.Max.Y; y++ {
+		dy := float64(y-center.Y) / halfHeight
+
+		for x := img.Rect.Min.X; x < img.Rect.Max.X; x++ {
+			dx := float64(x-center.X) / halfWidth
+
+			// The squared distance is 0 in the center and 2 in the corners
+			factor := 1 - strength*(dx*dx+dy*dy)/2
+
+			offset := img.PixOffset(x, y)
+			for c := 0; c < 3; c++ {
+				img.Pix[offset+c] = uint8(math.Round(float64(img.Pix[offset+c]) * factor))
+			}
+		}
+	}
+}
@@ -0,0 +1,101 @@
+package imaging
+
+import (
+	"image"
+	"image/color"
+	"math"
+	"testing"
+)
+
+// stripes returns an image of alternating black and white vertical stripes of one pixel
+func stripes() *image.RGBA {
+	img := image.NewRGBA(image.Rect(10, 10, 110, 60))
+	for y := 10; y < 60; y++ {
+		for x := 10; x < 110; x++ {
+			if x%2 == 0 {
+				img.Set(x, y, color.White)
+			} else {
+				img.Set(x, y, color.Black)
+			}
+		}
+	}
+
+	return img
+}
+
+func TestBoxBlur(t *testing.T) {
+	img := BoxBlur(stripes(), 4)
+	if bounds := img.Bounds(); bounds != image.Rect(0, 0, 100, 50) {
+		t.Fatalf("Expected bounds at the origin, got %v", bounds)
+	}
+
+	// A window of 9 pixels holds 4 or 5 white stripes
+	for _, x := range []int{20, 21} {
+		if c := img.RGBAAt(x, 25); math.Abs(float64(c.R)-127.5) > 15 || c.A != 0xff {
+			t.Fatalf("Expected gray at (%d,25), got %v", x, c)
+		}
+	}
+
+	if c := BoxBlur(stripes(), 0).RGBAAt(0, 0); c.R != 0xff {
+		t.Fatalf("Expected image to be unchanged without radius, got %v", c)
+	}
+}
+
+func TestGaussianBlur(t *testing.T) {
+	// Edge pixels repeated beyond the image bounds bias the edges
+	img := GaussianBlur(stripes(), 5)
+	for x := 20; x < 80; x++ {
+		if c := img.RGBAAt(x, 25); math.Abs(float64(c.R)-127.5) > 4 {
+			t.Fatalf("Expected stripes to be blurred to gray at (%d,25), got %v", x, c)
+		}
+	}
+}
+
+func TestGaussianBoxes(t *testing.T) {
+	for _, sigma := range []float64{1, 5, 24} {
+		radii := gaussianBoxes(sigma, 3)
+
+		// The variance of a box of width w is (w^2-1)/12, and variances add up
+		var variance float64
+		for _, radius := range radii {
+			width := float64(2*radius + 1)
+			variance += (width*width - 1) / 12
+		}
+
+		if math.Abs(math.Sqrt(variance)-sigma) > 0.5 {
+			t.Fatalf(
+				"Expected boxes %v to approximate deviation %g, got %g",
+				radii,
+				sigma,
+				math.Sqrt(variance),
+			)
+		}
+	}
+}
+
+func TestDimAndVignette(t *testing.T) {
+	img := image.NewRGBA(image.Rect(0, 0, 101, 101))
+	for y := 0; y < 101; y++ {
+		for x := 0; x < 101; x++ {
+			img.Set(x, y, color.RGBA{R: 200, G: 100, B: 50, A: 0xff})
+		}
+	}
+
+	Dim(img, 0.5)
+	if c := img.RGBAAt(50, 50); c != (color.RGBA{R: 100, G: 50, B: 25, A: 0xff}) {
+		t.Fatalf("Expected colors to be halved, got %v", c)
+	}
+
+	Vignette(img, 1)
+	if c := img.RGBAAt(50, 50); c.R != 100 {
+		t.Fatalf("Expected center to be unchanged, got %v", c)
+	}
+
+	if c := img.RGBAAt(0, 50); c.R < 50 || c.R > 52 {
+		t.Fatalf("Expected edge to be darkened by half, got %v", c)
+	}
+
+	if c := img.RGBAAt(0, 0); c.R > 2 || c.A != 0xff {
+		t.Fatalf("Expected corner to be black, got %v", c)
+	}
+}
@@ -0,0 +1,150 @@
+/*
+Copyright © 2024 Nicolas Goudry <goudry.nicolas@gmail.com>
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package imaging
+
+import (
+	"image"
+	"math"
+
+	"golang.org/x/image/draw"
+)
+
+const (
+	// focusSize is the size of the largest side of the copy of images searched for their focus
+	focusSize = 128
+	// focusBins is the number of luminance levels counted when measuring entropy
+	focusBins = 32
+)
+
+// FocusCrop returns the part of the bounds with the given aspect ratio holding the most details,
+// found by trimming the edges whose luminance has the least entropy
+// The crop only slides along one axis, and stays centered when details are evenly spread
+func FocusCrop(img image.Image, width int, height int) image.Rectangle {
+	bounds := img.Bounds()
+	crop := CenterCrop(bounds, width, height)
+
+	slack := bounds.Dx() - crop.Dx()
+	horizontal := slack > 0
+	if !horizontal {
+		slack = bounds.Dy() - crop.Dy()
+	}
+
+	if slack == 0 {
+		return crop
+	}
+
+	// Measuring a small grayscale copy is fast and ignores noise
+	scale := float64(focusSize) / float64(max(bounds.Dx(), bounds.Dy()))
+	small := image.NewGray(image.Rect(
+		0,
+		0,
+		max(int(math.Round(float64(bounds.Dx())*scale)), 1),
+		max(int(math.Round(float64(bounds.Dy())*scale)), 1),
+	))
+	draw.ApproxBiLinear.Scale(small, small.Bounds(), img, bounds, draw.Src, nil)
+
+	window := image.Rect(
+		0,
+		0,
+		min(max(int(math.Round(float64(crop.Dx())*scale)), 1), small.Rect.Dx()),
+		min(max(int(math.Round(float64(crop.Dy())*scale)), 1), small.Rect.Dy()),
+	)
+
+	// Strips of the copy with the least entropy are trimmed from either end until the crop fits
+	lo, hi, length := 0, small.Rect.Dy(), window.Dy()
+	strip := image.Rect(0, 0, small.Rect.Dx(), 1)
+	step := image.Pt(0, 1)
+	if horizontal {
+		lo, hi, length = 0, small.Rect.Dx(), window.Dx()
+		strip = image.Rect(0, 0, 1, small.Rect.Dy())
+		step = image.Pt(1, 0)
+	}
+
+	steps := hi - length
+	focused := false
+
+	for hi-lo > length {
+		first := entropy(small, strip.Add(step.Mul(lo)))
+		last := entropy(small, strip.Add(step.Mul(hi-1)))
+
+		switch {
+		case first < last-1e-9:
+			lo++
+			focused = true
+		case last < first-1e-9:
+			hi--
+			focused = true
+		// Ties are resolved in favor of the center
+		case lo <= steps-(hi-length):
+			lo++
+		default:
+			hi--
+		}
+	}
+
+	if !focused || steps == 0 {
+		return crop
+	}
+
+	shift := int(math.Round(float64(lo) / float64(steps) * float64(slack)))
+	min := bounds.Min.Add(step.Mul(shift))
+
+	return image.Rectangle{Min: min, Max: min.Add(crop.Size())}
+}
+
+// entropy returns the Shannon entropy of the luminance of the area of a grayscale image, in bits
+func entropy(img *image.Gray, area image.Rectangle) float64 {
+	var histogram [focusBins]int
+	for y := area.Min.Y; y < area.Max.Y; y++ {
+		for x := area.Min.X; x < area.Max.X; x++ {
+			histogram[int(img.GrayAt(x, y).Y)*focusBins/256]++
+		}
+	}
+
+	total := float64(area.Dx() * area.Dy())
+
+	var e float64
+	for _, count := range histogram {
+		if count > 0 {
+			p := float64(count) / total
+			e -= p * math.Log2(p)
+		}
+	}
+
+	return e
+}
+
+// FocusFill scales an image to the given size, cropping it around its most detailed part to keep
+// its aspect ratio
+func FocusFill(img image.Image, width int, height int, interpolator draw.Interpolator) *image.RGBA {
+	dst := image.NewRGBA(image.Rect(0, 0, width, height))
+	interpolator.Scale(
+		dst,
+		dst.Bounds(),
+		img,
+		FocusCrop(img, width, height),
+		draw.Src,
+		nil,
+	)
+
+	return dst
+}
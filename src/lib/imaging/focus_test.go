@@ -0,0 +1,47 @@
+package imaging
+
+import (
+	"image"
+	"image/color"
+	"testing"
+)
+
+func TestFocusCrop(t *testing.T) {
+	// A plain image is cropped in its center
+	plain := image.NewRGBA(image.Rect(0, 0, 1800, 1200))
+	if crop := FocusCrop(plain, 1920, 1080); crop != CenterCrop(plain.Bounds(), 1920, 1080) {
+		t.Fatalf("Expected centered crop for plain image, got %v", crop)
+	}
+
+	// Details of the right third are kept in frame
+	detailed := image.NewRGBA(image.Rect(0, 0, 1800, 600))
+	for y := 0; y < 600; y++ {
+		for x := 1200; x < 1800; x++ {
+			detailed.Set(x, y, color.Gray{Y: uint8((x*7 + y*13) % 256)})
+		}
+	}
+
+	crop := FocusCrop(detailed, 1, 1)
+	if crop != image.Rect(1200, 0, 1800, 600) {
+		t.Fatalf("Expected crop of the detailed part, got %v", crop)
+	}
+
+	// Portrait crops slide vertically
+	tall := image.NewRGBA(image.Rect(0, 0, 400, 1200))
+	for y := 0; y < 300; y++ {
+		for x := 0; x < 400; x++ {
+			tall.Set(x, y, color.Gray{Y: uint8((x*7 + y*13) % 256)})
+		}
+	}
+
+	if crop := FocusCrop(tall, 2, 1); crop.Max.Y > 300 || crop.Dx() != 400 || crop.Dy() != 200 {
+		t.Fatalf("Expected crop of the detailed top of the image, got %v", crop)
+	}
+}
+
+func TestFocusFill(t *testing.T) {
+	img := FocusFill(image.NewRGBA(image.Rect(0, 0, 1800, 1200)), 2560, 1080, Lanczos)
+	if bounds := img.Bounds(); bounds.Dx() != 2560 || bounds.Dy() != 1080 {
+		t.Fatalf("Expected image to be scaled to 2560x1080, got %dx%d", bounds.Dx(), bounds.Dy())
+	}
+}
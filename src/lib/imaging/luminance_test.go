@@ -0,0 +1,37 @@
+package imaging
+
+import (
+	"image"
+	"image/color"
+	"math"
+	"testing"
+)
+
+func TestMeanLuminance(t *testing.T) {
+	for _, tc := range []struct {
+		color color.Color
+		want  float64
+	}{
+		{color.Black, 0},
+		{color.White, 1},
+		{color.RGBA{R: 255, A: 255}, 0.2126},
+		{color.RGBA{G: 255, A: 255}, 0.7152},
+	} {
+		img := image.NewRGBA(image.Rect(0, 0, 16, 16))
+		for y := 0; y < 16; y++ {
+			for x := 0; x < 16; x++ {
+				img.Set(x, y, tc.color)
+			}
+		}
+
+		if got := MeanLuminance(img); math.Abs(got-tc.want) > 0.001 {
+			t.Fatalf("Expected luminance %f for color %v, got %f", tc.want, tc.color, got)
+		}
+	}
+}
+
+func TestMeanLuminanceEmpty(t *testing.T) {
+	if got := MeanLuminance(image.NewRGBA(image.Rectangle{})); got != 0 {
+		t.Fatalf("Expected zero luminance for empty image, got %f", got)
+	}
+}
@@ -0,0 +1,143 @@
+/*
+Copyright © 2024 Nicolas Goudry <goudry.nicolas@gmail.com>
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package imaging
+
+import (
+	"fmt"
+	"image"
+	"math"
+	"strconv"
+	"strings"
+
+	"golang.org/x/image/draw"
+)
+
+var (
+	// Lanczos is the Lanczos resampling kernel with 3 lobes, which gives the sharpest results
+	Lanczos = &draw.Kernel{Support: 3, At: lanczos3}
+
+	// Interpolators lists the resampling methods by name
+	Interpolators = map[string]draw.Interpolator{
+		"nearest":    draw.NearestNeighbor,
+		"bilinear":   draw.BiLinear,
+		"catmullrom": draw.CatmullRom,
+		"lanczos":    Lanczos,
+	}
+
+	// InterpolatorNames lists the names of resampling methods, from the fastest to the sharpest
+	InterpolatorNames = []string{"nearest", "bilinear", "catmullrom", "lanczos"}
+
+	// DefaultInterpolator is a good trade-off between speed and sharpness
+	DefaultInterpolator draw.Interpolator = draw.CatmullRom
+)
+
+// lanczos3 is the Lanczos kernel function with 3 lobes, for t in [0, 3)
+func lanczos3(t float64) float64 {
+	if t == 0 {
+		return 1
+	}
+
+	x := math.Pi * t
+
+	return 3 * math.Sin(x) * math.Sin(x/3) / (x * x)
+}
+
+// ParseInterpolator returns the resampling method with the given name
+func ParseInterpolator(name string) (draw.Interpolator, error) {
+	interpolator, ok := Interpolators[strings.ToLower(name)]
+	if !ok {
+		return nil, fmt.Errorf(
+			"invalid resampling method: %s. Expected one of %s",
+			name,
+			strings.Join(InterpolatorNames, ", "),
+		)
+	}
+
+	return interpolator, nil
+}
+
+// ParseSize parses a size in pixels formatted as 'WxH'
+func ParseSize(value string) (int, int, error) {
+	w, h, ok := strings.Cut(strings.ToLower(value), "x")
+	width, widthErr := strconv.Atoi(w)
+	height, heightErr := strconv.Atoi(h)
+
+	if !ok || widthErr != nil || heightErr != nil || width <= 0 || height <= 0 {
+		return 0, 0, fmt.Errorf("invalid size: %s. Expected 'WxH' in pixels, e.g. '480x320'", value)
+	}
+
+	return width, height, nil
+}
+
+// FitSize returns the largest size with the aspect ratio of the given bounds fitting in the given
+// maximum width and height, never enlarging
+func FitSize(bounds image.Rectangle, maxWidth int, maxHeight int) (int, int) {
+	width, height := bounds.Dx(), bounds.Dy()
+	if width <= maxWidth && height <= maxHeight {
+		return width, height
+	}
+
+	if width*maxHeight > height*maxWidth {
+		return maxWidth, max(height*maxWidth/width, 1)
+	}
+
+	return max(width*maxHeight/height, 1), maxHeight
+}
+
+// Fit scales an image down to fit in the given maximum width and height, keeping its aspect ratio
+func Fit(img image.Image, maxWidth int, maxHeight int, interpolator draw.Interpolator) *image.RGBA {
+	width, height := FitSize(img.Bounds(), maxWidth, maxHeight)
+
+	dst := image.NewRGBA(image.Rect(0, 0, width, height))
+	interpolator.Scale(dst, dst.Bounds(), img, img.Bounds(), draw.Src, nil)
+
+	return dst
+}
+
+// CenterCrop returns the largest centered part of the bounds with the given aspect ratio
+func CenterCrop(bounds image.Rectangle, width int, height int) image.Rectangle {
+	cropWidth, cropHeight := bounds.Dx(), bounds.Dy()
+	if cropWidth*height > cropHeight*width {
+		cropWidth = max(cropHeight*width/height, 1)
+	} else {
+		cropHeight = max(cropWidth*height/width, 1)
+	}
+
+	min := bounds.Min.Add(image.Pt((bounds.Dx()-cropWidth)/2, (bounds.Dy()-cropHeight)/2))
+
+	return image.Rectangle{Min: min, Max: min.Add(image.Pt(cropWidth, cropHeight))}
+}
+
+// Fill scales an image to the given size, cropping its center to keep its aspect ratio
+func Fill(img image.Image, width int, height int, interpolator draw.Interpolator) *image.RGBA {
+	dst := image.NewRGBA(image.Rect(0, 0, width, height))
+	interpolator.Scale(
+		dst,
+		dst.Bounds(),
+		img,
+		CenterCrop(img.Bounds(), width, height),
+		draw.Src,
+		nil,
+	)
+
+	return dst
+}
@@ -0,0 +1,100 @@
+package imaging
+
+import (
+	"image"
+	"image/color"
+	"math"
+	"testing"
+)
+
+func TestFitSize(t *testing.T) {
+	for _, tc := range []struct {
+		width, height         int
+		wantWidth, wantHeight int
+	}{
+		{1800, 1200, 480, 320},
+		{1200, 1800, 320, 480},
+		{1000, 100, 480, 48},
+		{200, 100, 200, 100},
+	} {
+		width, height := FitSize(image.Rect(0, 0, tc.width, tc.height), 480, 480)
+		if width != tc.wantWidth || height != tc.wantHeight {
+			t.Fatalf(
+				"Expected %dx%d to fit in %dx%d, got %dx%d",
+				tc.width, tc.height, tc.wantWidth, tc.wantHeight, width, height,
+			)
+		}
+	}
+}
+
+func TestFit(t *testing.T) {
+	img := Fit(image.NewRGBA(image.Rect(0, 0, 1800, 1200)), 300, 300, Lanczos)
+	if bounds := img.Bounds(); bounds.Dx() != 300 || bounds.Dy() != 200 {
+		t.Fatalf("Expected image to be scaled to 300x200, got %dx%d", bounds.Dx(), bounds.Dy())
+	}
+}
+
+func TestFill(t *testing.T) {
+	// Left and right quarters are red, the center is green
+	src := image.NewRGBA(image.Rect(0, 0, 400, 100))
+	for y := 0; y < 100; y++ {
+		for x := 0; x < 400; x++ {
+			c := color.RGBA{R: 255, A: 255}
+			if x >= 100 && x < 300 {
+				c = color.RGBA{G: 255, A: 255}
+			}
+			src.Set(x, y, c)
+		}
+	}
+
+	img := Fill(src, 50, 50, DefaultInterpolator)
+	if bounds := img.Bounds(); bounds.Dx() != 50 || bounds.Dy() != 50 {
+		t.Fatalf("Expected image to be scaled to 50x50, got %dx%d", bounds.Dx(), bounds.Dy())
+	}
+
+	if c := img.RGBAAt(2, 25); c.R != 0 || c.G != 255 {
+		t.Fatalf("Expected center of the image to be kept, got %v", c)
+	}
+}
+
+func TestCenterCrop(t *testing.T) {
+	crop := CenterCrop(image.Rect(0, 0, 1800, 1200), 1, 1)
+	if crop != image.Rect(300, 0, 1500, 1200) {
+		t.Fatalf("Expected centered square crop, got %v", crop)
+	}
+
+	crop = CenterCrop(image.Rect(0, 0, 1800, 1200), 3, 1)
+	if crop != image.Rect(0, 300, 1800, 900) {
+		t.Fatalf("Expected centered wide crop, got %v", crop)
+	}
+}
+
+func TestLanczos(t *testing.T) {
+	if lanczos3(0) != 1 || math.Abs(lanczos3(1)) > 1e-9 || math.Abs(lanczos3(2)) > 1e-9 {
+		t.Fatalf("Expected Lanczos kernel to interpolate sample points")
+	}
+}
+
+func TestParseSize(t *testing.T) {
+	if width, height, err := ParseSize("480x320"); err != nil || width != 480 || height != 320 {
+		t.Fatalf("Expected size 480x320, got %dx%d (%v)", width, height, err)
+	}
+
+	for _, value := range []string{"", "480", "x320", "480x", "0x320", "-480x320", "axb"} {
+		if _, _, err := ParseSize(value); err == nil {
+			t.Fatalf("Expected error for size %q", value)
+		}
+	}
+}
+
+func TestParseInterpolator(t *testing.T) {
+	for _, name := range InterpolatorNames {
+		if _, err := ParseInterpolator(name); err != nil {
+			t.Fatalf("Expected resampling method %s to be valid, got error: %s", name, err)
+		}
+	}
+
+	if _, err := ParseInterpolator("bicubic"); err == nil {
+		t.Fatalf("Expected error for unknown resampling method")
+	}
+}
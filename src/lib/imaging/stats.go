@@ -0,0 +1,191 @@
+/*
+Copyright © 2024 Nicolas Goudry <goudry.nicolas@gmail.com>
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package imaging
+
+import (
+	"image"
+	"math"
+)
+
+const (
+	// hueBins is the number of bins of the hue histogram, of 10 degrees each
+	hueBins = 36
+	// minHueShare is the share of pixels, weighted by their saturation, under which an image has
+	// no dominant hue
+	minHueShare = 0.05
+	// hazeLuminance and hazeSaturation are the thresholds of bright and pale pixels, typical of haze
+	// and clouds
+	hazeLuminance  = 0.6
+	hazeSaturation = 0.15
+)
+
+// Gray is the hue of images without enough saturated pixels to have a dominant hue
+const Gray = "gray"
+
+// hueRanges maps hue names to the upper bound of their range in degrees, in increasing order
+// Red wraps around 0 degrees
+var hueRanges = []struct {
+	name string
+	max  float64
+}{
+	{"red", 15},
+	{"orange", 45},
+	{"yellow", 70},
+	{"green", 165},
+	{"cyan", 195},
+	{"blue", 255},
+	{"purple", 290},
+	{"pink", 345},
+	{"red", 360},
+}
+
+// HueNames lists the names of dominant hues
+var HueNames = []string{"red", "orange", "yellow", "green", "cyan", "blue", "purple", "pink", Gray}
+
+// Stats holds measures of an image, used to filter out images which make poor backgrounds
+type Stats struct {
+	// Brightness is the mean luminance, between 0 (black) and 1 (white)
+	Brightness float64 `json:"brightness"`
+	// Contrast is the standard deviation of the luminance, between 0 (plain) and 0.5
+	Contrast float64 `json:"contrast"`
+	// Colorfulness is the metric of Hasler and Süsstrunk, from 0 (grayscale) to above 100 (very
+	// colorful)
+	Colorfulness float64 `json:"colorfulness"`
+	// Hue is the name of the most common hue among saturated pixels
+	Hue string `json:"hue"`
+	// Haze is the share of bright and pale pixels, typical of haze and clouds, between 0 and 1
+	Haze float64 `json:"haze"`
+}
+
+// HueName returns the name of the hue at the given angle in degrees
+func HueName(hue float64) string {
+	hue = math.Mod(math.Mod(hue, 360)+360, 360)
+	for _, r := range hueRanges {
+		if hue < r.max {
+			return r.name
+		}
+	}
+
+	return "red"
+}
+
+// Measure computes the statistics of an image from a sparse sampling of its pixels
+func Measure(img image.Image) Stats {
+	bounds := img.Bounds()
+
+	var count, hazy float64
+	var sumLuma, sumLuma2 float64
+	var sumRg, sumRg2, sumYb, sumYb2 float64
+	var hues [hueBins]float64
+
+	for y := bounds.Min.Y; y < bounds.Max.Y; y += sampleStep {
+		for x := bounds.Min.X; x < bounds.Max.X; x += sampleStep {
+			r, g, b, _ := img.At(x, y).RGBA()
+			count++
+
+			luma := luminance(r, g, b)
+			sumLuma += luma
+			sumLuma2 += luma * luma
+
+			// Opponent color components, on the 0-255 scale of the colorfulness metric
+			rf, gf, bf := float64(r>>8), float64(g>>8), float64(b>>8)
+			rg, yb := rf-gf, (rf+gf)/2-bf
+			sumRg += rg
+			sumRg2 += rg * rg
+			sumYb += yb
+			sumYb2 += yb * yb
+
+			hue, saturation, value := hsv(rf/0xff, gf/0xff, bf/0xff)
+			if luma > hazeLuminance && saturation < hazeSaturation {
+				hazy++
+			}
+
+			// Dark pixels have unreliable hues
+			if saturation > 0.2 && value > 0.15 {
+				hues[int(hue/360*hueBins)%hueBins] += saturation
+			}
+		}
+	}
+
+	if count == 0 {
+		return Stats{Hue: Gray}
+	}
+
+	meanLuma := sumLuma / count
+	meanRg, meanYb := sumRg/count, sumYb/count
+	varRg, varYb := sumRg2/count-meanRg*meanRg, sumYb2/count-meanYb*meanYb
+
+	stats := Stats{
+		Brightness: round(meanLuma),
+		Contrast:   round(math.Sqrt(math.Max(sumLuma2/count-meanLuma*meanLuma, 0))),
+		Colorfulness: round(
+			math.Sqrt(math.Max(varRg+varYb, 0)) + 0.3*math.Sqrt(meanRg*meanRg+meanYb*meanYb),
+		),
+		Hue:  Gray,
+		Haze: round(hazy / count),
+	}
+
+	var total float64
+	best := 0
+	for i, weight := range hues {
+		total += weight
+		if weight > hues[best] {
+			best = i
+		}
+	}
+
+	if total/count >= minHueShare {
+		stats.Hue = HueName((float64(best) + 0.5) * 360 / hueBins)
+	}
+
+	return stats
+}
+
+// hsv converts color channels between 0 and 1 to a hue in degrees, a saturation and a value
+func hsv(r float64, g float64, b float64) (float64, float64, float64) {
+	high, low := math.Max(r, math.Max(g, b)), math.Min(r, math.Min(g, b))
+	if high == 0 {
+		return 0, 0, 0
+	}
+
+	delta := high - low
+	if delta == 0 {
+		return 0, 0, high
+	}
+
+	var hue float64
+	switch high {
+	case r:
+		hue = math.Mod((g-b)/delta, 6)
+	case g:
+		hue = (b-r)/delta + 2
+	default:
+		hue = (r-g)/delta + 4
+	}
+
+	return math.Mod(hue*60+360, 360), delta / high, high
+}
+
+// round rounds statistics to 4 decimals, which is more than their accuracy
+func round(value float64) float64 {
+	return math.Round(value*1e4) / 1e4
+}
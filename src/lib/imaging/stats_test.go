@@ -0,0 +1,63 @@
+package imaging
+
+import (
+	"image"
+	"image/color"
+	"testing"
+)
+
+// plain returns an image of a single color
+func plain(c color.Color) *image.RGBA {
+	img := image.NewRGBA(image.Rect(0, 0, 40, 40))
+	for y := 0; y < 40; y++ {
+		for x := 0; x < 40; x++ {
+			img.Set(x, y, c)
+		}
+	}
+
+	return img
+}
+
+func TestHueName(t *testing.T) {
+	for hue, expected := range map[float64]string{
+		0:   "red",
+		359: "red",
+		-10: "red",
+		30:  "orange",
+		120: "green",
+		220: "blue",
+		300: "pink",
+	} {
+		if name := HueName(hue); name != expected {
+			t.Fatalf("Expected %s for hue %g, got %s", expected, hue, name)
+		}
+	}
+}
+
+func TestMeasure(t *testing.T) {
+	sea := Measure(plain(color.RGBA{R: 20, G: 60, B: 140, A: 0xff}))
+	if sea.Hue != "blue" || sea.Contrast != 0 || sea.Haze != 0 || sea.Colorfulness < 20 {
+		t.Fatalf("Expected plain colorful blue image, got %+v", sea)
+	}
+
+	clouds := Measure(plain(color.RGBA{R: 220, G: 220, B: 225, A: 0xff}))
+	if clouds.Hue != Gray || clouds.Haze != 1 || clouds.Colorfulness > 5 {
+		t.Fatalf("Expected pale hazy image, got %+v", clouds)
+	}
+
+	if clouds.Brightness <= sea.Brightness {
+		t.Fatalf("Expected clouds to be brighter than sea, got %+v and %+v", clouds, sea)
+	}
+
+	// Half black and half white
+	img := plain(color.Black)
+	for y := 0; y < 40; y++ {
+		for x := 0; x < 20; x++ {
+			img.Set(x, y, color.White)
+		}
+	}
+
+	if stats := Measure(img); stats.Brightness != 0.5 || stats.Contrast != 0.5 {
+		t.Fatalf("Expected brightness and contrast of 0.5, got %+v", stats)
+	}
+}
@@ -0,0 +1,93 @@
+/*
+Copyright © 2024 Nicolas Goudry <goudry.nicolas@gmail.com>
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package imaging
+
+import (
+	"image"
+	"image/color"
+	"strings"
+	"sync"
+
+	"golang.org/x/image/draw"
+	"golang.org/x/image/font"
+	"golang.org/x/image/font/gofont/goregular"
+	"golang.org/x/image/font/opentype"
+	"golang.org/x/image/math/fixed"
+)
+
+var (
+	regularOnce sync.Once
+	regular     *opentype.Font
+	regularErr  error
+)
+
+// NewFace returns a face of the embedded Go regular font at the given size in pixels
+// Faces must not be used concurrently
+func NewFace(size float64) (font.Face, error) {
+	regularOnce.Do(func() {
+		regular, regularErr = opentype.Parse(goregular.TTF)
+	})
+	if regularErr != nil {
+		return nil, regularErr
+	}
+
+	return opentype.NewFace(regular, &opentype.FaceOptions{
+		Size:    size,
+		DPI:     72,
+		Hinting: font.HintingFull,
+	})
+}
+
+// TextWidth returns the width of the text drawn with the face, in pixels
+func TextWidth(face font.Face, text string) int {
+	return font.MeasureString(face, text).Ceil()
+}
+
+// Ellipsize shortens the text with an ellipsis so that it fits in the given width in pixels
+func Ellipsize(face font.Face, text string, maxWidth int) string {
+	if TextWidth(face, text) <= maxWidth {
+		return text
+	}
+
+	runes := []rune(strings.TrimSpace(text))
+	for len(runes) > 0 {
+		runes = runes[:len(runes)-1]
+		shortened := strings.TrimSpace(string(runes)) + "…"
+
+		if TextWidth(face, shortened) <= maxWidth {
+			return shortened
+		}
+	}
+
+	return ""
+}
+
+// DrawText draws the text with the face and color, its baseline starting at the given point
+func DrawText(dst draw.Image, face font.Face, x int, y int, text string, c color.Color) {
+	drawer := font.Drawer{
+		Dst:  dst,
+		Src:  image.NewUniform(c),
+		Face: face,
+		Dot:  fixed.P(x, y),
+	}
+	drawer.DrawString(text)
+}
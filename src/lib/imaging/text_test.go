@@ -0,0 +1,24 @@
+package imaging
+
+import "testing"
+
+func TestEllipsize(t *testing.T) {
+	face, err := NewFace(14)
+	if err != nil {
+		t.Fatalf("Failed to load font: %s", err)
+	}
+
+	text := "Brittany, France"
+	if result := Ellipsize(face, text, TextWidth(face, text)); result != text {
+		t.Fatalf("Expected %q to fit, got %q", text, result)
+	}
+
+	result := Ellipsize(face, text, TextWidth(face, text)-1)
+	if result == text || TextWidth(face, result) >= TextWidth(face, text) {
+		t.Fatalf("Expected %q to be shortened, got %q", text, result)
+	}
+
+	if result := Ellipsize(face, text, 0); result != "" {
+		t.Fatalf("Expected empty text, got %q", result)
+	}
+}
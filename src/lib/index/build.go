@@ -0,0 +1,163 @@
+/*
+Copyright © 2024 Nicolas Goudry <goudry.nicolas@gmail.com>
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package index
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"image/jpeg"
+	"sync"
+
+	"earth-view/lib"
+	"earth-view/lib/imaging"
+)
+
+// BuildOptions configures how the index is built
+type BuildOptions struct {
+	// Refresh fetches the metadata of images already in the index again
+	Refresh bool
+	// Prune removes images which are not part of the built identifiers
+	Prune bool
+	// Workers is the number of parallel calls to gstatic.com
+	Workers int
+	// Retry is the number of retries before skipping an image
+	Retry int
+	// OnProgress is called after each image is processed, if set
+	OnProgress func(done int, total int)
+	// Missing holds the images known not to exist, which are only fetched again on refresh
+	// It is updated with the images found missing or existing during the build, if set
+	Missing map[int]bool
+}
+
+// BuildResult reports the changes made to the index
+type BuildResult struct {
+	Added   int
+	Updated int
+	Removed int
+	// Missing is the number of fetched images which do not exist
+	Missing int
+	Errors  []error
+}
+
+// fetchEntry retrieves the metadata of an image, it is replaced in tests
+var fetchEntry = func(id int, retry int) (Entry, error) {
+	asset := lib.Asset{Id: id}
+	if err := asset.Fetch(retry); err != nil {
+		return Entry{}, err
+	}
+
+	// The image data is only decoded to measure it, it is not kept in the index
+	if _, err := asset.GetContent(); err != nil {
+		return Entry{}, err
+	}
+
+	entry := EntryFromAsset(&asset)
+
+	img, err := jpeg.Decode(bytes.NewReader(asset.Content))
+	if err != nil {
+		return Entry{}, fmt.Errorf("[%d] failed to decode image: %s", id, err)
+	}
+
+	stats := imaging.Measure(img)
+	entry.Stats = &stats
+
+	return entry, nil
+}
+
+// Build fetches the metadata of the given images and adds them to the index
+// Only images missing from the index and not known to be missing upstream are fetched, as well as
+// indexed images which were not measured yet, unless Refresh is set
+func (idx *Index) Build(ids []int, opts BuildOptions) BuildResult {
+	result := BuildResult{}
+
+	var pending []int
+	for _, id := range ids {
+		entry, ok := idx.Get(id)
+		if (!ok && !opts.Missing[id]) || (ok && entry.Stats == nil) || opts.Refresh {
+			pending = append(pending, id)
+		}
+	}
+
+	if opts.Prune {
+		keep := make(map[int]bool, len(ids))
+		for _, id := range ids {
+			keep[id] = true
+		}
+
+		for _, entry := range idx.Entries() {
+			if !keep[entry.Id] {
+				idx.Remove(entry.Id)
+				result.Removed++
+			}
+		}
+	}
+
+	workers := max(opts.Workers, 1)
+	jobs := make(chan int)
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			for id := range jobs {
+				entry, err := fetchEntry(id, opts.Retry)
+
+				mu.Lock()
+				if errors.Is(err, lib.ErrNotFound) {
+					result.Missing++
+					if opts.Missing != nil {
+						opts.Missing[id] = true
+					}
+				} else if err != nil {
+					result.Errors = append(result.Errors, err)
+				} else {
+					delete(opts.Missing, id)
+
+					if _, ok := idx.Get(id); ok {
+						result.Updated++
+					} else {
+						result.Added++
+					}
+					idx.Add(entry)
+				}
+
+				done := result.Added + result.Updated + result.Missing + len(result.Errors)
+				if opts.OnProgress != nil {
+					opts.OnProgress(done, len(pending))
+				}
+				mu.Unlock()
+			}
+		}()
+	}
+
+	for _, id := range pending {
+		jobs <- id
+	}
+	close(jobs)
+	wg.Wait()
+
+	return result
+}
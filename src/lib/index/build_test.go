@@ -0,0 +1,69 @@
+package index
+
+import (
+	"fmt"
+	"testing"
+
+	"earth-view/lib"
+	"earth-view/lib/imaging"
+)
+
+func TestBuild(t *testing.T) {
+	fetched := make(map[int]int)
+
+	prevFetch := fetchEntry
+	fetchEntry = func(id int, _ int) (Entry, error) {
+		fetched[id]++
+
+		if id == 1008 {
+			return Entry{}, fmt.Errorf("[%d] fetch failed: %w", id, lib.ErrNotFound)
+		}
+
+		return Entry{Id: id, Country: "France"}, nil
+	}
+	defer func() { fetchEntry = prevFetch }()
+
+	// Entries without statistics are fetched again to measure them
+	idx := New()
+	for _, entry := range entries[:2] {
+		entry.Stats = &imaging.Stats{}
+		idx.Add(entry)
+	}
+
+	// Workers are limited to one since the fake fetch function is not safe for concurrent use
+	missing := make(map[int]bool)
+	result := idx.Build(
+		[]int{1003, 1006, 1007, 1008},
+		BuildOptions{Workers: 1, Prune: true, Missing: missing},
+	)
+
+	if result.Added != 2 || result.Updated != 0 || result.Removed != 1 || result.Missing != 1 ||
+		len(result.Errors) != 0 {
+		t.Fatalf("Expected 2 added, 1 removed and 1 missing, got %+v", result)
+	}
+
+	if !missing[1008] {
+		t.Fatalf("Expected missing image to be recorded, got %v", missing)
+	}
+
+	idx.Build([]int{1008}, BuildOptions{Workers: 1, Missing: missing})
+	if fetched[1008] != 1 {
+		t.Fatalf("Expected missing image not to be fetched again")
+	}
+
+	if fetched[1003] != 0 {
+		t.Fatalf("Expected indexed image not to be fetched again")
+	}
+
+	if _, ok := idx.Get(1004); ok || idx.Len() != 3 {
+		t.Fatalf("Expected image missing from input to be pruned, got %+v", idx.Entries())
+	}
+
+	result = idx.Build([]int{1003, 1008}, BuildOptions{Workers: 1, Refresh: true, Missing: missing})
+	if result.Updated != 1 || fetched[1003] != 1 || fetched[1008] != 2 {
+		t.Fatalf(
+			"Expected indexed and missing images to be fetched again on refresh, got %+v",
+			result,
+		)
+	}
+}
@@ -0,0 +1,236 @@
+/*
+Copyright © 2024 Nicolas Goudry <goudry.nicolas@gmail.com>
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package index
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+
+	"earth-view/lib/geo"
+	"earth-view/lib/imaging"
+)
+
+// defaultRadius is the maximum distance from a point when none is provided
+const defaultRadius = "500km"
+
+// Filter restricts images by their location and statistics
+// An image matches if it matches all the set criteria, and any of the values of a criterion
+type Filter struct {
+	Countries  []string
+	Regions    []string
+	Continents []string
+	Bbox       *geo.Bbox
+	Near       *geo.Point
+	// Radius is the maximum distance from Near, in kilometers
+	Radius float64
+	// MinBrightness, MaxBrightness and MaxHaze bound image statistics, when set
+	MinBrightness *float64
+	MaxBrightness *float64
+	MaxHaze       *float64
+	Hues          []string
+}
+
+// FilterOptions holds the raw values of filters, as provided on the command line
+type FilterOptions struct {
+	Countries     []string
+	Regions       []string
+	Continents    []string
+	Bbox          string
+	Near          string
+	Radius        string
+	MinBrightness string
+	MaxBrightness string
+	MaxHaze       string
+	Hues          []string
+}
+
+// NewFilter parses and validates filter options
+func NewFilter(opts FilterOptions) (*Filter, error) {
+	filter := &Filter{
+		Countries: opts.Countries,
+		Regions:   opts.Regions,
+	}
+
+	for _, name := range opts.Continents {
+		continent, ok := geo.ParseContinent(name)
+		if !ok {
+			return nil, fmt.Errorf(
+				"invalid continent: %s. Expected one of %s",
+				name,
+				strings.Join(geo.Continents, ", "),
+			)
+		}
+
+		filter.Continents = append(filter.Continents, continent)
+	}
+
+	if opts.Bbox != "" {
+		bbox, err := geo.ParseBbox(opts.Bbox)
+		if err != nil {
+			return nil, err
+		}
+
+		filter.Bbox = &bbox
+	}
+
+	if opts.Near != "" {
+		near, err := geo.ParsePoint(opts.Near)
+		if err != nil {
+			return nil, err
+		}
+
+		filter.Near = &near
+
+		radius := opts.Radius
+		if radius == "" {
+			radius = defaultRadius
+		}
+
+		if filter.Radius, err = geo.ParseDistance(radius); err != nil {
+			return nil, err
+		}
+	} else if opts.Radius != "" {
+		return nil, fmt.Errorf("radius requires a point to be set")
+	}
+
+	var err error
+	for _, bound := range []struct {
+		name   string
+		value  string
+		target **float64
+	}{
+		{"minimum brightness", opts.MinBrightness, &filter.MinBrightness},
+		{"maximum brightness", opts.MaxBrightness, &filter.MaxBrightness},
+		{"maximum haze", opts.MaxHaze, &filter.MaxHaze},
+	} {
+		if *bound.target, err = parseRatio(bound.name, bound.value); err != nil {
+			return nil, err
+		}
+	}
+
+	for _, hue := range opts.Hues {
+		hue = strings.ToLower(hue)
+		if !slices.Contains(imaging.HueNames, hue) {
+			return nil, fmt.Errorf(
+				"invalid hue: %s. Expected one of %s",
+				hue,
+				strings.Join(imaging.HueNames, ", "),
+			)
+		}
+
+		filter.Hues = append(filter.Hues, hue)
+	}
+
+	return filter, nil
+}
+
+// parseRatio parses an optional number between 0 and 1, returning nil if it is empty
+func parseRatio(name string, value string) (*float64, error) {
+	if value == "" {
+		return nil, nil
+	}
+
+	ratio, err := strconv.ParseFloat(value, 64)
+	if err != nil || ratio < 0 || ratio > 1 {
+		return nil, fmt.Errorf("invalid %s: %s. Expected a number between 0 and 1", name, value)
+	}
+
+	return &ratio, nil
+}
+
+// IsEmpty returns whether the filter has no criterion
+func (f *Filter) IsEmpty() bool {
+	return len(f.Countries) == 0 && len(f.Regions) == 0 && len(f.Continents) == 0 &&
+		f.Bbox == nil && f.Near == nil && f.MinBrightness == nil && f.MaxBrightness == nil &&
+		f.MaxHaze == nil && len(f.Hues) == 0
+}
+
+// Match returns whether an entry matches the filter
+func (f *Filter) Match(entry Entry) bool {
+	if len(f.Countries) > 0 && !containsFold(f.Countries, entry.Country) {
+		return false
+	}
+
+	if len(f.Regions) > 0 && !containsFold(f.Regions, entry.Region) {
+		return false
+	}
+
+	if len(f.Continents) > 0 && !slices.Contains(f.Continents, geo.ContinentOf(entry.Country)) {
+		return false
+	}
+
+	if f.Bbox != nil && !f.Bbox.Contains(entry.Point()) {
+		return false
+	}
+
+	if f.Near != nil && geo.Distance(*f.Near, entry.Point()) > f.Radius {
+		return false
+	}
+
+	return f.matchStats(entry.Stats)
+}
+
+// matchStats returns whether image statistics match the filter
+// Images without statistics never match statistics criteria
+func (f *Filter) matchStats(stats *imaging.Stats) bool {
+	if f.MinBrightness == nil && f.MaxBrightness == nil && f.MaxHaze == nil && len(f.Hues) == 0 {
+		return true
+	}
+
+	if stats == nil {
+		return false
+	}
+
+	if f.MinBrightness != nil && stats.Brightness < *f.MinBrightness {
+		return false
+	}
+
+	if f.MaxBrightness != nil && stats.Brightness > *f.MaxBrightness {
+		return false
+	}
+
+	if f.MaxHaze != nil && stats.Haze > *f.MaxHaze {
+		return false
+	}
+
+	return len(f.Hues) == 0 || slices.Contains(f.Hues, stats.Hue)
+}
+
+// Apply returns the identifiers of images in the index which match the filter
+// Images missing from the index are excluded since their location and statistics are unknown
+func (f *Filter) Apply(idx *Index, ids []int) []int {
+	var matching []int
+	for _, id := range ids {
+		if entry, ok := idx.Get(id); ok && f.Match(entry) {
+			matching = append(matching, id)
+		}
+	}
+
+	return matching
+}
+
+// containsFold returns whether values contain the given value, case-insensitively
+func containsFold(values []string, value string) bool {
+	return slices.ContainsFunc(values, func(v string) bool { return strings.EqualFold(v, value) })
+}
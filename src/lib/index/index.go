@@ -0,0 +1,328 @@
+/*
+Copyright © 2024 Nicolas Goudry <goudry.nicolas@gmail.com>
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package index
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"image"
+	_ "image/jpeg"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+
+	"earth-view/lib"
+	"earth-view/lib/geo"
+	"earth-view/lib/imaging"
+)
+
+// Entry holds the metadata of an image needed to select it without fetching it
+type Entry struct {
+	Id          int     `json:"id"`
+	Country     string  `json:"country,omitempty"`
+	Region      string  `json:"region,omitempty"`
+	Lat         float64 `json:"lat"`
+	Lng         float64 `json:"lng"`
+	Zoom        int     `json:"zoom,omitempty"`
+	Attribution string  `json:"attribution,omitempty"`
+	// MapsLink and EarthLink are the links provided by Google Earth View, if any
+	MapsLink  string `json:"mapsLink,omitempty"`
+	EarthLink string `json:"earthLink,omitempty"`
+	// Width and Height are the dimensions of the image in pixels
+	Width  int `json:"width,omitempty"`
+	Height int `json:"height,omitempty"`
+	// Size is the size of the encoded image in bytes
+	Size int `json:"size,omitempty"`
+	// Stats are measured on the image by the 'index build' command, they are missing until then
+	Stats *imaging.Stats `json:"stats,omitempty"`
+}
+
+// Point returns the location of the image
+func (e Entry) Point() geo.Point {
+	return geo.Point{Lat: e.Lat, Lng: e.Lng}
+}
+
+// Location returns the region and country of the image, or an empty string if they are unknown
+func (e Entry) Location() string {
+	var parts []string
+	for _, part := range []string{e.Region, e.Country} {
+		if part != "" {
+			parts = append(parts, part)
+		}
+	}
+
+	return strings.Join(parts, ", ")
+}
+
+// Links returns the Google Maps and Google Earth links of the image, as provided upstream
+// Images indexed before links were recorded get links built from their coordinates
+func (e Entry) Links() (string, string) {
+	mapsLink, earthLink := e.MapsLink, e.EarthLink
+	if mapsLink == "" {
+		mapsLink = geo.MapsLink(e.Point(), e.Zoom)
+	}
+
+	if earthLink == "" {
+		earthLink = geo.EarthLink(e.Point(), e.Zoom)
+	}
+
+	return mapsLink, earthLink
+}
+
+// Index is the local store of images metadata
+// It is stored in $XDG_DATA_HOME/earth-view/index.json, filled when images are fetched or by the
+// 'index build' command, and may be embedded in the binary
+type Index struct {
+	entries map[int]Entry
+}
+
+// Path returns the path of the index file
+func Path() string {
+	return filepath.Join(lib.DataHome(), "earth-view", "index.json")
+}
+
+// New creates an empty index
+func New() *Index {
+	return &Index{entries: make(map[int]Entry)}
+}
+
+// Load reads the index file, on top of the embedded index if any
+// An empty index is returned if there is none
+func Load() (*Index, error) {
+	idx := New()
+
+	if len(embedded) > 0 {
+		if err := idx.decode(embedded); err != nil {
+			return nil, fmt.Errorf("invalid embedded index: %s", err)
+		}
+	}
+
+	content, err := os.ReadFile(Path())
+	if errors.Is(err, os.ErrNotExist) {
+		return idx, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	if err := idx.decode(content); err != nil {
+		return nil, fmt.Errorf("invalid index file: %s", err)
+	}
+
+	return idx, nil
+}
+
+// LoadFile reads the given index file, returning an empty index if it does not exist
+func LoadFile(filePath string) (*Index, error) {
+	idx := New()
+
+	content, err := os.ReadFile(filePath)
+	if errors.Is(err, os.ErrNotExist) {
+		return idx, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	if err := idx.decode(content); err != nil {
+		return nil, fmt.Errorf("invalid index file: %s", err)
+	}
+
+	return idx, nil
+}
+
+// decode adds the entries of an encoded index, replacing existing ones
+func (idx *Index) decode(content []byte) error {
+	var entries []Entry
+	if err := json.Unmarshal(content, &entries); err != nil {
+		return err
+	}
+
+	for _, entry := range entries {
+		idx.entries[entry.Id] = entry
+	}
+
+	return nil
+}
+
+// Save writes the index file
+func (idx *Index) Save() error {
+	return idx.SaveFile(Path())
+}
+
+// SaveFile writes the index to the given file
+// The file is replaced atomically so that concurrent readers never see a partial index
+func (idx *Index) SaveFile(filePath string) error {
+	content, err := json.Marshal(idx.Entries())
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(filePath), 0755); err != nil {
+		return err
+	}
+
+	return lib.WriteFileAtomic(filePath, content, 0644)
+}
+
+// Add adds or replaces the entry of an image
+func (idx *Index) Add(entry Entry) {
+	idx.entries[entry.Id] = entry
+}
+
+// Get returns the entry of an image and whether it is in the index
+func (idx *Index) Get(id int) (Entry, bool) {
+	entry, ok := idx.entries[id]
+	return entry, ok
+}
+
+// Remove removes the entry of an image
+func (idx *Index) Remove(id int) {
+	delete(idx.entries, id)
+}
+
+// Len returns the number of entries in the index
+func (idx *Index) Len() int {
+	return len(idx.entries)
+}
+
+// Entries returns all the entries, sorted by identifier
+func (idx *Index) Entries() []Entry {
+	entries := make([]Entry, 0, len(idx.entries))
+	for _, entry := range idx.entries {
+		entries = append(entries, entry)
+	}
+
+	slices.SortFunc(entries, func(a, b Entry) int { return a.Id - b.Id })
+
+	return entries
+}
+
+// EntryFromMetadata creates the entry of an image from its asset metadata
+func EntryFromMetadata(id int, metadata map[string]interface{}) Entry {
+	entry := Entry{Id: id}
+	entry.Country, _ = metadata["country"].(string)
+	entry.Region, _ = metadata["region"].(string)
+	entry.Attribution, _ = metadata["attribution"].(string)
+	entry.MapsLink, _ = metadata["mapsLink"].(string)
+	entry.EarthLink, _ = metadata["earthLink"].(string)
+	entry.Lat = metadataFloat(metadata["lat"])
+	entry.Lng = metadataFloat(metadata["lng"])
+	entry.Zoom = int(metadataFloat(metadata["zoom"]))
+
+	return entry
+}
+
+// metadataFloat converts a metadata value to a number, which may be encoded as a string
+func metadataFloat(value interface{}) float64 {
+	switch v := value.(type) {
+	case float64:
+		return v
+	case string:
+		f, _ := strconv.ParseFloat(v, 64)
+		return f
+	}
+
+	return 0
+}
+
+// EntryFromAsset creates the entry of an image from a fetched asset, including its dimensions if
+// its content was decoded
+func EntryFromAsset(asset *lib.Asset) Entry {
+	entry := EntryFromMetadata(asset.Id, asset.Metadata)
+
+	if asset.Content != nil {
+		entry.Size = len(asset.Content)
+
+		if config, _, err := image.DecodeConfig(bytes.NewReader(asset.Content)); err == nil {
+			entry.Width = config.Width
+			entry.Height = config.Height
+		}
+	}
+
+	return entry
+}
+
+// Lookup returns the entry of an image, fetching its metadata and adding it to the index if it is
+// not indexed yet
+// The image itself is not fetched, its dimensions are only known once downloaded and its statistics
+// once measured by Build
+func (idx *Index) Lookup(id int) (Entry, error) {
+	if entry, ok := idx.Get(id); ok {
+		return entry, nil
+	}
+
+	asset := lib.Asset{Id: id}
+	if _, err := asset.GetMetadata(); err != nil {
+		return Entry{}, err
+	}
+
+	entry := EntryFromAsset(&asset)
+	idx.Add(entry)
+
+	return entry, nil
+}
+
+// Lookup returns the entry of an image from the index file, fetching its metadata and saving it in
+// the index file if it is not indexed yet
+func Lookup(id int) (Entry, error) {
+	idx, err := Load()
+	if err != nil {
+		return Entry{}, err
+	}
+
+	if entry, ok := idx.Get(id); ok {
+		return entry, nil
+	}
+
+	entry, err := idx.Lookup(id)
+	if err != nil {
+		return Entry{}, err
+	}
+
+	return entry, idx.Save()
+}
+
+// Record adds the metadata of a fetched asset to the index file
+func Record(asset *lib.Asset) error {
+	if asset.Metadata == nil {
+		return nil
+	}
+
+	idx, err := Load()
+	if err != nil {
+		return err
+	}
+
+	entry := EntryFromAsset(asset)
+
+	// Statistics are only measured when building the index
+	if previous, ok := idx.Get(entry.Id); ok {
+		entry.Stats = previous.Stats
+	}
+
+	idx.Add(entry)
+
+	return idx.Save()
+}
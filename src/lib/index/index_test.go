@@ -0,0 +1,128 @@
+package index
+
+import (
+	"slices"
+	"testing"
+
+	"earth-view/lib"
+	"earth-view/lib/imaging"
+)
+
+var entries = []Entry{
+	{Id: 1003, Country: "France", Region: "Brittany", Lat: 48.2, Lng: -3.0},
+	{Id: 1004, Country: "Australia", Region: "Queensland", Lat: -20.9, Lng: 142.7},
+	{Id: 1006, Country: "United States", Region: "Utah", Lat: 38.5, Lng: -109.8},
+	{Id: 1007, Country: "Spain", Region: "Andalusia", Lat: 37.4, Lng: -5.9},
+}
+
+func TestRecordAndLoad(t *testing.T) {
+	t.Setenv("XDG_DATA_HOME", t.TempDir())
+
+	asset := lib.Asset{
+		Id: 1003,
+		Metadata: map[string]interface{}{
+			"country": "France",
+			"region":  "Brittany",
+			"lat":     48.2,
+			"lng":     "-3.0",
+		},
+	}
+
+	if err := Record(&asset); err != nil {
+		t.Fatalf("Expected no error while recording asset, got %v", err)
+	}
+
+	idx, err := Load()
+	if err != nil {
+		t.Fatalf("Expected no error while loading index, got %v", err)
+	}
+
+	entry, ok := idx.Get(1003)
+	if !ok || entry != entries[0] {
+		t.Fatalf("Expected entry %+v, got %+v", entries[0], entry)
+	}
+}
+
+func TestFilter(t *testing.T) {
+	idx := New()
+	for _, entry := range entries {
+		idx.Add(entry)
+	}
+
+	ids := []int{1003, 1004, 1006, 1007, 1008}
+
+	for _, tc := range []struct {
+		opts     FilterOptions
+		expected []int
+	}{
+		{FilterOptions{}, []int{1003, 1004, 1006, 1007}},
+		{FilterOptions{Countries: []string{"france", "Spain"}}, []int{1003, 1007}},
+		{FilterOptions{Regions: []string{"Utah"}}, []int{1006}},
+		{FilterOptions{Continents: []string{"europe"}}, []int{1003, 1007}},
+		{FilterOptions{Continents: []string{"Oceania"}, Countries: []string{"France"}}, nil},
+		{FilterOptions{Bbox: "-10,35,5,50"}, []int{1003, 1007}},
+		{FilterOptions{Near: "40.4,-3.7", Radius: "500km"}, []int{1007}},
+		{FilterOptions{Near: "40.4,-3.7", Radius: "1000km"}, []int{1003, 1007}},
+	} {
+		filter, err := NewFilter(tc.opts)
+		if err != nil {
+			t.Fatalf("Expected no error for %+v, got %v", tc.opts, err)
+		}
+
+		if matching := filter.Apply(idx, ids); !slices.Equal(matching, tc.expected) {
+			t.Fatalf("Expected %+v to match %v, got %v", tc.opts, tc.expected, matching)
+		}
+	}
+
+	for _, opts := range []FilterOptions{
+		{Continents: []string{"Atlantis"}},
+		{Bbox: "1,2"},
+		{Near: "100,0"},
+		{Radius: "10km"},
+	} {
+		if _, err := NewFilter(opts); err == nil {
+			t.Fatalf("Expected error for %+v", opts)
+		}
+	}
+}
+
+func TestStatsFilter(t *testing.T) {
+	idx := New()
+	idx.Add(Entry{Id: 1003, Stats: &imaging.Stats{Brightness: 0.2, Hue: "blue", Haze: 0.05}})
+	idx.Add(Entry{Id: 1004, Stats: &imaging.Stats{Brightness: 0.5, Hue: "orange", Haze: 0.1}})
+	idx.Add(Entry{Id: 1006, Stats: &imaging.Stats{Brightness: 0.8, Hue: imaging.Gray, Haze: 0.7}})
+	idx.Add(Entry{Id: 1007})
+
+	ids := []int{1003, 1004, 1006, 1007}
+
+	for _, tc := range []struct {
+		opts     FilterOptions
+		expected []int
+	}{
+		{FilterOptions{MinBrightness: "0.3"}, []int{1004, 1006}},
+		{FilterOptions{MaxBrightness: "0.6"}, []int{1003, 1004}},
+		{FilterOptions{MinBrightness: "0.3", MaxBrightness: "0.6"}, []int{1004}},
+		{FilterOptions{MaxHaze: "0.5"}, []int{1003, 1004}},
+		{FilterOptions{Hues: []string{"Blue", "gray"}}, []int{1003, 1006}},
+	} {
+		filter, err := NewFilter(tc.opts)
+		if err != nil {
+			t.Fatalf("Expected no error for %+v, got %v", tc.opts, err)
+		}
+
+		if matching := filter.Apply(idx, ids); !slices.Equal(matching, tc.expected) {
+			t.Fatalf("Expected %+v to match %v, got %v", tc.opts, tc.expected, matching)
+		}
+	}
+
+	for _, opts := range []FilterOptions{
+		{MinBrightness: "bright"},
+		{MaxBrightness: "1.5"},
+		{MaxHaze: "-0.1"},
+		{Hues: []string{"magenta"}},
+	} {
+		if _, err := NewFilter(opts); err == nil {
+			t.Fatalf("Expected error for %+v", opts)
+		}
+	}
+}
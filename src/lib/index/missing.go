@@ -0,0 +1,83 @@
+/*
+Copyright © 2024 Nicolas Goudry <goudry.nicolas@gmail.com>
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package index
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+
+	"earth-view/lib"
+)
+
+// MissingPath returns the path of the file listing images which do not exist
+func MissingPath() string {
+	return filepath.Join(lib.DataHome(), "earth-view", "missing.json")
+}
+
+// LoadMissing reads the identifiers of images known not to exist, returning an empty set if there
+// is no such file
+func LoadMissing() (map[int]bool, error) {
+	missing := make(map[int]bool)
+
+	content, err := os.ReadFile(MissingPath())
+	if errors.Is(err, os.ErrNotExist) {
+		return missing, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	var ids []int
+	if err := json.Unmarshal(content, &ids); err != nil {
+		return nil, fmt.Errorf("invalid missing images file: %s", err)
+	}
+
+	for _, id := range ids {
+		missing[id] = true
+	}
+
+	return missing, nil
+}
+
+// SaveMissing writes the identifiers of images known not to exist, sorted
+func SaveMissing(missing map[int]bool) error {
+	ids := make([]int, 0, len(missing))
+	for id := range missing {
+		ids = append(ids, id)
+	}
+
+	slices.Sort(ids)
+
+	content, err := json.Marshal(ids)
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(MissingPath()), 0755); err != nil {
+		return err
+	}
+
+	return os.WriteFile(MissingPath(), content, 0644)
+}
@@ -0,0 +1,258 @@
+/*
+Copyright © 2024 Nicolas Goudry <goudry.nicolas@gmail.com>
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package index
+
+import (
+	"fmt"
+	"math"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"earth-view/lib/geo"
+	"earth-view/lib/imaging"
+)
+
+// fields maps the names usable in queries to the matching entry values, either strings or numbers
+var fields = map[string]func(Entry) interface{}{
+	"id":           func(e Entry) interface{} { return float64(e.Id) },
+	"country":      func(e Entry) interface{} { return e.Country },
+	"region":       func(e Entry) interface{} { return e.Region },
+	"continent":    func(e Entry) interface{} { return geo.ContinentOf(e.Country) },
+	"attribution":  func(e Entry) interface{} { return e.Attribution },
+	"lat":          func(e Entry) interface{} { return e.Lat },
+	"lng":          func(e Entry) interface{} { return e.Lng },
+	"zoom":         func(e Entry) interface{} { return float64(e.Zoom) },
+	"width":        func(e Entry) interface{} { return float64(e.Width) },
+	"height":       func(e Entry) interface{} { return float64(e.Height) },
+	"size":         func(e Entry) interface{} { return float64(e.Size) },
+	"brightness":   statsField(func(s imaging.Stats) float64 { return s.Brightness }),
+	"contrast":     statsField(func(s imaging.Stats) float64 { return s.Contrast }),
+	"colorfulness": statsField(func(s imaging.Stats) float64 { return s.Colorfulness }),
+	"haze":         statsField(func(s imaging.Stats) float64 { return s.Haze }),
+	"hue": func(e Entry) interface{} {
+		if e.Stats == nil {
+			return ""
+		}
+
+		return e.Stats.Hue
+	},
+}
+
+// statsField returns the value of a field measured on images, which never matches numeric
+// comparisons when the entry has no statistics
+func statsField(get func(imaging.Stats) float64) func(Entry) interface{} {
+	return func(e Entry) interface{} {
+		if e.Stats == nil {
+			return math.NaN()
+		}
+
+		return get(*e.Stats)
+	}
+}
+
+// textFields are the fields matched by free text terms
+var textFields = []string{"country", "region", "continent", "attribution"}
+
+// FieldNames returns the names of the fields usable in queries, sorted
+func FieldNames() []string {
+	names := make([]string, 0, len(fields))
+	for name := range fields {
+		names = append(names, name)
+	}
+
+	slices.Sort(names)
+
+	return names
+}
+
+// term is a single criterion of a query
+type term struct {
+	// field is empty for free text terms
+	field string
+	// op is one of '=', '~', '>', '>=', '<' or '<='
+	op     string
+	value  string
+	number float64
+}
+
+// Query is a parsed search query, matching entries which match all its terms
+type Query struct {
+	terms []term
+}
+
+// ParseQuery parses a search query made of whitespace separated terms
+// A term is either free text, matched as a substring of the text fields, or 'field:value' with
+// an optional operator before the value: '~' for substring match, or '>', '>=', '<' and '<=' for
+// numeric comparison. Values containing spaces can be quoted.
+func ParseQuery(query string) (*Query, error) {
+	words, err := splitQuery(query)
+	if err != nil {
+		return nil, err
+	}
+
+	q := &Query{}
+	for _, word := range words {
+		name, value, found := strings.Cut(word, ":")
+		if !found {
+			q.terms = append(q.terms, term{op: "~", value: word})
+			continue
+		}
+
+		name = strings.ToLower(name)
+		get, ok := fields[name]
+		if !ok {
+			return nil, fmt.Errorf(
+				"unknown field: %s. Expected one of %s",
+				name,
+				strings.Join(FieldNames(), ", "),
+			)
+		}
+
+		t := term{field: name, op: "="}
+		for _, op := range []string{">=", "<=", ">", "<", "~", "="} {
+			if rest, found := strings.CutPrefix(value, op); found {
+				t.op, value = op, rest
+				break
+			}
+		}
+		t.value = value
+
+		_, numeric := get(Entry{}).(float64)
+		if numeric && t.op != "~" {
+			if t.number, err = strconv.ParseFloat(value, 64); err != nil {
+				return nil, fmt.Errorf("invalid number for field %s: %s", name, value)
+			}
+		} else if !numeric && t.op != "=" && t.op != "~" {
+			return nil, fmt.Errorf("operator %s is not supported on text field %s", t.op, name)
+		}
+
+		q.terms = append(q.terms, t)
+	}
+
+	return q, nil
+}
+
+// splitQuery splits a query on whitespaces, keeping quoted parts together without their quotes
+func splitQuery(query string) ([]string, error) {
+	var words []string
+	var word strings.Builder
+	inWord, quoted := false, false
+
+	for _, r := range query {
+		switch {
+		case r == '"':
+			quoted = !quoted
+			inWord = true
+		case unicode.IsSpace(r) && !quoted:
+			if inWord {
+				words = append(words, word.String())
+				word.Reset()
+				inWord = false
+			}
+		default:
+			word.WriteRune(r)
+			inWord = true
+		}
+	}
+
+	if quoted {
+		return nil, fmt.Errorf("unterminated quote in query: %s", query)
+	}
+
+	if inWord {
+		words = append(words, word.String())
+	}
+
+	return words, nil
+}
+
+// Match returns whether an entry matches all the terms of the query
+func (q *Query) Match(entry Entry) bool {
+	for _, t := range q.terms {
+		if !t.match(entry) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// Search returns the entries of the index matching the query, sorted by identifier
+func (q *Query) Search(idx *Index) []Entry {
+	var results []Entry
+	for _, entry := range idx.Entries() {
+		if q.Match(entry) {
+			results = append(results, entry)
+		}
+	}
+
+	return results
+}
+
+// match returns whether an entry matches the term
+func (t term) match(entry Entry) bool {
+	if t.field == "" {
+		return slices.ContainsFunc(textFields, func(name string) bool {
+			return matchText(fields[name](entry).(string), "~", t.value)
+		})
+	}
+
+	switch value := fields[t.field](entry).(type) {
+	case string:
+		return matchText(value, t.op, t.value)
+	case float64:
+		if t.op == "~" {
+			return strings.Contains(strconv.FormatFloat(value, 'f', -1, 64), t.value)
+		}
+
+		return matchNumber(value, t.op, t.number)
+	}
+
+	return false
+}
+
+// matchText compares text case-insensitively, either for equality or as a substring
+func matchText(value string, op string, expected string) bool {
+	if op == "~" {
+		return strings.Contains(strings.ToLower(value), strings.ToLower(expected))
+	}
+
+	return strings.EqualFold(value, expected)
+}
+
+// matchNumber compares numbers with the given operator
+func matchNumber(value float64, op string, expected float64) bool {
+	switch op {
+	case ">":
+		return value > expected
+	case ">=":
+		return value >= expected
+	case "<":
+		return value < expected
+	case "<=":
+		return value <= expected
+	}
+
+	return value == expected
+}
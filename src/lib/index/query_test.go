@@ -0,0 +1,84 @@
+package index
+
+import (
+	"testing"
+
+	"earth-view/lib/imaging"
+)
+
+func TestQuery(t *testing.T) {
+	idx := New()
+	idx.Add(
+		Entry{
+			Id:          1003,
+			Country:     "Chile",
+			Region:      "Atacama",
+			Zoom:        15,
+			Attribution: "©2014 DigitalGlobe",
+		},
+	)
+	idx.Add(
+		Entry{
+			Id:          1004,
+			Country:     "Chile",
+			Region:      "Santiago",
+			Zoom:        13,
+			Attribution: "©2014 Cnes/Spot Image",
+		},
+	)
+	idx.Add(Entry{Id: 1006, Country: "Greenland", Zoom: 12, Attribution: "©2015 DigitalGlobe"})
+	idx.Add(
+		Entry{
+			Id:      1007,
+			Country: "United States",
+			Region:  "Utah",
+			Zoom:    16,
+			Stats:   &imaging.Stats{Brightness: 0.45, Hue: "orange"},
+		},
+	)
+
+	for _, tc := range []struct {
+		query    string
+		expected []int
+	}{
+		{"", []int{1003, 1004, 1006, 1007}},
+		{"chile", []int{1003, 1004}},
+		{"globe", []int{1003, 1006}},
+		{"country:Chile zoom:>14 attribution:~DigitalGlobe", []int{1003}},
+		{"country:chil", nil},
+		{`country:"united states"`, []int{1007}},
+		{"zoom:<=13", []int{1004, 1006}},
+		{"id:1006", []int{1006}},
+		{"continent:europe", nil},
+		{"continent:\"North America\"", []int{1006, 1007}},
+		{"brightness:>0.4", []int{1007}},
+		{"brightness:<0.4", nil},
+		{"hue:orange", []int{1007}},
+	} {
+		q, err := ParseQuery(tc.query)
+		if err != nil {
+			t.Fatalf("Expected no error for %q, got %v", tc.query, err)
+		}
+
+		var ids []int
+		for _, entry := range q.Search(idx) {
+			ids = append(ids, entry.Id)
+		}
+
+		if len(ids) != len(tc.expected) {
+			t.Fatalf("Expected %q to match %v, got %v", tc.query, tc.expected, ids)
+		}
+
+		for i := range ids {
+			if ids[i] != tc.expected[i] {
+				t.Fatalf("Expected %q to match %v, got %v", tc.query, tc.expected, ids)
+			}
+		}
+	}
+
+	for _, query := range []string{"color:red", "zoom:>high", "country:>Chile", `"unterminated`} {
+		if _, err := ParseQuery(query); err == nil {
+			t.Fatalf("Expected error for query %q", query)
+		}
+	}
+}
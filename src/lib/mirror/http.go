@@ -0,0 +1,74 @@
+/*
+Copyright © 2024 Nicolas Goudry <goudry.nicolas@gmail.com>
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package mirror
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// ImmutableCache is the cache policy of images and their metadata, which never change upstream
+const ImmutableCache = "public, max-age=604800, immutable"
+
+// ParseId parses an image identifier from a path
+func ParseId(value string) (int, bool) {
+	id, err := strconv.Atoi(value)
+	if err != nil || id < 0 || strconv.Itoa(id) != value {
+		return 0, false
+	}
+
+	return id, true
+}
+
+// ServeContent writes the content with an ETag derived from it, answering conditional and range
+// requests
+func ServeContent(
+	w http.ResponseWriter,
+	r *http.Request,
+	name string,
+	content []byte,
+	cacheControl string,
+) {
+	sum := sha256.Sum256(content)
+
+	w.Header().Set("ETag", `"`+hex.EncodeToString(sum[:16])+`"`)
+	w.Header().Set("Cache-Control", cacheControl)
+	http.ServeContent(w, r, name, time.Time{}, bytes.NewReader(content))
+}
+
+// ServeError writes the error of fetching an image, missing images being distinguished from
+// upstream failures
+func ServeError(w http.ResponseWriter, id int, err error) {
+	if IsNotFound(err) {
+		http.Error(w, fmt.Sprintf("image %d not found", id), http.StatusNotFound)
+		return
+	}
+
+	log.Printf("failed to fetch image %d: %s", id, err)
+	http.Error(w, fmt.Sprintf("failed to fetch image %d", id), http.StatusBadGateway)
+}
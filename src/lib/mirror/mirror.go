@@ -0,0 +1,206 @@
+/*
+Copyright © 2024 Nicolas Goudry <goudry.nicolas@gmail.com>
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package mirror
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"sync"
+
+	"earth-view/lib"
+)
+
+// FetchUpstream fetches an asset from upstream, decoding its image
+func FetchUpstream(id int) (*lib.Asset, error) {
+	asset := &lib.Asset{Id: id}
+	if _, err := asset.GetContent(); err != nil {
+		return nil, err
+	}
+
+	return asset, nil
+}
+
+// Mirror is a directory holding images and their metadata, which are fetched from upstream on a
+// cache miss
+// Images are saved as '<id>.jpeg', like the fetch commands do, and their metadata without the
+// encoded image as '<id>.json'
+type Mirror struct {
+	Dir string
+	// Fetch fetches assets missing from the mirror
+	Fetch func(id int) (*lib.Asset, error)
+	// OnFetch is called with assets fetched from upstream, one at a time, if set
+	OnFetch func(asset *lib.Asset)
+
+	mu      sync.Mutex
+	locks   map[int]*sync.Mutex
+	fetchMu sync.Mutex
+}
+
+// New returns the mirror stored in the given directory
+func New(dir string) *Mirror {
+	return &Mirror{Dir: dir, Fetch: FetchUpstream, locks: make(map[int]*sync.Mutex)}
+}
+
+// IsNotFound returns whether the error was caused by an image missing upstream
+func IsNotFound(err error) bool {
+	return errors.Is(err, lib.ErrNotFound)
+}
+
+// ImagePath returns the path of the image file in the mirror
+func (m *Mirror) ImagePath(id int) string {
+	return filepath.Join(m.Dir, strconv.Itoa(id)+".jpeg")
+}
+
+// MetadataPath returns the path of the metadata file in the mirror
+func (m *Mirror) MetadataPath(id int) string {
+	return filepath.Join(m.Dir, strconv.Itoa(id)+".json")
+}
+
+// Ids returns the identifiers of the images in the mirror, sorted
+func (m *Mirror) Ids() ([]int, error) {
+	files, err := os.ReadDir(m.Dir)
+	if err != nil {
+		return nil, err
+	}
+
+	var ids []int
+	for _, file := range files {
+		if filepath.Ext(file.Name()) != ".jpeg" {
+			continue
+		}
+
+		if id, ok := lib.IdFromPath(file.Name()); ok {
+			ids = append(ids, id)
+		}
+	}
+
+	// Images derived from another one share its identifier
+	slices.Sort(ids)
+
+	return slices.Compact(ids), nil
+}
+
+// lock locks the image with given identifier, so that it is fetched only once at a time
+func (m *Mirror) lock(id int) func() {
+	m.mu.Lock()
+	lock, ok := m.locks[id]
+	if !ok {
+		lock = &sync.Mutex{}
+		m.locks[id] = lock
+	}
+	m.mu.Unlock()
+
+	lock.Lock()
+
+	return lock.Unlock
+}
+
+// ensure fetches the image with given identifier from upstream if the given file is missing
+// Both the image and its metadata are saved, the image being kept if it already exists
+func (m *Mirror) ensure(id int, filePath string) error {
+	unlock := m.lock(id)
+	defer unlock()
+
+	if lib.FileExists(filePath) {
+		return nil
+	}
+
+	asset, err := m.Fetch(id)
+	if err != nil {
+		return err
+	}
+
+	if m.OnFetch != nil {
+		m.fetchMu.Lock()
+		m.OnFetch(asset)
+		m.fetchMu.Unlock()
+	}
+
+	if err := os.MkdirAll(m.Dir, 0755); err != nil {
+		return err
+	}
+
+	if !lib.FileExists(m.ImagePath(id)) {
+		if err := lib.WriteFileAtomic(m.ImagePath(id), asset.Content, 0644); err != nil {
+			return err
+		}
+	}
+
+	metadata := maps.Clone(asset.Metadata)
+	delete(metadata, "dataUri")
+
+	content, err := json.Marshal(metadata)
+	if err != nil {
+		return err
+	}
+
+	return lib.WriteFileAtomic(m.MetadataPath(id), content, 0644)
+}
+
+// Image returns the content of the image with given identifier, fetching it on a cache miss
+func (m *Mirror) Image(id int) ([]byte, error) {
+	if err := m.ensure(id, m.ImagePath(id)); err != nil {
+		return nil, err
+	}
+
+	return os.ReadFile(m.ImagePath(id))
+}
+
+// Metadata returns the metadata of the image with given identifier, without the encoded image,
+// fetching it on a cache miss
+func (m *Mirror) Metadata(id int) ([]byte, error) {
+	if err := m.ensure(id, m.MetadataPath(id)); err != nil {
+		return nil, err
+	}
+
+	return os.ReadFile(m.MetadataPath(id))
+}
+
+// Asset returns the asset with given identifier as served upstream, its metadata holding the
+// encoded image, fetching it on a cache miss
+func (m *Mirror) Asset(id int) ([]byte, error) {
+	content, err := m.Metadata(id)
+	if err != nil {
+		return nil, err
+	}
+
+	var metadata map[string]interface{}
+	if err := json.Unmarshal(content, &metadata); err != nil {
+		return nil, fmt.Errorf("invalid metadata of image %d: %s", id, err)
+	}
+
+	image, err := m.Image(id)
+	if err != nil {
+		return nil, err
+	}
+
+	metadata["dataUri"] = "data:image/jpeg;base64," + base64.StdEncoding.EncodeToString(image)
+
+	return json.Marshal(metadata)
+}
@@ -0,0 +1,92 @@
+package mirror
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"testing"
+
+	"earth-view/lib"
+)
+
+// newTestMirror returns a mirror in a temporary directory, counting fetches of known assets
+func newTestMirror(t *testing.T, fetches *int) *Mirror {
+	m := New(t.TempDir())
+	m.Fetch = func(id int) (*lib.Asset, error) {
+		*fetches++
+		if id != 1003 {
+			return nil, fmt.Errorf("[%d] fetch failed: %w", id, lib.ErrNotFound)
+		}
+
+		return &lib.Asset{
+			Id: id,
+			Metadata: map[string]interface{}{
+				"country": "France",
+				"dataUri": "data:image/jpeg;base64,",
+			},
+			Content: []byte("image"),
+		}, nil
+	}
+
+	return m
+}
+
+func TestImage(t *testing.T) {
+	var fetches int
+	m := newTestMirror(t, &fetches)
+
+	for i := 0; i < 2; i++ {
+		content, err := m.Image(1003)
+		if err != nil || string(content) != "image" {
+			t.Fatalf("Expected image content, got %q (%v)", content, err)
+		}
+	}
+
+	if fetches != 1 {
+		t.Fatalf("Expected image to be fetched once, got %d fetches", fetches)
+	}
+
+	if _, err := m.Metadata(1003); err != nil || fetches != 1 {
+		t.Fatalf(
+			"Expected metadata to be saved along with image, got %d fetches (%v)",
+			fetches,
+			err,
+		)
+	}
+}
+
+func TestMetadata(t *testing.T) {
+	var fetches int
+	m := newTestMirror(t, &fetches)
+
+	content, err := m.Metadata(1003)
+	if err != nil {
+		t.Fatalf("Expected metadata, got error: %s", err)
+	}
+
+	var metadata map[string]interface{}
+	if err := json.Unmarshal(content, &metadata); err != nil {
+		t.Fatalf("Expected JSON metadata, got error: %s", err)
+	}
+
+	if _, ok := metadata["dataUri"]; ok || metadata["country"] != "France" {
+		t.Fatalf("Expected metadata without encoded image, got %v", metadata)
+	}
+
+	if !lib.FileExists(m.ImagePath(1003)) {
+		t.Fatalf("Expected image to be saved along with metadata")
+	}
+}
+
+func TestNotFound(t *testing.T) {
+	var fetches int
+	m := newTestMirror(t, &fetches)
+
+	if _, err := m.Image(999); !IsNotFound(err) {
+		t.Fatalf("Expected not found error, got %v", err)
+	}
+
+	if entries, _ := os.ReadDir(m.Dir); len(entries) != 0 {
+		t.Fatalf("Expected nothing to be saved, got %d files", len(entries))
+	}
+}
@@ -0,0 +1,109 @@
+/*
+Copyright © 2024 Nicolas Goudry <goudry.nicolas@gmail.com>
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package palette
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+)
+
+// Color is an opaque sRGB color
+type Color struct {
+	R uint8
+	G uint8
+	B uint8
+}
+
+// Hex formats the color as '#rrggbb'
+func (c Color) Hex() string {
+	return fmt.Sprintf("#%02x%02x%02x", c.R, c.G, c.B)
+}
+
+func (c Color) MarshalJSON() ([]byte, error) {
+	return json.Marshal(c.Hex())
+}
+
+// Luminance returns the relative luminance of the color as defined by WCAG, between 0 (black) and
+// 1 (white)
+func (c Color) Luminance() float64 {
+	return 0.2126*linear(c.R) + 0.7152*linear(c.G) + 0.0722*linear(c.B)
+}
+
+// linear returns the linear value of a gamma-encoded sRGB channel, between 0 and 1
+func linear(channel uint8) float64 {
+	value := float64(channel) / 0xff
+	if value <= 0.04045 {
+		return value / 12.92
+	}
+
+	return math.Pow((value+0.055)/1.055, 2.4)
+}
+
+// Contrast returns the contrast ratio between two colors as defined by WCAG, from 1 to 21
+// Text needs a ratio of at least 4.5 to meet level AA, and 7 to meet level AAA
+func Contrast(a Color, b Color) float64 {
+	la, lb := a.Luminance(), b.Luminance()
+	if la < lb {
+		la, lb = lb, la
+	}
+
+	return (la + 0.05) / (lb + 0.05)
+}
+
+// Level returns the WCAG conformance level of text with the given contrast ratio, or an empty
+// string if it does not meet any
+func Level(contrast float64) string {
+	switch {
+	case contrast >= 7:
+		return "AAA"
+	case contrast >= 4.5:
+		return "AA"
+	}
+
+	return ""
+}
+
+// Mix returns the color between a and b at the given position, from 0 (a) to 1 (b)
+func Mix(a Color, b Color, t float64) Color {
+	mix := func(x uint8, y uint8) uint8 {
+		return uint8(math.Round(float64(x) + (float64(y)-float64(x))*t))
+	}
+
+	return Color{R: mix(a.R, b.R), G: mix(a.G, b.G), B: mix(a.B, b.B)}
+}
+
+// Saturation returns the saturation of the color in the HSL model, between 0 and 1
+func (c Color) Saturation() float64 {
+	high := float64(max(c.R, c.G, c.B)) / 0xff
+	low := float64(min(c.R, c.G, c.B)) / 0xff
+	if high == low {
+		return 0
+	}
+
+	lightness := (high + low) / 2
+	if lightness > 0.5 {
+		return (high - low) / (2 - high - low)
+	}
+
+	return (high - low) / (high + low)
+}
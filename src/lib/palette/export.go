@@ -0,0 +1,103 @@
+/*
+Copyright © 2024 Nicolas Goudry <goudry.nicolas@gmail.com>
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package palette
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// Formats lists the formats palettes can be exported to
+var Formats = []string{"json", "xresources", "kitty", "alacritty", "foot", "css"}
+
+// ansiNames lists the names of the 8 normal terminal colors, as used by alacritty
+var ansiNames = []string{"black", "red", "green", "yellow", "blue", "magenta", "cyan", "white"}
+
+// Export formats the palette in the given format
+func Export(p Palette, format string) (string, error) {
+	var out strings.Builder
+
+	switch format {
+	case "json":
+		content, err := json.MarshalIndent(p, "", "  ")
+		if err != nil {
+			return "", err
+		}
+
+		return string(content), nil
+	case "xresources":
+		fmt.Fprintf(&out, "*.background: %s\n", p.Background.Hex())
+		fmt.Fprintf(&out, "*.foreground: %s\n", p.Foreground.Hex())
+		fmt.Fprintf(&out, "*.cursorColor: %s\n", p.Foreground.Hex())
+		for i, c := range p.Colors {
+			fmt.Fprintf(&out, "*.color%d: %s\n", i, c.Hex())
+		}
+	case "kitty":
+		fmt.Fprintf(&out, "background %s\n", p.Background.Hex())
+		fmt.Fprintf(&out, "foreground %s\n", p.Foreground.Hex())
+		fmt.Fprintf(&out, "cursor %s\n", p.Foreground.Hex())
+		fmt.Fprintf(&out, "selection_background %s\n", p.Foreground.Hex())
+		fmt.Fprintf(&out, "selection_foreground %s\n", p.Background.Hex())
+		for i, c := range p.Colors {
+			fmt.Fprintf(&out, "color%d %s\n", i, c.Hex())
+		}
+	case "alacritty":
+		fmt.Fprintf(&out, "[colors.primary]\n")
+		fmt.Fprintf(&out, "background = %q\n", p.Background.Hex())
+		fmt.Fprintf(&out, "foreground = %q\n", p.Foreground.Hex())
+		for i, section := range []string{"normal", "bright"} {
+			fmt.Fprintf(&out, "\n[colors.%s]\n", section)
+			for j, name := range ansiNames {
+				fmt.Fprintf(&out, "%s = %q\n", name, p.Colors[i*8+j].Hex())
+			}
+		}
+	case "foot":
+		fmt.Fprintf(&out, "[colors]\n")
+		fmt.Fprintf(&out, "background=%s\n", p.Background.Hex()[1:])
+		fmt.Fprintf(&out, "foreground=%s\n", p.Foreground.Hex()[1:])
+		for i, section := range []string{"regular", "bright"} {
+			for j := 0; j < 8; j++ {
+				fmt.Fprintf(&out, "%s%d=%s\n", section, j, p.Colors[i*8+j].Hex()[1:])
+			}
+		}
+	case "css":
+		fmt.Fprintf(&out, ":root {\n")
+		fmt.Fprintf(&out, "  --background: %s;\n", p.Background.Hex())
+		fmt.Fprintf(&out, "  --foreground: %s;\n", p.Foreground.Hex())
+		for i, c := range p.Colors {
+			fmt.Fprintf(&out, "  --color%d: %s;\n", i, c.Hex())
+		}
+		for i, swatch := range p.Dominant {
+			fmt.Fprintf(&out, "  --dominant%d: %s;\n", i, swatch.Color.Hex())
+		}
+		fmt.Fprintf(&out, "}\n")
+	default:
+		return "", fmt.Errorf(
+			"invalid format: %s. Supported formats are: %s",
+			format,
+			strings.Join(Formats, ", "),
+		)
+	}
+
+	return strings.TrimSuffix(out.String(), "\n"), nil
+}
@@ -0,0 +1,248 @@
+/*
+Copyright © 2024 Nicolas Goudry <goudry.nicolas@gmail.com>
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package palette
+
+import (
+	"fmt"
+	"image"
+	"math"
+	"math/rand"
+	"sort"
+	"strings"
+)
+
+const (
+	// maxSamples is the approximate number of pixels sampled from images to find their colors
+	maxSamples = 20000
+	// maxIterations bounds the number of k-means iterations, which usually converge much sooner
+	maxIterations = 32
+)
+
+// Methods lists the methods dominant colors can be extracted with
+var Methods = []string{"kmeans", "mediancut"}
+
+// Swatch is a dominant color of an image, along with the share of its pixels it stands for
+type Swatch struct {
+	Color  Color   `json:"color"`
+	Weight float64 `json:"weight"`
+}
+
+// point is a color with channels as floating point numbers, for averaging and measuring distances
+type point [3]float64
+
+func (p point) color() Color {
+	return Color{
+		R: uint8(math.Round(p[0])),
+		G: uint8(math.Round(p[1])),
+		B: uint8(math.Round(p[2])),
+	}
+}
+
+func distance(a point, b point) float64 {
+	dr, dg, db := a[0]-b[0], a[1]-b[1], a[2]-b[2]
+	return dr*dr + dg*dg + db*db
+}
+
+// samplePixels returns the colors of pixels evenly sampled from the image
+func samplePixels(img image.Image) []point {
+	bounds := img.Bounds()
+	step := max(int(math.Sqrt(float64(bounds.Dx()*bounds.Dy())/maxSamples)), 1)
+
+	var points []point
+	for y := bounds.Min.Y; y < bounds.Max.Y; y += step {
+		for x := bounds.Min.X; x < bounds.Max.X; x += step {
+			r, g, b, _ := img.At(x, y).RGBA()
+			points = append(points, point{float64(r >> 8), float64(g >> 8), float64(b >> 8)})
+		}
+	}
+
+	return points
+}
+
+// Dominant returns up to the given number of dominant colors of the image, the most common first,
+// extracted with the given method
+func Dominant(img image.Image, count int, method string) ([]Swatch, error) {
+	if count <= 0 {
+		return nil, fmt.Errorf("invalid number of colors: %d. Expected a positive number", count)
+	}
+
+	points := samplePixels(img)
+	if len(points) == 0 {
+		return nil, fmt.Errorf("cannot extract colors from an empty image")
+	}
+
+	var clusters [][]point
+	switch method {
+	case "kmeans":
+		clusters = kMeans(points, count)
+	case "mediancut":
+		clusters = medianCut(points, count)
+	default:
+		return nil, fmt.Errorf(
+			"invalid method: %s. Supported methods are: %s",
+			method,
+			strings.Join(Methods, ", "),
+		)
+	}
+
+	var swatches []Swatch
+	for _, cluster := range clusters {
+		if len(cluster) == 0 {
+			continue
+		}
+
+		swatches = append(swatches, Swatch{
+			Color:  mean(cluster).color(),
+			Weight: float64(len(cluster)) / float64(len(points)),
+		})
+	}
+
+	sort.SliceStable(swatches, func(i, j int) bool {
+		return swatches[i].Weight > swatches[j].Weight
+	})
+
+	return swatches, nil
+}
+
+func mean(points []point) point {
+	var sum point
+	for _, p := range points {
+		for c := range sum {
+			sum[c] += p[c]
+		}
+	}
+
+	for c := range sum {
+		sum[c] /= float64(len(points))
+	}
+
+	return sum
+}
+
+// kMeans groups points in the given number of clusters around their means
+// Initial means are picked with k-means++ from a fixed seed, so that results are reproducible
+func kMeans(points []point, k int) [][]point {
+	random := rand.New(rand.NewSource(1))
+
+	centers := []point{points[random.Intn(len(points))]}
+	distances := make([]float64, len(points))
+	for len(centers) < k {
+		// Points far from existing centers are more likely to be picked
+		var total float64
+		for i, p := range points {
+			distances[i] = math.Inf(1)
+			for _, center := range centers {
+				distances[i] = math.Min(distances[i], distance(p, center))
+			}
+			total += distances[i]
+		}
+
+		if total == 0 {
+			break
+		}
+
+		target := random.Float64() * total
+		for i, d := range distances {
+			target -= d
+			if target <= 0 {
+				centers = append(centers, points[i])
+				break
+			}
+		}
+	}
+
+	assignments := make([]int, len(points))
+	var clusters [][]point
+
+	for iteration := 0; iteration < maxIterations; iteration++ {
+		changed := iteration == 0
+		for i, p := range points {
+			nearest := 0
+			for j, center := range centers {
+				if distance(p, center) < distance(p, centers[nearest]) {
+					nearest = j
+				}
+			}
+
+			if assignments[i] != nearest {
+				assignments[i] = nearest
+				changed = true
+			}
+		}
+
+		clusters = make([][]point, len(centers))
+		for i, p := range points {
+			clusters[assignments[i]] = append(clusters[assignments[i]], p)
+		}
+
+		if !changed {
+			break
+		}
+
+		for j, cluster := range clusters {
+			if len(cluster) > 0 {
+				centers[j] = mean(cluster)
+			}
+		}
+	}
+
+	return clusters
+}
+
+// medianCut splits points in the given number of boxes, repeatedly cutting the box with the widest
+// range of a channel at the median of this channel
+func medianCut(points []point, count int) [][]point {
+	boxes := [][]point{points}
+	for len(boxes) < count {
+		widest, channel, widestRange := -1, 0, 0.0
+		for i, box := range boxes {
+			if len(box) < 2 {
+				continue
+			}
+
+			for c := 0; c < 3; c++ {
+				low, high := math.Inf(1), math.Inf(-1)
+				for _, p := range box {
+					low, high = math.Min(low, p[c]), math.Max(high, p[c])
+				}
+
+				if high-low > widestRange {
+					widest, channel, widestRange = i, c, high-low
+				}
+			}
+		}
+
+		// All boxes hold a single color
+		if widest < 0 {
+			break
+		}
+
+		box := boxes[widest]
+		sort.Slice(box, func(i, j int) bool { return box[i][channel] < box[j][channel] })
+
+		median := len(box) / 2
+		boxes[widest] = box[:median]
+		boxes = append(boxes, box[median:])
+	}
+
+	return boxes
+}
@@ -0,0 +1,147 @@
+/*
+Copyright © 2024 Nicolas Goudry <goudry.nicolas@gmail.com>
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package palette
+
+import (
+	"slices"
+	"sort"
+)
+
+const (
+	// foregroundContrast is the minimum contrast of the foreground, to meet WCAG level AAA
+	foregroundContrast = 7
+	// mutedContrast is the minimum contrast of the muted colors used for secondary text
+	mutedContrast = 3
+	// similarDistance is the distance between colors under which they are too close to be used as
+	// distinct accents
+	similarDistance = 32
+	// mixStep is the step by which colors are mixed with black or white to reach a contrast
+	mixStep = 0.02
+)
+
+var (
+	black = Color{}
+	white = Color{R: 0xff, G: 0xff, B: 0xff}
+)
+
+// Palette is a set of colors for terminal and desktop themes, derived from the dominant colors of
+// an image
+type Palette struct {
+	Dark       bool      `json:"dark"`
+	Background Color     `json:"background"`
+	Foreground Color     `json:"foreground"`
+	Contrast   float64   `json:"contrast"`
+	Level      string    `json:"level"`
+	Colors     [16]Color `json:"colors"`
+	Dominant   []Swatch  `json:"dominant"`
+}
+
+// Build derives a palette from dominant colors, with a dark or light background
+// The background is the darkest or lightest dominant color pushed towards black or white, and the
+// foreground is the opposite one with a contrast meeting WCAG level AAA. The 16 terminal colors
+// are the background, the most saturated dominant colors and the foreground, each one adjusted to
+// have at least the given contrast with the background
+func Build(dominant []Swatch, dark bool, minContrast float64) Palette {
+	colors := make([]Color, len(dominant))
+	for i, swatch := range dominant {
+		colors[i] = swatch.Color
+	}
+
+	// Dominant colors ordered from the darkest to the lightest
+	byLuminance := append([]Color(nil), colors...)
+	sort.SliceStable(byLuminance, func(i, j int) bool {
+		return byLuminance[i].Luminance() < byLuminance[j].Luminance()
+	})
+
+	background, foreground := black, white
+	if len(byLuminance) > 0 {
+		background, foreground = byLuminance[0], byLuminance[len(byLuminance)-1]
+	}
+
+	toward := white
+	if dark {
+		background = Mix(background, black, 0.7)
+	} else {
+		background, foreground = Mix(foreground, white, 0.85), background
+		toward = black
+	}
+
+	foreground = ensureContrast(foreground, background, foregroundContrast, toward)
+
+	// Accents are the most saturated distinct dominant colors, repeated if there are not enough of
+	// them
+	sorted := append([]Color(nil), colors...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].Saturation() > sorted[j].Saturation()
+	})
+
+	var accents []Color
+	for _, c := range sorted {
+		if !slices.ContainsFunc(accents, func(accent Color) bool { return similar(c, accent) }) {
+			accents = append(accents, c)
+		}
+	}
+	if len(accents) == 0 {
+		accents = []Color{foreground}
+	}
+
+	p := Palette{
+		Dark:       dark,
+		Background: background,
+		Foreground: foreground,
+		Contrast:   Contrast(foreground, background),
+		Dominant:   dominant,
+	}
+	p.Level = Level(p.Contrast)
+
+	p.Colors[0] = background
+	p.Colors[7] = ensureContrast(Mix(foreground, background, 0.15), background, minContrast, toward)
+	p.Colors[8] = ensureContrast(Mix(background, foreground, 0.3), background, mutedContrast, toward)
+	p.Colors[15] = foreground
+
+	for i := 0; i < 6; i++ {
+		accent := ensureContrast(accents[i%len(accents)], background, minContrast, toward)
+		p.Colors[1+i] = accent
+		p.Colors[9+i] = ensureContrast(Mix(accent, toward, 0.25), background, minContrast, toward)
+	}
+
+	return p
+}
+
+// similar returns whether two colors are too close to tell apart as accents
+func similar(a Color, b Color) bool {
+	dr, dg, db := int(a.R)-int(b.R), int(a.G)-int(b.G), int(a.B)-int(b.B)
+	return dr*dr+dg*dg+db*db < similarDistance*similarDistance
+}
+
+// ensureContrast mixes the color with the given target color until its contrast with the
+// background reaches the minimum, or the target is reached
+func ensureContrast(c Color, background Color, minimum float64, target Color) Color {
+	for t := 0.0; t < 1; t += mixStep {
+		mixed := Mix(c, target, t)
+		if Contrast(mixed, background) >= minimum {
+			return mixed
+		}
+	}
+
+	return target
+}
@@ -0,0 +1,136 @@
+package palette
+
+import (
+	"image"
+	"image/color"
+	"math"
+	"strings"
+	"testing"
+)
+
+// stripes returns an image of vertical stripes of the given colors, of equal widths
+func stripes(colors ...Color) image.Image {
+	img := image.NewRGBA(image.Rect(0, 0, 30*len(colors), 20))
+	for y := 0; y < 20; y++ {
+		for x := 0; x < img.Rect.Dx(); x++ {
+			c := colors[x/30]
+			img.Set(x, y, color.RGBA{R: c.R, G: c.G, B: c.B, A: 0xff})
+		}
+	}
+
+	return img
+}
+
+var (
+	navy   = Color{R: 20, G: 60, B: 120}
+	sand   = Color{R: 200, G: 180, B: 120}
+	forest = Color{R: 40, G: 120, B: 60}
+)
+
+func TestContrast(t *testing.T) {
+	if contrast := Contrast(black, white); math.Abs(contrast-21) > 1e-9 {
+		t.Fatalf("Expected contrast of 21 between black and white, got %g", contrast)
+	}
+
+	if contrast := Contrast(sand, sand); contrast != 1 {
+		t.Fatalf("Expected contrast of 1 between identical colors, got %g", contrast)
+	}
+
+	for _, tc := range []struct {
+		contrast float64
+		level    string
+	}{{7.2, "AAA"}, {4.5, "AA"}, {3, ""}} {
+		if level := Level(tc.contrast); level != tc.level {
+			t.Fatalf("Expected level %q for contrast %g, got %q", tc.level, tc.contrast, level)
+		}
+	}
+}
+
+func TestDominant(t *testing.T) {
+	img := stripes(navy, sand, forest, navy)
+
+	for _, method := range Methods {
+		swatches, err := Dominant(img, 3, method)
+		if err != nil {
+			t.Fatalf("Expected colors extracted with %s, got error: %s", method, err)
+		}
+
+		if len(swatches) != 3 {
+			t.Fatalf("Expected 3 colors with %s, got %d", method, len(swatches))
+		}
+
+		if swatches[0].Color != navy || math.Abs(swatches[0].Weight-0.5) > 0.01 {
+			t.Fatalf(
+				"Expected navy to be the most common color with %s, got %+v",
+				method,
+				swatches[0],
+			)
+		}
+	}
+
+	if _, err := Dominant(img, 3, "octree"); err == nil {
+		t.Fatalf("Expected error for unknown method")
+	}
+
+	if _, err := Dominant(img, 0, "kmeans"); err == nil {
+		t.Fatalf("Expected error for invalid number of colors")
+	}
+}
+
+func TestBuild(t *testing.T) {
+	dominant := []Swatch{{navy, 0.5}, {sand, 0.25}, {forest, 0.25}}
+
+	for _, dark := range []bool{true, false} {
+		p := Build(dominant, dark, 4.5)
+
+		if dark != (p.Background.Luminance() < p.Foreground.Luminance()) {
+			t.Fatalf("Expected background to match dark mode %t, got %+v", dark, p)
+		}
+
+		if p.Contrast < 7 || p.Level != "AAA" {
+			t.Fatalf("Expected foreground to meet level AAA, got contrast %g", p.Contrast)
+		}
+
+		for i, c := range p.Colors[1:] {
+			minimum := 4.5
+			if i+1 == 8 {
+				minimum = 3
+			}
+
+			if contrast := Contrast(c, p.Background); contrast < minimum {
+				t.Fatalf("Expected color %d to have contrast of %g, got %g", i+1, minimum, contrast)
+			}
+		}
+	}
+
+	// Without dominant colors, the palette falls back to black and white
+	if p := Build(nil, true, 4.5); p.Background != black || p.Foreground != white {
+		t.Fatalf("Expected black and white palette, got %+v", p)
+	}
+}
+
+func TestExport(t *testing.T) {
+	p := Build([]Swatch{{navy, 0.5}, {sand, 0.5}}, true, 4.5)
+
+	for format, expected := range map[string]string{
+		"json":       `"background": "` + p.Background.Hex() + `"`,
+		"xresources": "*.color15: " + p.Foreground.Hex(),
+		"kitty":      "color1 " + p.Colors[1].Hex(),
+		"alacritty":  "[colors.bright]\nblack = \"" + p.Colors[8].Hex() + `"`,
+		"foot":       "regular0=" + p.Background.Hex()[1:],
+		"css":        "--dominant1: " + sand.Hex() + ";",
+	} {
+		out, err := Export(p, format)
+		if err != nil {
+			t.Fatalf("Expected palette exported to %s, got error: %s", format, err)
+		}
+
+		if !strings.Contains(out, expected) {
+			t.Fatalf("Expected %s export to contain %q, got:\n%s", format, expected, out)
+		}
+	}
+
+	if _, err := Export(p, "yaml"); err == nil {
+		t.Fatalf("Expected error for unknown format")
+	}
+}
@@ -0,0 +1,147 @@
+/*
+Copyright © 2024 Nicolas Goudry <goudry.nicolas@gmail.com>
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package lib
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"time"
+)
+
+// Preference represents an image marked as favorite or banned
+// Location metadata is saved along with the identifier so that it can be listed offline
+type Preference struct {
+	Id          int       `json:"id"`
+	Time        time.Time `json:"time"`
+	Country     string    `json:"country,omitempty"`
+	Region      string    `json:"region,omitempty"`
+	Attribution string    `json:"attribution,omitempty"`
+}
+
+// Preferences holds the favorite and banned images
+// They are stored in $XDG_STATE_HOME/earth-view/preferences.json
+type Preferences struct {
+	Favorites []Preference `json:"favorites"`
+	Banned    []Preference `json:"banned"`
+}
+
+// PreferencesPath returns the path of the preferences file
+func PreferencesPath() string {
+	return filepath.Join(StateHome(), "earth-view", "preferences.json")
+}
+
+// LoadPreferences reads the preferences file, returning empty preferences if there is none
+func LoadPreferences() (*Preferences, error) {
+	p := &Preferences{}
+
+	content, err := os.ReadFile(PreferencesPath())
+	if errors.Is(err, os.ErrNotExist) {
+		return p, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	if err := json.Unmarshal(content, p); err != nil {
+		return nil, fmt.Errorf("invalid preferences file: %s", err)
+	}
+
+	return p, nil
+}
+
+// Save writes the preferences file
+func (p *Preferences) Save() error {
+	content, err := json.MarshalIndent(p, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(PreferencesPath()), 0700); err != nil {
+		return err
+	}
+
+	return os.WriteFile(PreferencesPath(), content, 0600)
+}
+
+// Like marks an image as favorite, removing it from the banned images
+// It returns false if the image already was a favorite
+func (p *Preferences) Like(pref Preference) bool {
+	p.Banned = removePreference(p.Banned, pref.Id)
+
+	if p.IsFavorite(pref.Id) {
+		return false
+	}
+
+	p.Favorites = append(p.Favorites, pref)
+
+	return true
+}
+
+// Unlike removes an image from the favorites
+// It returns false if the image was not a favorite
+func (p *Preferences) Unlike(id int) bool {
+	found := p.IsFavorite(id)
+	p.Favorites = removePreference(p.Favorites, id)
+
+	return found
+}
+
+// Ban marks an image as banned, removing it from the favorites
+// It returns false if the image already was banned
+func (p *Preferences) Ban(pref Preference) bool {
+	p.Favorites = removePreference(p.Favorites, pref.Id)
+
+	if p.IsBanned(pref.Id) {
+		return false
+	}
+
+	p.Banned = append(p.Banned, pref)
+
+	return true
+}
+
+// Unban removes an image from the banned images
+// It returns false if the image was not banned
+func (p *Preferences) Unban(id int) bool {
+	found := p.IsBanned(id)
+	p.Banned = removePreference(p.Banned, id)
+
+	return found
+}
+
+// IsFavorite returns whether an image is a favorite
+func (p *Preferences) IsFavorite(id int) bool {
+	return slices.ContainsFunc(p.Favorites, func(pref Preference) bool { return pref.Id == id })
+}
+
+// IsBanned returns whether an image is banned
+func (p *Preferences) IsBanned(id int) bool {
+	return slices.ContainsFunc(p.Banned, func(pref Preference) bool { return pref.Id == id })
+}
+
+// removePreference returns the preferences without the one of the given image
+func removePreference(prefs []Preference, id int) []Preference {
+	return slices.DeleteFunc(prefs, func(pref Preference) bool { return pref.Id == id })
+}
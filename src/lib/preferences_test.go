@@ -0,0 +1,52 @@
+package lib
+
+import (
+	"testing"
+)
+
+func TestPreferences(t *testing.T) {
+	t.Setenv("XDG_STATE_HOME", t.TempDir())
+
+	prefs, err := LoadPreferences()
+	if err != nil {
+		t.Fatalf("Expected no error while loading missing preferences, got %v", err)
+	}
+
+	if !prefs.Like(Preference{Id: 1003, Country: "France"}) {
+		t.Fatalf("Expected 1003 to be added to favorites")
+	}
+
+	if prefs.Like(Preference{Id: 1003}) {
+		t.Fatalf("Expected 1003 not to be added twice to favorites")
+	}
+
+	prefs.Ban(Preference{Id: 1004})
+	if err := prefs.Save(); err != nil {
+		t.Fatalf("Expected no error while saving preferences, got %v", err)
+	}
+
+	prefs, err = LoadPreferences()
+	if err != nil {
+		t.Fatalf("Expected no error while loading preferences, got %v", err)
+	}
+
+	if !prefs.IsFavorite(1003) || prefs.Favorites[0].Country != "France" || !prefs.IsBanned(1004) {
+		t.Fatalf("Expected preferences to be saved, got %+v", prefs)
+	}
+
+	// Banning a favorite removes it from favorites, and the other way around
+	prefs.Ban(Preference{Id: 1003})
+	prefs.Like(Preference{Id: 1004})
+
+	if prefs.IsFavorite(1003) || !prefs.IsBanned(1003) {
+		t.Fatalf("Expected 1003 to only be banned, got %+v", prefs)
+	}
+
+	if !prefs.IsFavorite(1004) || prefs.IsBanned(1004) {
+		t.Fatalf("Expected 1004 to only be a favorite, got %+v", prefs)
+	}
+
+	if !prefs.Unban(1003) || prefs.Unban(1003) || !prefs.Unlike(1004) || prefs.Unlike(1004) {
+		t.Fatalf("Expected images to be removed once, got %+v", prefs)
+	}
+}
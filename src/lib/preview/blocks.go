@@ -0,0 +1,78 @@
+/*
+Copyright © 2024 Nicolas Goudry <goudry.nicolas@gmail.com>
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package preview
+
+import (
+	"bufio"
+	"fmt"
+	"image"
+	"io"
+	"strings"
+)
+
+// renderBlocks displays an image with upper half block characters, the foreground color being
+// the upper pixel and the background color the lower one
+func renderBlocks(w io.Writer, img *image.RGBA) error {
+	out := bufio.NewWriter(w)
+	bounds := img.Bounds()
+
+	for y := bounds.Min.Y; y < bounds.Max.Y; y += 2 {
+		for x := bounds.Min.X; x < bounds.Max.X; x++ {
+			top := img.RGBAAt(x, y)
+
+			if y+1 < bounds.Max.Y {
+				bottom := img.RGBAAt(x, y+1)
+				fmt.Fprintf(
+					out,
+					"\x1b[38;2;%d;%d;%d;48;2;%d;%d;%dm▀",
+					top.R, top.G, top.B,
+					bottom.R, bottom.G, bottom.B,
+				)
+			} else {
+				fmt.Fprintf(out, "\x1b[38;2;%d;%d;%d;49m▀", top.R, top.G, top.B)
+			}
+		}
+
+		out.WriteString("\x1b[0m\n")
+	}
+
+	return out.Flush()
+}
+
+// BlocksString renders an image with half block characters fit in the given number of columns and
+// rows, for use in terminal user interfaces
+func BlocksString(img image.Image, cols int, rows int) (string, error) {
+	// Half blocks are about square, assuming cells twice as high as wide
+	cols, rows = fitCells(img.Bounds().Size(), Options{
+		Columns:    cols,
+		Rows:       rows,
+		CellWidth:  1,
+		CellHeight: 2,
+	})
+
+	var out strings.Builder
+	if err := renderBlocks(&out, scale(img, cols, rows*2)); err != nil {
+		return "", err
+	}
+
+	return strings.TrimSuffix(out.String(), "\n"), nil
+}
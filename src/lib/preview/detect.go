@@ -0,0 +1,75 @@
+/*
+Copyright © 2024 Nicolas Goudry <goudry.nicolas@gmail.com>
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package preview
+
+import (
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// sixelTerms lists terminals known to support Sixel by their TERM value
+var sixelTerms = []string{"foot", "foot-extra", "mlterm", "yaft-256color", "contour"}
+
+// deviceAttributes matches the primary device attributes reported by the terminal
+var deviceAttributes = regexp.MustCompile(`\x1b\[\?([0-9;]*)c`)
+
+// Detect returns the best protocol supported by the terminal
+// The environment is checked first since it is forwarded over SSH for most of the relevant
+// variables, then the terminal is queried for Sixel support
+func Detect(getenv func(string) string, query func() string) Protocol {
+	term := getenv("TERM")
+	program := getenv("TERM_PROGRAM")
+
+	// Graphics protocols do not go through terminal multiplexers without extra configuration
+	if getenv("TMUX") != "" || strings.HasPrefix(term, "screen") {
+		return Blocks
+	}
+
+	switch {
+	case getenv("KITTY_WINDOW_ID") != "",
+		term == "xterm-kitty",
+		term == "xterm-ghostty",
+		program == "ghostty":
+		return Kitty
+	case program == "iTerm.app", getenv("LC_TERMINAL") == "iTerm2", program == "WezTerm":
+		return Iterm
+	case slices.Contains(sixelTerms, term), strings.Contains(term, "sixel"):
+		return Sixel
+	}
+
+	if supportsSixel(query()) {
+		return Sixel
+	}
+
+	return Blocks
+}
+
+// supportsSixel returns whether primary device attributes include Sixel graphics, reported as 4
+func supportsSixel(response string) bool {
+	match := deviceAttributes.FindStringSubmatch(response)
+	if match == nil {
+		return false
+	}
+
+	return slices.Contains(strings.Split(match[1], ";"), "4")
+}
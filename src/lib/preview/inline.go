@@ -0,0 +1,106 @@
+/*
+Copyright © 2024 Nicolas Goudry <goudry.nicolas@gmail.com>
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package preview
+
+import (
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"image"
+	"image/png"
+	"io"
+)
+
+// kittyChunkSize is the maximum size of base64 data sent in a single Kitty graphics command
+const kittyChunkSize = 4096
+
+// encodePng returns the base64 encoded PNG data of an image, along with its size in bytes
+func encodePng(img image.Image) (string, int, error) {
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		return "", 0, err
+	}
+
+	return base64.StdEncoding.EncodeToString(buf.Bytes()), buf.Len(), nil
+}
+
+// renderKitty displays an image with the Kitty graphics protocol, over the given cells
+// The image is transmitted as PNG data, split in chunks as required by the protocol
+func renderKitty(w io.Writer, img image.Image, cols int, rows int) error {
+	data, _, err := encodePng(img)
+	if err != nil {
+		return err
+	}
+
+	for i := 0; i < len(data); i += kittyChunkSize {
+		end := min(i+kittyChunkSize, len(data))
+
+		more := 1
+		if end == len(data) {
+			more = 0
+		}
+
+		var control string
+		if i == 0 {
+			control = fmt.Sprintf("a=T,f=100,q=2,c=%d,r=%d,", cols, rows)
+		}
+
+		if _, err := fmt.Fprintf(w, "\x1b_G%sm=%d;%s\x1b\\", control, more, data[i:end]); err != nil {
+			return err
+		}
+	}
+
+	_, err = fmt.Fprintln(w)
+
+	return err
+}
+
+// renderIterm displays an image with the iTerm2 inline images protocol, over the given cells
+func renderIterm(w io.Writer, img image.Image, cols int, rows int) error {
+	data, size, err := encodePng(img)
+	if err != nil {
+		return err
+	}
+
+	_, err = fmt.Fprintf(
+		w,
+		"\x1b]1337;File=inline=1;size=%d;width=%d;height=%d;preserveAspectRatio=1:%s\a\n",
+		size,
+		cols,
+		rows,
+		data,
+	)
+
+	return err
+}
+
+// Clear removes the images displayed with the given protocol which would otherwise stay on screen
+// Only images displayed with the Kitty graphics protocol need to be removed
+func Clear(w io.Writer, protocol Protocol) error {
+	if protocol != Kitty {
+		return nil
+	}
+
+	_, err := fmt.Fprint(w, "\x1b_Ga=d,q=2\x1b\\")
+
+	return err
+}
@@ -0,0 +1,163 @@
+/*
+Copyright © 2024 Nicolas Goudry <goudry.nicolas@gmail.com>
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package preview
+
+import (
+	"fmt"
+	"image"
+	_ "image/jpeg"
+	"io"
+	"os"
+	"slices"
+	"strings"
+
+	"golang.org/x/image/draw"
+)
+
+// Protocol is a way of displaying images in a terminal
+type Protocol string
+
+const (
+	// Auto selects the best protocol supported by the terminal
+	Auto Protocol = "auto"
+	// Kitty is the Kitty terminal graphics protocol
+	Kitty Protocol = "kitty"
+	// Sixel is the DEC Sixel graphics format
+	Sixel Protocol = "sixel"
+	// Iterm is the iTerm2 inline images protocol
+	Iterm Protocol = "iterm"
+	// Blocks renders images with 24-bit colored half block characters, supported by most terminals
+	Blocks Protocol = "blocks"
+)
+
+// Protocols lists the supported protocols
+var Protocols = []Protocol{Auto, Kitty, Sixel, Iterm, Blocks}
+
+// ProtocolHelp describes the supported protocols, for commands help
+var ProtocolHelp = `  The '--preview' flag renders the image in the terminal. The protocol used is
+  detected from the terminal capabilities: Kitty graphics protocol, Sixel or
+  iTerm2 inline images, falling back to colored half block characters. It can
+  be forced by providing a value to the flag, e.g. '--preview=blocks'. Available
+  protocols are: auto, kitty, sixel, iterm and blocks.`
+
+// Options configures how an image is rendered
+type Options struct {
+	Protocol Protocol
+	// Columns and Rows bound the preview in terminal cells
+	Columns int
+	Rows    int
+	// CellWidth and CellHeight are the size of a terminal cell in pixels
+	CellWidth  int
+	CellHeight int
+}
+
+// ParseProtocol returns the protocol matching the given name
+func ParseProtocol(name string) (Protocol, error) {
+	protocol := Protocol(strings.ToLower(name))
+	if !slices.Contains(Protocols, protocol) {
+		names := make([]string, len(Protocols))
+		for i, p := range Protocols {
+			names[i] = string(p)
+		}
+
+		return "", fmt.Errorf(
+			"invalid preview protocol: %s. Expected one of %s",
+			name,
+			strings.Join(names, ", "),
+		)
+	}
+
+	return protocol, nil
+}
+
+// Render writes the escape sequences displaying the image with the protocol set in options
+func Render(w io.Writer, img image.Image, opts Options) error {
+	cols, rows := fitCells(img.Bounds().Size(), opts)
+
+	switch opts.Protocol {
+	case Kitty:
+		return renderKitty(w, scale(img, cols*opts.CellWidth, rows*opts.CellHeight), cols, rows)
+	case Iterm:
+		return renderIterm(w, scale(img, cols*opts.CellWidth, rows*opts.CellHeight), cols, rows)
+	case Sixel:
+		return renderSixel(w, scale(img, cols*opts.CellWidth, rows*opts.CellHeight))
+	case Blocks:
+		// Each cell displays two vertically stacked pixels
+		return renderBlocks(w, scale(img, cols, rows*2))
+	}
+
+	return fmt.Errorf("unsupported preview protocol: %s", opts.Protocol)
+}
+
+// RenderFile decodes an image file and renders it in the terminal
+func RenderFile(w io.Writer, filePath string, protocol Protocol) error {
+	file, err := os.Open(filePath)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	img, _, err := image.Decode(file)
+	if err != nil {
+		return fmt.Errorf("failed to decode image: %s", err)
+	}
+
+	return RenderImage(w, img, protocol)
+}
+
+// RenderImage renders an image in the terminal, detecting the terminal capabilities and size
+func RenderImage(w io.Writer, img image.Image, protocol Protocol) error {
+	opts := terminalOptions()
+	opts.Protocol = protocol
+	if protocol == Auto {
+		opts.Protocol = DetectTerminal()
+	}
+
+	return Render(w, img, opts)
+}
+
+// DetectTerminal returns the best protocol supported by the controlling terminal
+func DetectTerminal() Protocol {
+	return Detect(os.Getenv, queryAttributes)
+}
+
+// fitCells returns the number of columns and rows the image takes once fit in the bounds set in
+// options, keeping its aspect ratio
+func fitCells(size image.Point, opts Options) (int, int) {
+	cellWidth, cellHeight := max(opts.CellWidth, 1), max(opts.CellHeight, 1)
+	maxWidth := float64(max(opts.Columns, 1) * cellWidth)
+	maxHeight := float64(max(opts.Rows, 1) * cellHeight)
+
+	ratio := min(maxWidth/float64(size.X), maxHeight/float64(size.Y))
+	cols := int(float64(size.X) * ratio / float64(cellWidth))
+	rows := int(float64(size.Y) * ratio / float64(cellHeight))
+
+	return max(cols, 1), max(rows, 1)
+}
+
+// scale resizes an image to the given dimensions
+func scale(img image.Image, width int, height int) *image.RGBA {
+	dst := image.NewRGBA(image.Rect(0, 0, max(width, 1), max(height, 1)))
+	draw.CatmullRom.Scale(dst, dst.Bounds(), img, img.Bounds(), draw.Src, nil)
+
+	return dst
+}
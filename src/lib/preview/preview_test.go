@@ -0,0 +1,134 @@
+package preview
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"math/rand"
+	"strings"
+	"testing"
+)
+
+func env(vars map[string]string) func(string) string {
+	return func(name string) string { return vars[name] }
+}
+
+func TestDetect(t *testing.T) {
+	noAnswer := func() string { return "" }
+	sixelAnswer := func() string { return "\x1b[?62;4;22c" }
+
+	for _, tc := range []struct {
+		vars     map[string]string
+		query    func() string
+		expected Protocol
+	}{
+		{map[string]string{"TERM": "xterm-kitty"}, noAnswer, Kitty},
+		{map[string]string{"TERM": "xterm-256color", "KITTY_WINDOW_ID": "1"}, noAnswer, Kitty},
+		{map[string]string{"TERM": "xterm-256color", "LC_TERMINAL": "iTerm2"}, noAnswer, Iterm},
+		{map[string]string{"TERM": "foot"}, noAnswer, Sixel},
+		{map[string]string{"TERM": "xterm-256color"}, sixelAnswer, Sixel},
+		{map[string]string{"TERM": "xterm-256color"}, noAnswer, Blocks},
+		{map[string]string{"TERM": "xterm-kitty", "TMUX": "/tmp/tmux"}, sixelAnswer, Blocks},
+	} {
+		if protocol := Detect(env(tc.vars), tc.query); protocol != tc.expected {
+			t.Fatalf("Expected %s for %v, got %s", tc.expected, tc.vars, protocol)
+		}
+	}
+
+	if supportsSixel("\x1b[?62;22c") {
+		t.Fatalf("Expected no Sixel support without attribute 4")
+	}
+}
+
+func TestFitCells(t *testing.T) {
+	opts := Options{Columns: 80, Rows: 24, CellWidth: 10, CellHeight: 20}
+
+	// Limited by height: 480px high, 720px wide
+	cols, rows := fitCells(image.Pt(1800, 1200), opts)
+	if cols != 72 || rows != 24 {
+		t.Fatalf("Expected 72x24 cells, got %dx%d", cols, rows)
+	}
+}
+
+func testImage() *image.RGBA {
+	img := image.NewRGBA(image.Rect(0, 0, 2, 3))
+	for x := 0; x < 2; x++ {
+		img.Set(x, 0, color.RGBA{255, 0, 0, 255})
+		img.Set(x, 1, color.RGBA{0, 0, 255, 255})
+		img.Set(x, 2, color.RGBA{0, 255, 0, 255})
+	}
+
+	return img
+}
+
+func TestRenderBlocks(t *testing.T) {
+	var out bytes.Buffer
+	if err := renderBlocks(&out, testImage()); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	expected := strings.Repeat("\x1b[38;2;255;0;0;48;2;0;0;255m▀", 2) + "\x1b[0m\n" +
+		strings.Repeat("\x1b[38;2;0;255;0;49m▀", 2) + "\x1b[0m\n"
+
+	if out.String() != expected {
+		t.Fatalf("Expected %q, got %q", expected, out.String())
+	}
+}
+
+func TestRenderSixel(t *testing.T) {
+	var out bytes.Buffer
+	if err := renderSixel(&out, testImage()); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	sixel := out.String()
+	if !strings.HasPrefix(sixel, "\x1bP0;1;0q\"1;1;2;3") || !strings.HasSuffix(sixel, "-\x1b\\\n") {
+		t.Fatalf("Expected a 2x3 Sixel image, got %q", sixel)
+	}
+
+	// Red, blue and green rows are the first, second and third sixel bits
+	for _, expected := range []string{"@@$", "AA$", "CC-"} {
+		if !strings.Contains(sixel, expected) {
+			t.Fatalf("Expected Sixel data to contain %q, got %q", expected, sixel)
+		}
+	}
+}
+
+func TestRenderKitty(t *testing.T) {
+	img := image.NewRGBA(image.Rect(0, 0, 200, 200))
+	// Random pixels are not compressed much, so that data is split in several chunks
+	rand.New(rand.NewSource(1)).Read(img.Pix)
+
+	var out bytes.Buffer
+	if err := renderKitty(&out, img, 10, 5); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	commands := strings.Split(strings.TrimSuffix(out.String(), "\n"), "\x1b\\")
+	commands = commands[:len(commands)-1]
+
+	if len(commands) < 2 {
+		t.Fatalf("Expected image data to be split in chunks, got %d commands", len(commands))
+	}
+
+	if !strings.HasPrefix(commands[0], "\x1b_Ga=T,f=100,q=2,c=10,r=5,m=1;") {
+		t.Fatalf("Expected first command to hold control data, got %q", commands[0][:40])
+	}
+
+	if !strings.HasPrefix(commands[len(commands)-1], "\x1b_Gm=0;") {
+		t.Fatalf(
+			"Expected last command to end transmission, got %q",
+			commands[len(commands)-1][:10],
+		)
+	}
+}
+
+func TestParseProtocol(t *testing.T) {
+	if protocol, err := ParseProtocol("Sixel"); err != nil || protocol != Sixel {
+		t.Fatalf("Expected sixel protocol, got %s (%v)", protocol, err)
+	}
+
+	if _, err := ParseProtocol("ascii"); err == nil {
+		t.Fatalf("Expected error for unknown protocol")
+	}
+}
@@ -0,0 +1,118 @@
+/*
+Copyright © 2024 Nicolas Goudry <goudry.nicolas@gmail.com>
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package preview
+
+import (
+	"bufio"
+	"fmt"
+	"image"
+	"image/color/palette"
+	"io"
+
+	"golang.org/x/image/draw"
+)
+
+// renderSixel displays an image with the Sixel graphics format
+// The image is reduced to a 256 colors palette with dithering, then encoded in bands of six rows
+func renderSixel(w io.Writer, img image.Image) error {
+	bounds := img.Bounds()
+	paletted := image.NewPaletted(image.Rect(0, 0, bounds.Dx(), bounds.Dy()), palette.Plan9)
+	draw.FloydSteinberg.Draw(paletted, paletted.Bounds(), img, bounds.Min)
+
+	out := bufio.NewWriter(w)
+	width, height := paletted.Rect.Dx(), paletted.Rect.Dy()
+
+	// Pixel aspect ratio 1:1 and raster size
+	fmt.Fprintf(out, "\x1bP0;1;0q\"1;1;%d;%d", width, height)
+
+	for i, c := range paletted.Palette {
+		r, g, b, _ := c.RGBA()
+		fmt.Fprintf(out, "#%d;2;%d;%d;%d", i, r*100/0xffff, g*100/0xffff, b*100/0xffff)
+	}
+
+	for y := 0; y < height; y += 6 {
+		// Colors are drawn one after the other over the same band, in order of appearance
+		var colors []uint8
+		seen := make(map[uint8]bool)
+		for dy := 0; dy < 6 && y+dy < height; dy++ {
+			for x := 0; x < width; x++ {
+				if index := paletted.ColorIndexAt(x, y+dy); !seen[index] {
+					seen[index] = true
+					colors = append(colors, index)
+				}
+			}
+		}
+
+		for i, index := range colors {
+			if i > 0 {
+				out.WriteByte('$')
+			}
+
+			fmt.Fprintf(out, "#%d", index)
+			writeSixelRow(out, paletted, y, index)
+		}
+
+		out.WriteByte('-')
+	}
+
+	out.WriteString("\x1b\\\n")
+
+	return out.Flush()
+}
+
+// writeSixelRow writes the sixels of a color over a band of six rows, with run-length encoding
+func writeSixelRow(out *bufio.Writer, img *image.Paletted, y int, index uint8) {
+	width, height := img.Rect.Dx(), img.Rect.Dy()
+
+	var last byte
+	count := 0
+
+	flush := func() {
+		if count > 3 {
+			fmt.Fprintf(out, "!%d%c", count, last)
+		} else {
+			for i := 0; i < count; i++ {
+				out.WriteByte(last)
+			}
+		}
+	}
+
+	for x := 0; x < width; x++ {
+		var bits byte
+		for dy := 0; dy < 6 && y+dy < height; dy++ {
+			if img.ColorIndexAt(x, y+dy) == index {
+				bits |= 1 << dy
+			}
+		}
+
+		sixel := 63 + bits
+		if count > 0 && sixel != last {
+			flush()
+			count = 0
+		}
+
+		last = sixel
+		count++
+	}
+
+	flush()
+}
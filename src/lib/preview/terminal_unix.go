@@ -0,0 +1,103 @@
+//go:build unix
+
+/*
+Copyright © 2024 Nicolas Goudry <goudry.nicolas@gmail.com>
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package preview
+
+import (
+	"bytes"
+	"os"
+
+	"golang.org/x/sys/unix"
+	"golang.org/x/term"
+)
+
+// queryTimeout is the time to wait for the terminal to answer a query, in milliseconds
+const queryTimeout = 200
+
+// terminalOptions returns the rendering options matching the size of the controlling terminal
+// Default values are used if the size cannot be retrieved
+func terminalOptions() Options {
+	opts := Options{Columns: 80, Rows: 24, CellWidth: 8, CellHeight: 16}
+
+	tty, err := os.Open("/dev/tty")
+	if err != nil {
+		return opts
+	}
+	defer tty.Close()
+
+	size, err := unix.IoctlGetWinsize(int(tty.Fd()), unix.TIOCGWINSZ)
+	if err != nil || size.Col == 0 || size.Row == 0 {
+		return opts
+	}
+
+	opts.Columns = int(size.Col)
+	// Keep room for the prompt below the image
+	opts.Rows = max(int(size.Row)-2, 1)
+
+	if size.Xpixel > 0 && size.Ypixel > 0 {
+		opts.CellWidth = int(size.Xpixel) / int(size.Col)
+		opts.CellHeight = int(size.Ypixel) / int(size.Row)
+	}
+
+	return opts
+}
+
+// queryAttributes asks the controlling terminal for its primary device attributes and returns its
+// answer, or an empty string if it does not answer in time
+func queryAttributes() string {
+	tty, err := os.OpenFile("/dev/tty", os.O_RDWR, 0)
+	if err != nil {
+		return ""
+	}
+	defer tty.Close()
+
+	fd := int(tty.Fd())
+	state, err := term.MakeRaw(fd)
+	if err != nil {
+		return ""
+	}
+	defer term.Restore(fd, state)
+
+	if _, err := tty.WriteString("\x1b[c"); err != nil {
+		return ""
+	}
+
+	var response []byte
+	buf := make([]byte, 64)
+
+	for !bytes.HasSuffix(response, []byte("c")) {
+		fds := []unix.PollFd{{Fd: int32(fd), Events: unix.POLLIN}}
+		if n, err := unix.Poll(fds, queryTimeout); err != nil || n == 0 {
+			break
+		}
+
+		n, err := tty.Read(buf)
+		if err != nil || n == 0 {
+			break
+		}
+
+		response = append(response, buf[:n]...)
+	}
+
+	return string(response)
+}
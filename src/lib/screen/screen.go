@@ -0,0 +1,323 @@
+/*
+Copyright © 2024 Nicolas Goudry <goudry.nicolas@gmail.com>
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package screen
+
+import (
+	"bufio"
+	"fmt"
+	"image"
+	"math"
+	"os/exec"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Monitor describes an enabled monitor
+// Its size is in physical pixels, once rotated, while its position is in the coordinates of the
+// desktop layout, which are scaled on Wayland
+type Monitor struct {
+	Name    string
+	X       int
+	Y       int
+	Width   int
+	Height  int
+	Scale   float64
+	Primary bool
+}
+
+// Size formats the size of the monitor as 'WxH'
+func (m Monitor) Size() string {
+	return fmt.Sprintf("%dx%d", m.Width, m.Height)
+}
+
+// geometry matches a monitor size and position formatted as 'WxH+X+Y'
+var geometry = regexp.MustCompile(`^(\d+)x(\d+)([+-]\d+)([+-]\d+)$`)
+
+// ParseLayout parses a comma separated list of monitor geometries formatted as 'WxH+X+Y', e.g.
+// '1920x1080+0+0,2560x1440+1920+0'
+func ParseLayout(value string) ([]Monitor, error) {
+	var monitors []Monitor
+	for i, part := range strings.Split(value, ",") {
+		match := geometry.FindStringSubmatch(strings.TrimSpace(part))
+		if match == nil {
+			return nil, fmt.Errorf(
+				"invalid monitor geometry: %s. Expected 'WxH+X+Y', e.g. '1920x1080+0+0'",
+				part,
+			)
+		}
+
+		values := make([]int, 4)
+		for j, value := range match[1:] {
+			values[j], _ = strconv.Atoi(value)
+		}
+
+		if values[0] == 0 || values[1] == 0 {
+			return nil, fmt.Errorf("invalid monitor geometry: %s. Size cannot be zero", part)
+		}
+
+		monitors = append(monitors, Monitor{
+			Name:   fmt.Sprintf("monitor-%d", i+1),
+			Width:  values[0],
+			Height: values[1],
+			X:      values[2],
+			Y:      values[3],
+			Scale:  1,
+		})
+	}
+
+	return monitors, nil
+}
+
+// xrandrOutput matches the connected outputs listed by xrandr that have a mode set, e.g.
+// 'DP-1 connected primary 2560x1440+1920+0 (normal left inverted right x axis y axis) 597mm x 336mm'
+var xrandrOutput = regexp.MustCompile(
+	`^(\S+) connected (primary )?(\d+)x(\d+)([+-]\d+)([+-]\d+)`,
+)
+
+// ParseXrandr returns the enabled monitors listed by 'xrandr --query'
+func ParseXrandr(output string) ([]Monitor, error) {
+	var monitors []Monitor
+
+	scanner := bufio.NewScanner(strings.NewReader(output))
+	for scanner.Scan() {
+		match := xrandrOutput.FindStringSubmatch(scanner.Text())
+		if match == nil {
+			continue
+		}
+
+		values := make([]int, 4)
+		for i, value := range match[3:] {
+			values[i], _ = strconv.Atoi(value)
+		}
+
+		monitors = append(monitors, Monitor{
+			Name:    match[1],
+			Width:   values[0],
+			Height:  values[1],
+			X:       values[2],
+			Y:       values[3],
+			Scale:   1,
+			Primary: match[2] != "",
+		})
+	}
+
+	return monitors, scanner.Err()
+}
+
+// ParseWlrRandr returns the enabled monitors listed by 'wlr-randr'
+// Each output is described by a block of indented properties following a line with its name
+func ParseWlrRandr(output string) ([]Monitor, error) {
+	var monitors []Monitor
+	var current *Monitor
+	var enabled, inModes bool
+	var transform string
+
+	flush := func() {
+		if current != nil && enabled && current.Width > 0 {
+			// Monitors rotated by a quarter turn are taller than their mode
+			if strings.HasSuffix(transform, "90") || strings.HasSuffix(transform, "270") {
+				current.Width, current.Height = current.Height, current.Width
+			}
+
+			monitors = append(monitors, *current)
+		}
+
+		current, enabled, inModes, transform = nil, false, false, ""
+	}
+
+	scanner := bufio.NewScanner(strings.NewReader(output))
+	for scanner.Scan() {
+		line := scanner.Text()
+		if line == "" {
+			continue
+		}
+
+		if !strings.HasPrefix(line, " ") {
+			flush()
+
+			name, _, _ := strings.Cut(line, " ")
+			current = &Monitor{Name: name, Scale: 1}
+			continue
+		}
+
+		if current == nil {
+			return nil, fmt.Errorf("unexpected wlr-randr output: %s", line)
+		}
+
+		key, value, found := strings.Cut(strings.TrimSpace(line), ":")
+		value = strings.TrimSpace(value)
+
+		// Modes are listed on their own lines below the 'Modes:' property
+		if inModes && !found {
+			if strings.Contains(value+key, "current") {
+				size, _, _ := strings.Cut(key, " ")
+				width, height, err := parseSize(size)
+				if err != nil {
+					return nil, err
+				}
+
+				current.Width, current.Height = width, height
+			}
+
+			continue
+		}
+
+		inModes = false
+
+		switch key {
+		case "Enabled":
+			enabled = value == "yes"
+		case "Modes":
+			inModes = true
+		case "Position":
+			x, y, _ := strings.Cut(value, ",")
+			current.X, _ = strconv.Atoi(x)
+			current.Y, _ = strconv.Atoi(y)
+		case "Transform":
+			transform = value
+		case "Scale":
+			if scale, err := strconv.ParseFloat(value, 64); err == nil && scale > 0 {
+				current.Scale = scale
+			}
+		}
+	}
+	flush()
+
+	return monitors, scanner.Err()
+}
+
+// parseSize parses a size formatted as 'WxH'
+func parseSize(value string) (int, int, error) {
+	w, h, _ := strings.Cut(value, "x")
+	width, widthErr := strconv.Atoi(w)
+	height, heightErr := strconv.Atoi(h)
+
+	if widthErr != nil || heightErr != nil {
+		return 0, 0, fmt.Errorf("invalid monitor size: %s", value)
+	}
+
+	return width, height, nil
+}
+
+// tool describes a program listing monitors and how to parse its output
+type tool struct {
+	name  string
+	args  []string
+	parse func(output string) ([]Monitor, error)
+}
+
+var (
+	xrandr    = tool{name: "xrandr", args: []string{"--query"}, parse: ParseXrandr}
+	wlrRandr  = tool{name: "wlr-randr", parse: ParseWlrRandr}
+	errNoTool = fmt.Errorf("no monitor found, either xrandr or wlr-randr is needed to detect them")
+)
+
+// Detect returns the enabled monitors, as listed by wlr-randr on Wayland or xrandr otherwise
+// xrandr is tried as well on Wayland, where it lists the XWayland outputs, to support compositors
+// that do not implement the output management protocol used by wlr-randr
+func Detect(
+	getenv func(string) string,
+	run func(name string, args ...string) (string, error),
+) ([]Monitor, error) {
+	tools := []tool{xrandr}
+	if getenv("WAYLAND_DISPLAY") != "" {
+		tools = []tool{wlrRandr, xrandr}
+	}
+
+	for _, tool := range tools {
+		output, err := run(tool.name, tool.args...)
+		if err != nil {
+			continue
+		}
+
+		monitors, err := tool.parse(output)
+		if err != nil {
+			return nil, err
+		}
+
+		if len(monitors) > 0 {
+			return monitors, nil
+		}
+	}
+
+	return nil, errNoTool
+}
+
+// Run executes the named program found in PATH and returns its standard output
+func Run(name string, args ...string) (string, error) {
+	output, err := exec.Command(name, args...).Output()
+	return string(output), err
+}
+
+// Main returns the primary monitor, or the monitor with the most pixels if none is primary
+func Main(monitors []Monitor) Monitor {
+	var main Monitor
+	for _, monitor := range monitors {
+		if monitor.Primary {
+			return monitor
+		}
+
+		if monitor.Width*monitor.Height > main.Width*main.Height {
+			main = monitor
+		}
+	}
+
+	return main
+}
+
+// Rects returns the areas of the monitors in the desktop layout, in physical pixels, with the
+// top-left corner of the layout at the origin
+// Positions are scaled with the largest scale of all monitors, which is exact when they share
+// the same scale
+func Rects(monitors []Monitor) []image.Rectangle {
+	scale := 1.0
+	for _, monitor := range monitors {
+		scale = math.Max(scale, monitor.Scale)
+	}
+
+	rects := make([]image.Rectangle, len(monitors))
+	for i, monitor := range monitors {
+		min := image.Pt(
+			int(math.Round(float64(monitor.X)*scale)),
+			int(math.Round(float64(monitor.Y)*scale)),
+		)
+		rects[i] = image.Rectangle{Min: min, Max: min.Add(image.Pt(monitor.Width, monitor.Height))}
+	}
+
+	var origin image.Point
+	for i, rect := range rects {
+		if i == 0 || rect.Min.X < origin.X {
+			origin.X = rect.Min.X
+		}
+
+		if i == 0 || rect.Min.Y < origin.Y {
+			origin.Y = rect.Min.Y
+		}
+	}
+
+	for i := range rects {
+		rects[i] = rects[i].Sub(origin)
+	}
+
+	return rects
+}
@@ -0,0 +1,167 @@
+package screen
+
+import (
+	"fmt"
+	"image"
+	"reflect"
+	"testing"
+)
+
+const xrandrQuery = `Screen 0: minimum 320 x 200, current 4480 x 1440, maximum 16384 x 16384
+eDP-1 connected (normal left inverted right x axis y axis)
+   1920x1080     60.01 +
+DP-1 connected primary 2560x1440+1920+0 (normal left inverted right x axis y axis) 597mm x 336mm
+   2560x1440     59.95*+
+HDMI-1 connected 1080x1920+0+0 left (normal left inverted right x axis y axis) 527mm x 296mm
+   1920x1080     60.00*+
+DP-2 disconnected (normal left inverted right x axis y axis)
+`
+
+const wlrRandrOutput = `eDP-1 "BOE 0x095F (eDP-1)"
+  Make: BOE
+  Model: 0x095F
+  Serial: (null)
+  Physical size: 290x190 mm
+  Enabled: yes
+  Modes:
+    2256x1504 px, 59.999001 Hz (preferred, current)
+  Position: 0,0
+  Transform: normal
+  Scale: 1.500000
+DP-3 "Dell Inc. DELL U2720Q 1234 (DP-3)"
+  Make: Dell Inc.
+  Enabled: yes
+  Modes:
+    3840x2160 px, 59.997002 Hz (preferred, current)
+    2560x1440 px, 59.951000 Hz
+  Position: 1504,0
+  Transform: 90
+  Scale: 1.000000
+HDMI-A-1 "Unknown"
+  Enabled: no
+  Modes:
+    1920x1080 px, 60.000000 Hz (preferred)
+`
+
+func TestParseXrandr(t *testing.T) {
+	monitors, err := ParseXrandr(xrandrQuery)
+	if err != nil {
+		t.Fatalf("Expected no error, got %s", err)
+	}
+
+	expected := []Monitor{
+		{Name: "DP-1", X: 1920, Width: 2560, Height: 1440, Scale: 1, Primary: true},
+		{Name: "HDMI-1", Width: 1080, Height: 1920, Scale: 1},
+	}
+	if !reflect.DeepEqual(monitors, expected) {
+		t.Fatalf("Expected %+v, got %+v", expected, monitors)
+	}
+}
+
+func TestParseWlrRandr(t *testing.T) {
+	monitors, err := ParseWlrRandr(wlrRandrOutput)
+	if err != nil {
+		t.Fatalf("Expected no error, got %s", err)
+	}
+
+	expected := []Monitor{
+		{Name: "eDP-1", Width: 2256, Height: 1504, Scale: 1.5},
+		{Name: "DP-3", X: 1504, Width: 2160, Height: 3840, Scale: 1},
+	}
+	if !reflect.DeepEqual(monitors, expected) {
+		t.Fatalf("Expected %+v, got %+v", expected, monitors)
+	}
+
+	if _, err := ParseWlrRandr("  Enabled: yes"); err == nil {
+		t.Fatalf("Expected error for property without output")
+	}
+}
+
+func TestDetect(t *testing.T) {
+	outputs := map[string]string{"xrandr": xrandrQuery, "wlr-randr": wlrRandrOutput}
+	run := func(name string, _ ...string) (string, error) {
+		output, ok := outputs[name]
+		if !ok {
+			return "", fmt.Errorf("%s not found", name)
+		}
+
+		return output, nil
+	}
+
+	wayland := func(key string) string {
+		if key == "WAYLAND_DISPLAY" {
+			return "wayland-1"
+		}
+
+		return ""
+	}
+	x11 := func(string) string { return "" }
+
+	if monitors, err := Detect(wayland, run); err != nil || monitors[0].Name != "eDP-1" {
+		t.Fatalf("Expected monitors listed by wlr-randr on Wayland, got %+v (%v)", monitors, err)
+	}
+
+	if monitors, err := Detect(x11, run); err != nil || monitors[0].Name != "DP-1" {
+		t.Fatalf("Expected monitors listed by xrandr, got %+v (%v)", monitors, err)
+	}
+
+	delete(outputs, "wlr-randr")
+	if monitors, err := Detect(wayland, run); err != nil || monitors[0].Name != "DP-1" {
+		t.Fatalf("Expected fallback to xrandr on Wayland, got %+v (%v)", monitors, err)
+	}
+
+	delete(outputs, "xrandr")
+	if _, err := Detect(wayland, run); err == nil {
+		t.Fatalf("Expected error without any tool")
+	}
+}
+
+func TestMainMonitor(t *testing.T) {
+	monitors := []Monitor{
+		{Name: "eDP-1", Width: 1920, Height: 1080},
+		{Name: "DP-1", Width: 3840, Height: 2160},
+		{Name: "DP-2", Width: 2560, Height: 1440},
+	}
+
+	if main := Main(monitors); main.Name != "DP-1" {
+		t.Fatalf("Expected largest monitor, got %s", main.Name)
+	}
+
+	monitors[2].Primary = true
+	if main := Main(monitors); main.Name != "DP-2" {
+		t.Fatalf("Expected primary monitor, got %s", main.Name)
+	}
+}
+
+func TestParseLayout(t *testing.T) {
+	monitors, err := ParseLayout("1920x1080+0+180, 2560x1440+1920+0")
+	if err != nil {
+		t.Fatalf("Expected no error, got %s", err)
+	}
+
+	expected := []Monitor{
+		{Name: "monitor-1", Y: 180, Width: 1920, Height: 1080, Scale: 1},
+		{Name: "monitor-2", X: 1920, Width: 2560, Height: 1440, Scale: 1},
+	}
+	if !reflect.DeepEqual(monitors, expected) {
+		t.Fatalf("Expected %+v, got %+v", expected, monitors)
+	}
+
+	for _, value := range []string{"", "1920x1080", "1920x1080+0", "0x1080+0+0", "auto"} {
+		if _, err := ParseLayout(value); err == nil {
+			t.Fatalf("Expected error for invalid layout %q", value)
+		}
+	}
+}
+
+func TestRects(t *testing.T) {
+	rects := Rects([]Monitor{
+		{Width: 2256, Height: 1504, X: -1504, Scale: 1.5},
+		{Width: 3840, Height: 2160, Scale: 1.5},
+	})
+
+	expected := []image.Rectangle{image.Rect(0, 0, 2256, 1504), image.Rect(2256, 0, 6096, 2160)}
+	if !reflect.DeepEqual(rects, expected) {
+		t.Fatalf("Expected %v, got %v", expected, rects)
+	}
+}
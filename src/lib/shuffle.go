@@ -0,0 +1,127 @@
+/*
+Copyright © 2024 Nicolas Goudry <goudry.nicolas@gmail.com>
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package lib
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"slices"
+)
+
+// ShuffleBag holds the identifiers not yet picked in the current cycle through a catalog
+// It is stored in $XDG_STATE_HOME/earth-view/shuffle.json so that cycles span several runs
+type ShuffleBag struct {
+	// Catalog identifies the set of identifiers the bag was filled from
+	Catalog string `json:"catalog"`
+	// Remaining holds the identifiers not yet picked
+	Remaining []int `json:"remaining"`
+	// Missing holds the identifiers which do not exist upstream, sorted, never put back in the bag
+	Missing []int `json:"missing,omitempty"`
+}
+
+// shuffleBagPath returns the path of the shuffle bag state file
+func shuffleBagPath() string {
+	return filepath.Join(StateHome(), "earth-view", "shuffle.json")
+}
+
+// CatalogKey returns a key identifying a set of identifiers, whatever their order
+func CatalogKey(ids []int) string {
+	sorted := slices.Clone(ids)
+	slices.Sort(sorted)
+
+	content, _ := json.Marshal(sorted)
+	sum := sha256.Sum256(content)
+
+	return hex.EncodeToString(sum[:8])
+}
+
+// LoadShuffleBag reads the shuffle bag state, returning an empty bag if there is none
+func LoadShuffleBag() (*ShuffleBag, error) {
+	bag := &ShuffleBag{}
+
+	content, err := os.ReadFile(shuffleBagPath())
+	if errors.Is(err, os.ErrNotExist) {
+		return bag, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	// A corrupted state only restarts the cycle
+	if err := json.Unmarshal(content, bag); err != nil {
+		return &ShuffleBag{}, nil
+	}
+
+	return bag, nil
+}
+
+// Save writes the shuffle bag state
+func (b *ShuffleBag) Save() error {
+	content, err := json.Marshal(b)
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(shuffleBagPath()), 0700); err != nil {
+		return err
+	}
+
+	// Several processes may pick images at the same time
+	return WriteFileAtomic(shuffleBagPath(), content, 0600)
+}
+
+// Refill starts a new cycle through the given identifiers if the bag is empty or was filled from
+// another catalog
+func (b *ShuffleBag) Refill(ids []int) {
+	key := CatalogKey(ids)
+	if b.Catalog == key && len(b.Remaining) > 0 {
+		return
+	}
+
+	b.Catalog = key
+	b.Remaining = slices.DeleteFunc(slices.Clone(ids), b.IsMissing)
+}
+
+// Take removes the given identifier from the bag
+func (b *ShuffleBag) Take(id int) {
+	if i := slices.Index(b.Remaining, id); i >= 0 {
+		b.Remaining = slices.Delete(b.Remaining, i, i+1)
+	}
+}
+
+// Drop removes the given identifier from the bag for good, as it does not exist upstream
+func (b *ShuffleBag) Drop(id int) {
+	b.Take(id)
+
+	if i, found := slices.BinarySearch(b.Missing, id); !found {
+		b.Missing = slices.Insert(b.Missing, i, id)
+	}
+}
+
+// IsMissing returns whether the given identifier was dropped from the bag
+func (b *ShuffleBag) IsMissing(id int) bool {
+	_, found := slices.BinarySearch(b.Missing, id)
+	return found
+}
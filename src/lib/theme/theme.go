@@ -0,0 +1,54 @@
+/*
+Copyright © 2024 Nicolas Goudry <goudry.nicolas@gmail.com>
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package theme
+
+import (
+	"github.com/charmbracelet/lipgloss"
+)
+
+// Colors shared by the terminal user interfaces, from the Catppuccin Latte and Mocha palettes
+var (
+	Red = lipgloss.AdaptiveColor{
+		Light: "#d20f39",
+		Dark:  "#f38ba8",
+	}
+	Green = lipgloss.AdaptiveColor{
+		Light: "#40a02b",
+		Dark:  "#a6e3a1",
+	}
+	Orange = lipgloss.AdaptiveColor{
+		Light: "#fe640b",
+		Dark:  "#fab387",
+	}
+	Yellow = lipgloss.AdaptiveColor{
+		Light: "#df8e1d",
+		Dark:  "#f9e2af",
+	}
+	Blue = lipgloss.AdaptiveColor{
+		Light: "#1e66f5",
+		Dark:  "#89b4fa",
+	}
+	Muted = lipgloss.AdaptiveColor{
+		Light: "#6c6f85",
+		Dark:  "#a6adc8",
+	}
+)
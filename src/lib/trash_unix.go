@@ -0,0 +1,249 @@
+//go:build unix
+
+/*
+Copyright © 2024 Nicolas Goudry <goudry.nicolas@gmail.com>
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package lib
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+)
+
+// trashCan represents a trash directory as defined by the FreeDesktop.org Trash specification
+type trashCan struct {
+	// dir is the trash directory, containing the files and info subdirectories
+	dir string
+	// topDir is the directory against which original paths are made relative, empty for the home
+	// trash which stores absolute paths
+	topDir string
+}
+
+// Trash moves a file to the trash, following the FreeDesktop.org Trash specification
+// Files living on the same device as the home trash are moved to it, other files are moved to the
+// trash directory at the top of their mount point
+// It returns the path of the trashed file
+func Trash(filePath string) (string, error) {
+	absPath, err := filepath.Abs(filePath)
+	if err != nil {
+		return "", err
+	}
+
+	stat, err := os.Lstat(absPath)
+	if err != nil {
+		return "", err
+	}
+
+	can, err := findTrashCan(absPath, deviceOf(stat))
+	if err != nil {
+		return "", fmt.Errorf("cannot trash %s: %s", absPath, err)
+	}
+
+	return can.put(absPath)
+}
+
+// findTrashCan returns the trash can to use for a file living on the given device
+func findTrashCan(absPath string, device uint64) (*trashCan, error) {
+	homeTrash := filepath.Join(DataHome(), "Trash")
+
+	homeDevice, err := nearestDevice(homeTrash)
+	if err != nil {
+		return nil, err
+	}
+
+	if homeDevice == device {
+		return &trashCan{dir: homeTrash}, nil
+	}
+
+	topDir, err := mountTopDir(absPath, device)
+	if err != nil {
+		return nil, err
+	}
+
+	trashDir, err := topDirTrash(topDir, os.Getuid())
+	if err != nil {
+		return nil, err
+	}
+
+	return &trashCan{dir: trashDir, topDir: topDir}, nil
+}
+
+// topDirTrash returns the trash directory to use in the given top directory
+// The shared $topdir/.Trash/$uid directory is used if $topdir/.Trash is a sticky directory which is
+// not a symbolic link, otherwise $topdir/.Trash-$uid is used
+func topDirTrash(topDir string, uid int) (string, error) {
+	shared := filepath.Join(topDir, ".Trash")
+	if stat, err := os.Lstat(shared); err == nil &&
+		stat.IsDir() &&
+		stat.Mode()&os.ModeSticky != 0 {
+		userTrash := filepath.Join(shared, strconv.Itoa(uid))
+		if err := ensureTrashDir(userTrash); err == nil {
+			return userTrash, nil
+		}
+	}
+
+	userTrash := filepath.Join(topDir, ".Trash-"+strconv.Itoa(uid))
+	if err := ensureTrashDir(userTrash); err != nil {
+		return "", err
+	}
+
+	return userTrash, nil
+}
+
+// ensureTrashDir creates the given trash directory if needed and checks that it is a real directory
+func ensureTrashDir(dir string) error {
+	if err := os.Mkdir(dir, 0700); err != nil && !errors.Is(err, os.ErrExist) {
+		return err
+	}
+
+	stat, err := os.Lstat(dir)
+	if err != nil {
+		return err
+	}
+
+	if !stat.IsDir() {
+		return fmt.Errorf("%s is not a directory", dir)
+	}
+
+	return nil
+}
+
+// put moves the file to the trash can, writing its trash info file beforehand
+func (c *trashCan) put(absPath string) (string, error) {
+	filesDir := filepath.Join(c.dir, "files")
+	infoDir := filepath.Join(c.dir, "info")
+
+	for _, dir := range []string{filesDir, infoDir} {
+		if err := os.MkdirAll(dir, 0700); err != nil {
+			return "", err
+		}
+	}
+
+	originalPath := absPath
+	if c.topDir != "" {
+		relPath, err := filepath.Rel(c.topDir, absPath)
+		if err != nil {
+			return "", err
+		}
+
+		originalPath = relPath
+	}
+
+	info := fmt.Sprintf(
+		"[Trash Info]\nPath=%s\nDeletionDate=%s\n",
+		(&url.URL{Path: originalPath}).EscapedPath(),
+		time.Now().Format("2006-01-02T15:04:05"),
+	)
+
+	ext := filepath.Ext(absPath)
+	stem := strings.TrimSuffix(filepath.Base(absPath), ext)
+
+	for i := 1; ; i++ {
+		name := stem + ext
+		if i > 1 {
+			name = stem + "." + strconv.Itoa(i) + ext
+		}
+
+		// Creating the info file atomically reserves the name in the trash can
+		infoPath := filepath.Join(infoDir, name+".trashinfo")
+		infoFile, err := os.OpenFile(infoPath, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0600)
+		if errors.Is(err, os.ErrExist) {
+			continue
+		} else if err != nil {
+			return "", err
+		}
+
+		_, err = infoFile.WriteString(info)
+		if closeErr := infoFile.Close(); err == nil {
+			err = closeErr
+		}
+
+		trashedPath := filepath.Join(filesDir, name)
+		if err == nil {
+			// A file may have been left in files without its info file, do not overwrite it
+			if FileExists(trashedPath) {
+				os.Remove(infoPath)
+				continue
+			}
+
+			err = os.Rename(absPath, trashedPath)
+		}
+
+		if err != nil {
+			os.Remove(infoPath)
+			return "", err
+		}
+
+		return trashedPath, nil
+	}
+}
+
+// deviceOf returns the device identifier of the given file information
+func deviceOf(stat os.FileInfo) uint64 {
+	return uint64(stat.Sys().(*syscall.Stat_t).Dev)
+}
+
+// nearestDevice returns the device of the given path, or the one of its nearest existing parent
+func nearestDevice(path string) (uint64, error) {
+	for {
+		stat, err := os.Stat(path)
+		if err == nil {
+			return deviceOf(stat), nil
+		}
+
+		parent := filepath.Dir(path)
+		if !errors.Is(err, os.ErrNotExist) || parent == path {
+			return 0, err
+		}
+
+		path = parent
+	}
+}
+
+// mountTopDir returns the top directory of the mount point containing the given path
+func mountTopDir(absPath string, device uint64) (string, error) {
+	dir := filepath.Dir(absPath)
+
+	for {
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return dir, nil
+		}
+
+		stat, err := os.Stat(parent)
+		if err != nil {
+			return "", err
+		}
+
+		if deviceOf(stat) != device {
+			return dir, nil
+		}
+
+		dir = parent
+	}
+}
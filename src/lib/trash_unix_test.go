@@ -0,0 +1,136 @@
+//go:build unix
+
+package lib
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func prepareTrashTest(t *testing.T) (string, string) {
+	dataHome := t.TempDir()
+	t.Setenv("XDG_DATA_HOME", dataHome)
+
+	filePath := filepath.Join(t.TempDir(), "my image.jpeg")
+	if err := os.WriteFile(filePath, []byte("content"), 0644); err != nil {
+		t.Fatalf("Failed to prepare file: %v", err)
+	}
+
+	return dataHome, filePath
+}
+
+func TestTrashHome(t *testing.T) {
+	dataHome, filePath := prepareTrashTest(t)
+
+	trashedPath, err := Trash(filePath)
+	if err != nil {
+		t.Fatalf("Expected trash success, got error: %v", err)
+	}
+
+	if want := filepath.Join(dataHome, "Trash", "files", "my image.jpeg"); trashedPath != want {
+		t.Fatalf("Expected file to be trashed at %s, got %s", want, trashedPath)
+	}
+
+	if FileExists(filePath) {
+		t.Fatal("Expected original file to be removed")
+	}
+
+	info, err := os.ReadFile(filepath.Join(dataHome, "Trash", "info", "my image.jpeg.trashinfo"))
+	if err != nil {
+		t.Fatalf("Failed to read trash info: %v", err)
+	}
+
+	escapedPath := strings.ReplaceAll(filePath, " ", "%20")
+	if !strings.HasPrefix(string(info), "[Trash Info]\nPath="+escapedPath+"\nDeletionDate=") {
+		t.Fatalf("Received unexpected trash info: %s", info)
+	}
+}
+
+func TestTrashNameCollision(t *testing.T) {
+	dataHome, filePath := prepareTrashTest(t)
+
+	if _, err := Trash(filePath); err != nil {
+		t.Fatalf("Expected trash success, got error: %v", err)
+	}
+
+	if err := os.WriteFile(filePath, []byte("content"), 0644); err != nil {
+		t.Fatalf("Failed to prepare file: %v", err)
+	}
+
+	trashedPath, err := Trash(filePath)
+	if err != nil {
+		t.Fatalf("Expected trash success, got error: %v", err)
+	}
+
+	if want := filepath.Join(dataHome, "Trash", "files", "my image.2.jpeg"); trashedPath != want {
+		t.Fatalf("Expected file to be trashed at %s, got %s", want, trashedPath)
+	}
+
+	if !FileExists(filepath.Join(dataHome, "Trash", "info", "my image.2.jpeg.trashinfo")) {
+		t.Fatal("Expected trash info to be written for second file")
+	}
+}
+
+func TestTopDirTrashShared(t *testing.T) {
+	topDir := t.TempDir()
+	shared := filepath.Join(topDir, ".Trash")
+	if err := os.Mkdir(shared, 0777|os.ModeSticky); err != nil {
+		t.Fatalf("Failed to prepare shared trash: %v", err)
+	}
+	// Mkdir is subject to umask and may drop the sticky bit
+	if err := os.Chmod(shared, 0777|os.ModeSticky); err != nil {
+		t.Fatalf("Failed to prepare shared trash: %v", err)
+	}
+
+	trashDir, err := topDirTrash(topDir, 1000)
+	if err != nil {
+		t.Fatalf("Expected trash directory, got error: %v", err)
+	}
+
+	if want := filepath.Join(shared, "1000"); trashDir != want {
+		t.Fatalf("Expected trash directory %s, got %s", want, trashDir)
+	}
+}
+
+func TestTopDirTrashNotSticky(t *testing.T) {
+	topDir := t.TempDir()
+	if err := os.Mkdir(filepath.Join(topDir, ".Trash"), 0777); err != nil {
+		t.Fatalf("Failed to prepare shared trash: %v", err)
+	}
+
+	trashDir, err := topDirTrash(topDir, 1000)
+	if err != nil {
+		t.Fatalf("Expected trash directory, got error: %v", err)
+	}
+
+	if want := filepath.Join(topDir, ".Trash-1000"); trashDir != want {
+		t.Fatalf("Expected trash directory %s, got %s", want, trashDir)
+	}
+}
+
+func TestTopDirTrashRelativePath(t *testing.T) {
+	topDir := t.TempDir()
+	filePath := filepath.Join(topDir, "images", "1003.jpeg")
+	if err := os.MkdirAll(filepath.Dir(filePath), 0755); err != nil {
+		t.Fatalf("Failed to prepare file: %v", err)
+	}
+	if err := os.WriteFile(filePath, []byte("content"), 0644); err != nil {
+		t.Fatalf("Failed to prepare file: %v", err)
+	}
+
+	can := &trashCan{dir: filepath.Join(topDir, ".Trash-1000"), topDir: topDir}
+	if _, err := can.put(filePath); err != nil {
+		t.Fatalf("Expected trash success, got error: %v", err)
+	}
+
+	info, err := os.ReadFile(filepath.Join(can.dir, "info", "1003.jpeg.trashinfo"))
+	if err != nil {
+		t.Fatalf("Failed to read trash info: %v", err)
+	}
+
+	if !strings.Contains(string(info), "\nPath=images/1003.jpeg\n") {
+		t.Fatalf("Expected path relative to top directory, got: %s", info)
+	}
+}
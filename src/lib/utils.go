@@ -25,6 +25,9 @@ import (
 	"os"
 	"path"
 	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
 )
 
 func ResolveAbsFilePath(outPath string, defaultFilename string) (string, error) {
@@ -61,3 +64,63 @@ func WriteFile(content []byte, outPath string) error {
 
 	return nil
 }
+
+// WriteFileAtomic writes the file through a temporary file renamed over it, so that concurrent
+// readers never see a partial file
+func WriteFileAtomic(filePath string, content []byte, perm os.FileMode) error {
+	tmpFile, err := os.CreateTemp(filepath.Dir(filePath), "."+filepath.Base(filePath)+"-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmpFile.Name())
+
+	if _, err := tmpFile.Write(content); err != nil {
+		tmpFile.Close()
+		return err
+	}
+
+	if err := tmpFile.Close(); err != nil {
+		return err
+	}
+
+	// Temporary files are only readable by their owner
+	if err := os.Chmod(tmpFile.Name(), perm); err != nil {
+		return err
+	}
+
+	return os.Rename(tmpFile.Name(), filePath)
+}
+
+// LinkCurrent points the '.current' symbolic link of the given directory to the given file
+// The link tracks the current background and is replaced atomically
+func LinkCurrent(dir string, filePath string) error {
+	tmpLink := path.Join(dir, ".current.tmp")
+	os.Remove(tmpLink)
+
+	if err := os.Symlink(filePath, tmpLink); err != nil {
+		return err
+	}
+
+	return os.Rename(tmpLink, path.Join(dir, ".current"))
+}
+
+// imageName matches the file names of images saved by the fetch commands, with the suffixes of
+// images derived from them
+var imageName = regexp.MustCompile(`^(\d+)(-\d+x\d+)?(-caption|-lockscreen)?$`)
+
+// IdFromPath returns the identifier of an image from its file name, as saved by the fetch commands
+// Images derived from another one, e.g. '1003-1920x1080-caption.jpeg', have its identifier
+func IdFromPath(filePath string) (int, bool) {
+	name := filepath.Base(filePath)
+	match := imageName.FindStringSubmatch(strings.TrimSuffix(name, filepath.Ext(name)))
+	if match == nil {
+		return 0, false
+	}
+
+	id, err := strconv.Atoi(match[1])
+	if err != nil {
+		return 0, false
+	}
+
+	return id, true
+}
@@ -0,0 +1,33 @@
+package lib
+
+import "testing"
+
+func TestIdFromPath(t *testing.T) {
+	for filePath, expected := range map[string]int{
+		"/tmp/1003.jpeg":                      1003,
+		"1003.json":                           1003,
+		"/tmp/1003-1920x1080.jpeg":            1003,
+		"/tmp/1003-1920x1080-caption.jpeg":    1003,
+		"/tmp/1003-1920x1080-lockscreen.jpeg": 1003,
+		"/tmp/1003-caption.jpeg":              1003,
+	} {
+		if id, ok := IdFromPath(filePath); !ok || id != expected {
+			t.Fatalf("Expected identifier %d for %s, got %d", expected, filePath, id)
+		}
+	}
+
+	for _, filePath := range []string{
+		"/tmp/wallpaper.png",
+		"/tmp/-1003.jpeg",
+		"/tmp/+1003.jpeg",
+		"/tmp/earth-1003.jpeg",
+		"/tmp/2019-05-x.jpg",
+		"/tmp/2019-05-vacation.jpg",
+		"/tmp/1003-copy.jpeg",
+		"/tmp/1003-caption-1920x1080.jpeg",
+	} {
+		if _, ok := IdFromPath(filePath); ok {
+			t.Fatalf("Expected no identifier for %s", filePath)
+		}
+	}
+}
@@ -0,0 +1,44 @@
+/*
+Copyright © 2024 Nicolas Goudry <goudry.nicolas@gmail.com>
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package wallpaper
+
+// feh sets the wallpaper of X desktops with feh
+type feh struct {
+	runner Runner
+}
+
+func newFeh(runner Runner) Backend {
+	return &feh{runner: runner}
+}
+
+func (f *feh) Name() string {
+	return "feh"
+}
+
+func (f *feh) Set(file string, opts Options) error {
+	args := []string{"--bg-" + opts.Display, "--no-fehbg"}
+	if !opts.Xinerama {
+		args = append(args, "--no-xinerama")
+	}
+
+	return f.runner.Run("feh", append(args, file)...)
+}
@@ -0,0 +1,81 @@
+/*
+Copyright © 2024 Nicolas Goudry <goudry.nicolas@gmail.com>
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package wallpaper
+
+import (
+	"net/url"
+)
+
+const gnomeSchema = "org.gnome.desktop.background"
+
+// gnomePictureOptions maps display modes to GNOME picture-options values
+var gnomePictureOptions = map[string]string{
+	"center": "centered",
+	"fill":   "zoom",
+	"max":    "scaled",
+	"scale":  "stretched",
+	"tile":   "wallpaper",
+}
+
+// gnome sets the wallpaper of GNOME shell desktops with gsettings
+type gnome struct {
+	runner Runner
+}
+
+func newGnome(runner Runner) Backend {
+	return &gnome{runner: runner}
+}
+
+func (g *gnome) Name() string {
+	return "gnome"
+}
+
+func (g *gnome) Set(file string, opts Options) error {
+	uri := fileUri(file)
+	darkUri := uri
+	if opts.DarkFile != "" {
+		darkUri = fileUri(opts.DarkFile)
+	}
+
+	// GNOME stretches a single image across all screens with the spanned option
+	pictureOptions := gnomePictureOptions[opts.Display]
+	if !opts.Xinerama {
+		pictureOptions = "spanned"
+	}
+
+	for _, setting := range [][2]string{
+		{"picture-uri", uri},
+		{"picture-uri-dark", darkUri},
+		{"picture-options", pictureOptions},
+	} {
+		if err := g.runner.Run("gsettings", "set", gnomeSchema, setting[0], setting[1]); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// fileUri returns the file URI of the given absolute path
+func fileUri(file string) string {
+	return (&url.URL{Scheme: "file", Path: file}).String()
+}
@@ -0,0 +1,111 @@
+/*
+Copyright © 2024 Nicolas Goudry <goudry.nicolas@gmail.com>
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package wallpaper
+
+import (
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"earth-view/lib"
+)
+
+// hyprpaperPrefixes maps display modes to hyprpaper wallpaper path prefixes
+// hyprpaper covers the screen by default and does not support other modes
+var hyprpaperPrefixes = map[string]string{
+	"max":  "contain:",
+	"tile": "tile:",
+}
+
+// hyprpaper sets the wallpaper of Hyprland with hyprpaper, through its IPC socket
+// hyprpaper has no support for spanning a single image across screens, the Xinerama option is
+// ignored
+type hyprpaper struct {
+	socketPath string
+}
+
+func newHyprpaper(_ Runner) Backend {
+	return &hyprpaper{socketPath: hyprpaperSocket(os.Getenv("HYPRLAND_INSTANCE_SIGNATURE"))}
+}
+
+// hyprpaperSocket returns the path of the hyprpaper IPC socket for the given Hyprland instance
+// Hyprland used to store its sockets in /tmp before moving them to XDG_RUNTIME_DIR
+func hyprpaperSocket(signature string) string {
+	socketPath := filepath.Join(lib.RuntimeDir(), "hypr", signature, ".hyprpaper.sock")
+	if !lib.FileExists(socketPath) {
+		legacyPath := filepath.Join("/tmp", "hypr", signature, ".hyprpaper.sock")
+		if lib.FileExists(legacyPath) {
+			return legacyPath
+		}
+	}
+
+	return socketPath
+}
+
+func (h *hyprpaper) Name() string {
+	return "hyprpaper"
+}
+
+func (h *hyprpaper) Set(file string, opts Options) error {
+	// An empty monitor name applies the wallpaper to all monitors
+	for _, request := range []string{
+		"preload " + file,
+		"wallpaper ," + hyprpaperPrefixes[opts.Display] + file,
+		"unload unused",
+	} {
+		if err := h.send(request); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// send writes a request to the hyprpaper socket and checks its response
+func (h *hyprpaper) send(request string) error {
+	conn, err := net.DialTimeout("unix", h.socketPath, time.Second)
+	if err != nil {
+		return fmt.Errorf("hyprpaper is not reachable: %s", err)
+	}
+	defer conn.Close()
+
+	conn.SetDeadline(time.Now().Add(5 * time.Second))
+
+	if _, err := conn.Write([]byte(request)); err != nil {
+		return fmt.Errorf("hyprpaper request failed: %s", err)
+	}
+
+	response, err := io.ReadAll(conn)
+	if err != nil {
+		return fmt.Errorf("hyprpaper request failed: %s", err)
+	}
+
+	if response := strings.TrimSpace(string(response)); response != "ok" {
+		return fmt.Errorf("hyprpaper request '%s' failed: %s", request, response)
+	}
+
+	return nil
+}
@@ -0,0 +1,54 @@
+/*
+Copyright © 2024 Nicolas Goudry <goudry.nicolas@gmail.com>
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package wallpaper
+
+// kdeFillModes maps display modes to plasma-apply-wallpaperimage fill modes
+var kdeFillModes = map[string]string{
+	"center": "pad",
+	"fill":   "preserveAspectCrop",
+	"max":    "preserveAspectFit",
+	"scale":  "stretch",
+	"tile":   "tile",
+}
+
+// kde sets the wallpaper of KDE Plasma desktops with plasma-apply-wallpaperimage
+// Plasma has no support for spanning a single image across screens, the Xinerama option is ignored
+type kde struct {
+	runner Runner
+}
+
+func newKde(runner Runner) Backend {
+	return &kde{runner: runner}
+}
+
+func (k *kde) Name() string {
+	return "kde"
+}
+
+func (k *kde) Set(file string, opts Options) error {
+	return k.runner.Run(
+		"plasma-apply-wallpaperimage",
+		"--fill-mode",
+		kdeFillModes[opts.Display],
+		file,
+	)
+}
@@ -0,0 +1,70 @@
+/*
+Copyright © 2024 Nicolas Goudry <goudry.nicolas@gmail.com>
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package wallpaper
+
+import (
+	"fmt"
+	"os/exec"
+	"strings"
+)
+
+// Runner runs the external programs used by backends
+type Runner interface {
+	// Run executes the named program with given arguments and waits for it to complete
+	Run(name string, args ...string) error
+	// Start executes the named program with given arguments in its own session, without waiting
+	// for it to complete, and returns its process identifier
+	Start(name string, args ...string) (int, error)
+}
+
+// ExecRunner is a Runner executing programs found in PATH
+type ExecRunner struct{}
+
+func (ExecRunner) Run(name string, args ...string) error {
+	output, err := exec.Command(name, args...).CombinedOutput()
+	if err != nil {
+		if output := strings.TrimSpace(string(output)); output != "" {
+			return fmt.Errorf("%s failed: %s: %s", name, err, output)
+		}
+
+		return fmt.Errorf("%s failed: %s", name, err)
+	}
+
+	return nil
+}
+
+func (ExecRunner) Start(name string, args ...string) (int, error) {
+	cmd := exec.Command(name, args...)
+	// Detach the process so that it outlives the current one
+	detach(cmd)
+
+	if err := cmd.Start(); err != nil {
+		return 0, fmt.Errorf("%s failed: %s", name, err)
+	}
+
+	pid := cmd.Process.Pid
+	if err := cmd.Process.Release(); err != nil {
+		return 0, err
+	}
+
+	return pid, nil
+}
@@ -0,0 +1,64 @@
+/*
+Copyright © 2024 Nicolas Goudry <goudry.nicolas@gmail.com>
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package wallpaper
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"earth-view/lib"
+)
+
+// ErrNotTracked is wrapped by errors occurring after the wallpaper was set, so that callers can
+// report them as warnings rather than failures
+var ErrNotTracked = errors.New("background is set but not tracked")
+
+// Show sets the given image file as wallpaper, records it in history if record is set, and points
+// the '.current' link of linkDir to it
+// Backgrounds shown again from history should not be recorded. When linkDir is empty, the link of
+// the directory of the file is updated only if it exists.
+func Show(backend Backend, file string, opts Options, record bool, linkDir string) error {
+	if err := backend.Set(file, opts); err != nil {
+		return err
+	}
+
+	if record {
+		if err := lib.RecordHistory(file, backend.Name()); err != nil {
+			return fmt.Errorf("%w: failed to record it in history: %s", ErrNotTracked, err)
+		}
+	}
+
+	if linkDir == "" {
+		linkDir = filepath.Dir(file)
+		if _, err := os.Lstat(filepath.Join(linkDir, ".current")); err != nil {
+			return nil
+		}
+	}
+
+	if err := lib.LinkCurrent(linkDir, file); err != nil {
+		return fmt.Errorf("%w: failed to update current link: %s", ErrNotTracked, err)
+	}
+
+	return nil
+}
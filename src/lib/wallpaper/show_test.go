@@ -0,0 +1,63 @@
+package wallpaper
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"earth-view/lib"
+)
+
+func TestShow(t *testing.T) {
+	t.Setenv("XDG_STATE_HOME", t.TempDir())
+	fakeBinaries(t, "feh")
+	backend := newFeh(ExecRunner{})
+
+	dir := t.TempDir()
+	file := filepath.Join(dir, "1003.jpeg")
+
+	// The link of the image directory is only updated if it exists
+	if err := Show(backend, file, Options{Display: "fill"}, true, ""); err != nil {
+		t.Fatalf("Expected success, got error: %v", err)
+	}
+
+	if _, err := os.Lstat(filepath.Join(dir, ".current")); err == nil {
+		t.Fatal("Expected current link not to be created")
+	}
+
+	linkDir := t.TempDir()
+	if err := Show(backend, file, Options{Display: "fill"}, false, linkDir); err != nil {
+		t.Fatalf("Expected success, got error: %v", err)
+	}
+
+	if link, err := os.Readlink(filepath.Join(linkDir, ".current")); err != nil || link != file {
+		t.Fatalf("Expected current link to point to %s, got %s (error: %v)", file, link, err)
+	}
+
+	history, err := lib.LoadHistory()
+	if err != nil {
+		t.Fatalf("Expected no error while loading history, got %v", err)
+	}
+
+	if len(history.Entries) != 1 || history.Entries[0].Path != file {
+		t.Fatalf("Expected a single recorded background %s, got %+v", file, history.Entries)
+	}
+}
+
+func TestShowNotTracked(t *testing.T) {
+	fakeBinaries(t, "feh")
+	backend := newFeh(ExecRunner{})
+
+	// Recording fails since the state directory is a file
+	stateHome := filepath.Join(t.TempDir(), "state")
+	if err := os.WriteFile(stateHome, nil, 0644); err != nil {
+		t.Fatalf("Failed to prepare state directory: %v", err)
+	}
+	t.Setenv("XDG_STATE_HOME", stateHome)
+
+	err := Show(backend, "/tmp/1003.jpeg", Options{Display: "fill"}, true, "")
+	if !errors.Is(err, ErrNotTracked) {
+		t.Fatalf("Expected not tracked error, got %v", err)
+	}
+}
@@ -0,0 +1,119 @@
+/*
+Copyright © 2024 Nicolas Goudry <goudry.nicolas@gmail.com>
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package wallpaper
+
+import (
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"earth-view/lib"
+)
+
+// swaybgModes maps display modes to swaybg modes
+var swaybgModes = map[string]string{
+	"center": "center",
+	"fill":   "fill",
+	"max":    "fit",
+	"scale":  "stretch",
+	"tile":   "tile",
+}
+
+// swaybg sets the wallpaper of wlroots based Wayland compositors with swaybg
+// swaybg keeps running to display the wallpaper: the process started by the backend is tracked in
+// a PID file so that it can be stopped once the next wallpaper is displayed
+// swaybg has no support for spanning a single image across screens, the Xinerama option is ignored
+type swaybg struct {
+	runner  Runner
+	pidFile string
+}
+
+func newSwaybg(runner Runner) Backend {
+	return &swaybg{
+		runner:  runner,
+		pidFile: filepath.Join(lib.RuntimeDir(), "earth-view", "swaybg.pid"),
+	}
+}
+
+func (s *swaybg) Name() string {
+	return "swaybg"
+}
+
+func (s *swaybg) Set(file string, opts Options) error {
+	previousPid := s.readPid()
+
+	pid, err := s.runner.Start(
+		"swaybg",
+		"--output",
+		"*",
+		"--image",
+		file,
+		"--mode",
+		swaybgModes[opts.Display],
+	)
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(s.pidFile), 0700); err != nil {
+		return err
+	}
+
+	if err := os.WriteFile(s.pidFile, []byte(strconv.Itoa(pid)), 0600); err != nil {
+		return err
+	}
+
+	// Stop the previous instance after starting the new one to avoid showing an empty background
+	if previousPid > 0 && isSwaybg(previousPid) {
+		if err := terminate(previousPid); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// readPid returns the PID of the previously started swaybg process, or 0 if there is none
+func (s *swaybg) readPid() int {
+	content, err := os.ReadFile(s.pidFile)
+	if err != nil {
+		return 0
+	}
+
+	pid, err := strconv.Atoi(strings.TrimSpace(string(content)))
+	if err != nil {
+		return 0
+	}
+
+	return pid
+}
+
+// isSwaybg checks that the process with given PID is swaybg, in case the PID has been reused
+func isSwaybg(pid int) bool {
+	comm, err := os.ReadFile(filepath.Join("/proc", strconv.Itoa(pid), "comm"))
+	if err != nil {
+		return false
+	}
+
+	return strings.TrimSpace(string(comm)) == "swaybg"
+}
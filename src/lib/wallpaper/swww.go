@@ -0,0 +1,103 @@
+/*
+Copyright © 2024 Nicolas Goudry <goudry.nicolas@gmail.com>
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package wallpaper
+
+import (
+	"strconv"
+	"time"
+)
+
+// swwwResizeModes maps display modes to swww resize modes
+// swww cannot tile images, they are displayed at their original size instead
+var swwwResizeModes = map[string]string{
+	"center": "no",
+	"fill":   "crop",
+	"max":    "fit",
+	"scale":  "stretch",
+	"tile":   "no",
+}
+
+// swwwDaemonTimeout is the maximum time to wait for swww-daemon to be ready when it is started by
+// the backend
+const swwwDaemonTimeout = 3 * time.Second
+
+// swww sets the wallpaper of Wayland compositors with swww, which supports animated transitions
+// The swww daemon is started if it is not running yet
+// swww has no support for spanning a single image across screens, the Xinerama option is ignored
+type swww struct {
+	runner Runner
+}
+
+func newSwww(runner Runner) Backend {
+	return &swww{runner: runner}
+}
+
+func (s *swww) Name() string {
+	return "swww"
+}
+
+func (s *swww) Set(file string, opts Options) error {
+	if err := s.ensureDaemon(); err != nil {
+		return err
+	}
+
+	args := []string{"img", file, "--resize", swwwResizeModes[opts.Display]}
+
+	if opts.Transition.Type != "" {
+		args = append(args, "--transition-type", opts.Transition.Type)
+	}
+
+	if opts.Transition.Duration > 0 {
+		args = append(
+			args,
+			"--transition-duration",
+			strconv.FormatFloat(opts.Transition.Duration.Seconds(), 'f', -1, 64),
+		)
+	}
+
+	if opts.Transition.Fps > 0 {
+		args = append(args, "--transition-fps", strconv.Itoa(opts.Transition.Fps))
+	}
+
+	return s.runner.Run("swww", args...)
+}
+
+// ensureDaemon starts swww-daemon if it does not answer queries, and waits for it to be ready
+func (s *swww) ensureDaemon() error {
+	if s.runner.Run("swww", "query") == nil {
+		return nil
+	}
+
+	if _, err := s.runner.Start("swww-daemon"); err != nil {
+		return err
+	}
+
+	deadline := time.Now().Add(swwwDaemonTimeout)
+	for {
+		err := s.runner.Run("swww", "query")
+		if err == nil || time.Now().After(deadline) {
+			return err
+		}
+
+		time.Sleep(100 * time.Millisecond)
+	}
+}
@@ -0,0 +1,150 @@
+/*
+Copyright © 2024 Nicolas Goudry <goudry.nicolas@gmail.com>
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package wallpaper
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+)
+
+// Display modes understood by backends
+var Displays = []string{"center", "fill", "max", "scale", "tile"}
+
+// Options holds the settings applied by backends when setting a wallpaper
+type Options struct {
+	// Display is the way the image is fitted on screen, one of Displays
+	Display string
+	// Xinerama places a separate image per screen when enabled, otherwise a single image is
+	// stretched across all screens
+	Xinerama bool
+	// DarkFile is the image used in dark mode by desktops supporting it, the wallpaper is used in
+	// both modes when empty
+	DarkFile string
+	// Transition is the animation played by backends supporting animated wallpaper changes
+	Transition Transition
+}
+
+// Transition holds the settings of the animation played when changing the wallpaper
+// Zero values let the backend use its own defaults
+type Transition struct {
+	// Type is the name of the transition, as understood by the backend
+	Type string
+	// Duration is the length of the transition
+	Duration time.Duration
+	// Fps is the frame rate of the transition
+	Fps int
+}
+
+// Backend sets the desktop wallpaper using a given program
+type Backend interface {
+	// Name returns the backend name, as used by the '--backend' flag
+	Name() string
+	// Set sets the given image file as wallpaper
+	Set(file string, opts Options) error
+}
+
+// backendInfo describes how to detect and create a backend
+type backendInfo struct {
+	name   string
+	detect func(getenv func(string) string) bool
+	new    func(runner Runner) Backend
+}
+
+// backends lists known backends, in detection order
+// The last backend is used as a fallback when no other backend is detected
+var backends = []backendInfo{
+	{name: "gnome", detect: desktopIs("GNOME"), new: newGnome},
+	{name: "kde", detect: desktopIs("KDE"), new: newKde},
+	{name: "hyprpaper", detect: envIsSet("HYPRLAND_INSTANCE_SIGNATURE"), new: newHyprpaper},
+	{name: "swaybg", detect: envIsSet("SWAYSOCK"), new: newSwaybg},
+	{name: "swww", detect: func(_ func(string) string) bool { return false }, new: newSwww},
+	{name: "feh", detect: func(_ func(string) string) bool { return true }, new: newFeh},
+}
+
+// Names returns the names of known backends
+func Names() []string {
+	names := make([]string, len(backends))
+	for i, backend := range backends {
+		names[i] = backend.name
+	}
+
+	return names
+}
+
+// New creates the backend with given name
+func New(name string, runner Runner) (Backend, error) {
+	for _, backend := range backends {
+		if backend.name == name {
+			return backend.new(runner), nil
+		}
+	}
+
+	return nil, fmt.Errorf(
+		"unknown backend: %s. Supported backends are: %s",
+		name,
+		strings.Join(Names(), ", "),
+	)
+}
+
+// Detect creates the backend matching the current environment
+func Detect(getenv func(string) string, runner Runner) Backend {
+	for _, backend := range backends {
+		if backend.detect(getenv) {
+			return backend.new(runner)
+		}
+	}
+
+	return backends[len(backends)-1].new(runner)
+}
+
+// desktopIs returns a detection function matching the given desktop against XDG_CURRENT_DESKTOP,
+// which holds a colon separated list of desktop names
+func desktopIs(desktop string) func(getenv func(string) string) bool {
+	return func(getenv func(string) string) bool {
+		return slices.ContainsFunc(
+			strings.Split(getenv("XDG_CURRENT_DESKTOP"), ":"),
+			func(name string) bool { return strings.EqualFold(name, desktop) },
+		)
+	}
+}
+
+// envIsSet returns a detection function checking that the given environment variable is set
+func envIsSet(key string) func(getenv func(string) string) bool {
+	return func(getenv func(string) string) bool {
+		return getenv(key) != ""
+	}
+}
+
+// ValidateOptions checks that options hold supported values
+func ValidateOptions(opts Options) error {
+	if !slices.Contains(Displays, opts.Display) {
+		return fmt.Errorf(
+			"invalid display mode: %s. Supported modes are: %s",
+			opts.Display,
+			strings.Join(Displays, ", "),
+		)
+	}
+
+	return nil
+}
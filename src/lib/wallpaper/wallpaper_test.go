@@ -0,0 +1,167 @@
+package wallpaper
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// fakeBinaries creates executables logging their invocations and puts them first in PATH
+// It returns a function reading the logged invocations
+func fakeBinaries(t *testing.T, names ...string) func() []string {
+	dir := t.TempDir()
+	logFile := filepath.Join(dir, "calls.log")
+
+	for _, name := range names {
+		script := "#!/bin/sh\necho \"${0##*/} $*\" >> " + logFile + "\n"
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(script), 0755); err != nil {
+			t.Fatalf("Failed to prepare fake binary: %v", err)
+		}
+	}
+
+	t.Setenv("PATH", dir+string(os.PathListSeparator)+os.Getenv("PATH"))
+
+	return func() []string {
+		content, err := os.ReadFile(logFile)
+		if err != nil {
+			t.Fatalf("Failed to read fake binaries calls: %v", err)
+		}
+
+		return strings.Split(strings.TrimSpace(string(content)), "\n")
+	}
+}
+
+func checkCalls(t *testing.T, got []string, want []string) {
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Fatalf("Expected calls:\n%s\nGot:\n%s", strings.Join(want, "\n"), strings.Join(got, "\n"))
+	}
+}
+
+func env(vars map[string]string) func(string) string {
+	return func(key string) string {
+		return vars[key]
+	}
+}
+
+func TestDetect(t *testing.T) {
+	for desktop, want := range map[string]string{
+		"GNOME":        "gnome",
+		"ubuntu:GNOME": "gnome",
+		"KDE":          "kde",
+		"XFCE":         "feh",
+		"":             "feh",
+	} {
+		backend := Detect(env(map[string]string{"XDG_CURRENT_DESKTOP": desktop}), ExecRunner{})
+		if backend.Name() != want {
+			t.Fatalf("Expected %s backend for desktop %q, got %s", want, desktop, backend.Name())
+		}
+	}
+}
+
+func TestNewUnknownBackend(t *testing.T) {
+	if _, err := New("unknown", ExecRunner{}); err == nil {
+		t.Fatal("Expected error, got success")
+	}
+}
+
+func TestValidateOptions(t *testing.T) {
+	if err := ValidateOptions(Options{Display: "fill"}); err != nil {
+		t.Fatalf("Expected valid options, got error: %v", err)
+	}
+
+	if err := ValidateOptions(Options{Display: "stretch"}); err == nil {
+		t.Fatal("Expected error, got success")
+	}
+}
+
+func TestGnomeSet(t *testing.T) {
+	calls := fakeBinaries(t, "gsettings")
+
+	if err := newGnome(ExecRunner{}).Set("/tmp/my image.jpeg", Options{Display: "max", Xinerama: true}); err != nil {
+		t.Fatalf("Expected success, got error: %v", err)
+	}
+
+	checkCalls(t, calls(), []string{
+		"gsettings set org.gnome.desktop.background picture-uri file:///tmp/my%20image.jpeg",
+		"gsettings set org.gnome.desktop.background picture-uri-dark file:///tmp/my%20image.jpeg",
+		"gsettings set org.gnome.desktop.background picture-options scaled",
+	})
+}
+
+func TestGnomeSetNoXinerama(t *testing.T) {
+	calls := fakeBinaries(t, "gsettings")
+
+	if err := newGnome(ExecRunner{}).Set("/tmp/1003.jpeg", Options{Display: "fill"}); err != nil {
+		t.Fatalf("Expected success, got error: %v", err)
+	}
+
+	if got := calls(); got[2] != "gsettings set org.gnome.desktop.background picture-options spanned" {
+		t.Fatalf("Expected spanned picture options, got: %s", got[2])
+	}
+}
+
+func TestGnomeSetDarkFile(t *testing.T) {
+	calls := fakeBinaries(t, "gsettings")
+
+	err := newGnome(ExecRunner{}).Set("/tmp/1003.jpeg", Options{
+		Display:  "fill",
+		Xinerama: true,
+		DarkFile: "/tmp/1004.jpeg",
+	})
+	if err != nil {
+		t.Fatalf("Expected success, got error: %v", err)
+	}
+
+	checkCalls(t, calls(), []string{
+		"gsettings set org.gnome.desktop.background picture-uri file:///tmp/1003.jpeg",
+		"gsettings set org.gnome.desktop.background picture-uri-dark file:///tmp/1004.jpeg",
+		"gsettings set org.gnome.desktop.background picture-options zoom",
+	})
+}
+
+func TestKdeSet(t *testing.T) {
+	calls := fakeBinaries(t, "plasma-apply-wallpaperimage")
+
+	if err := newKde(ExecRunner{}).Set("/tmp/1003.jpeg", Options{Display: "fill", Xinerama: true}); err != nil {
+		t.Fatalf("Expected success, got error: %v", err)
+	}
+
+	checkCalls(t, calls(), []string{
+		"plasma-apply-wallpaperimage --fill-mode preserveAspectCrop /tmp/1003.jpeg",
+	})
+}
+
+func TestFehSet(t *testing.T) {
+	calls := fakeBinaries(t, "feh")
+	backend := newFeh(ExecRunner{})
+
+	if err := backend.Set("/tmp/1003.jpeg", Options{Display: "tile", Xinerama: true}); err != nil {
+		t.Fatalf("Expected success, got error: %v", err)
+	}
+
+	if err := backend.Set("/tmp/1003.jpeg", Options{Display: "center"}); err != nil {
+		t.Fatalf("Expected success, got error: %v", err)
+	}
+
+	checkCalls(t, calls(), []string{
+		"feh --bg-tile --no-fehbg /tmp/1003.jpeg",
+		"feh --bg-center --no-fehbg --no-xinerama /tmp/1003.jpeg",
+	})
+}
+
+func TestRunnerFailure(t *testing.T) {
+	dir := t.TempDir()
+	script := "#!/bin/sh\necho 'cannot open display' >&2\nexit 1\n"
+	if err := os.WriteFile(filepath.Join(dir, "feh"), []byte(script), 0755); err != nil {
+		t.Fatalf("Failed to prepare fake binary: %v", err)
+	}
+	t.Setenv("PATH", dir)
+
+	err := newFeh(ExecRunner{}).Set("/tmp/1003.jpeg", Options{Display: "fill"})
+	if err == nil {
+		t.Fatal("Expected error, got success")
+	} else if !strings.Contains(err.Error(), "cannot open display") {
+		t.Fatalf("Expected error to contain program output, got: %v", err)
+	}
+}
@@ -0,0 +1,208 @@
+package wallpaper
+
+import (
+	"bufio"
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+// processRunning checks that the process with given PID exists and is not a zombie
+func processRunning(pid int) bool {
+	stat, err := os.ReadFile(filepath.Join("/proc", strconv.Itoa(pid), "stat"))
+	if err != nil {
+		return false
+	}
+
+	// The process state follows the command name, which is enclosed in parentheses
+	fields := strings.Fields(string(stat[strings.LastIndex(string(stat), ")")+1:]))
+
+	return len(fields) > 0 && fields[0] != "Z"
+}
+
+func TestDetectWayland(t *testing.T) {
+	for vars, want := range map[[2]string]string{
+		{"SWAYSOCK", "/run/user/1000/sway-ipc.sock"}: "swaybg",
+		{"HYPRLAND_INSTANCE_SIGNATURE", "signature"}: "hyprpaper",
+	} {
+		backend := Detect(env(map[string]string{vars[0]: vars[1]}), ExecRunner{})
+		if backend.Name() != want {
+			t.Fatalf("Expected %s backend when %s is set, got %s", want, vars[0], backend.Name())
+		}
+	}
+}
+
+func TestSwaybgSet(t *testing.T) {
+	t.Setenv("XDG_RUNTIME_DIR", t.TempDir())
+
+	dir := t.TempDir()
+	script := "#!/bin/sh\necho \"${0##*/} $*\" >> " + filepath.Join(dir, "calls.log") +
+		"\nwhile :; do sleep 1; done\n"
+	if err := os.WriteFile(filepath.Join(dir, "swaybg"), []byte(script), 0755); err != nil {
+		t.Fatalf("Failed to prepare fake binary: %v", err)
+	}
+	t.Setenv("PATH", dir+string(os.PathListSeparator)+os.Getenv("PATH"))
+
+	backend := newSwaybg(ExecRunner{}).(*swaybg)
+	var pids []int
+	t.Cleanup(func() {
+		for _, pid := range pids {
+			if process, err := os.FindProcess(pid); err == nil {
+				process.Kill()
+			}
+		}
+	})
+
+	for _, display := range []string{"fill", "max"} {
+		if err := backend.Set("/tmp/1003.jpeg", Options{Display: display}); err != nil {
+			t.Fatalf("Expected success, got error: %v", err)
+		}
+
+		pid := backend.readPid()
+		if pid == 0 {
+			t.Fatal("Expected swaybg PID to be saved")
+		}
+		pids = append(pids, pid)
+	}
+
+	// Leave some time to the process to handle the signal
+	deadline := time.Now().Add(2 * time.Second)
+	for processRunning(pids[0]) && time.Now().Before(deadline) {
+		time.Sleep(50 * time.Millisecond)
+	}
+
+	if processRunning(pids[0]) {
+		t.Fatal("Expected previous swaybg process to be stopped")
+	}
+
+	if !processRunning(pids[1]) {
+		t.Fatal("Expected current swaybg process to be running")
+	}
+
+	content, err := os.ReadFile(filepath.Join(dir, "calls.log"))
+	if err != nil {
+		t.Fatalf("Failed to read fake binaries calls: %v", err)
+	}
+
+	checkCalls(t, strings.Split(strings.TrimSpace(string(content)), "\n"), []string{
+		"swaybg --output * --image /tmp/1003.jpeg --mode fill",
+		"swaybg --output * --image /tmp/1003.jpeg --mode fit",
+	})
+}
+
+func TestSwwwSet(t *testing.T) {
+	calls := fakeBinaries(t, "swww")
+
+	err := newSwww(ExecRunner{}).Set("/tmp/1003.jpeg", Options{
+		Display: "fill",
+		Transition: Transition{
+			Type:     "wipe",
+			Duration: 1500 * time.Millisecond,
+			Fps:      60,
+		},
+	})
+	if err != nil {
+		t.Fatalf("Expected success, got error: %v", err)
+	}
+
+	checkCalls(t, calls(), []string{
+		"swww query",
+		"swww img /tmp/1003.jpeg --resize crop --transition-type wipe --transition-duration 1.5 --transition-fps 60",
+	})
+}
+
+func TestSwwwStartDaemon(t *testing.T) {
+	dir := t.TempDir()
+	logFile := filepath.Join(dir, "calls.log")
+	readyFile := filepath.Join(dir, "ready")
+
+	// The fake swww fails to query until the fake daemon has been started
+	for name, script := range map[string]string{
+		"swww": "echo \"${0##*/} $*\" >> " + logFile + "\n" +
+			"if test \"$1\" = query && ! test -f " + readyFile + "; then exit 1; fi\n",
+		"swww-daemon": "echo \"${0##*/} $*\" >> " + logFile + "\ntouch " + readyFile + "\n",
+	} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte("#!/bin/sh\n"+script), 0755); err != nil {
+			t.Fatalf("Failed to prepare fake binary: %v", err)
+		}
+	}
+	t.Setenv("PATH", dir+string(os.PathListSeparator)+os.Getenv("PATH"))
+
+	if err := newSwww(ExecRunner{}).Set("/tmp/1003.jpeg", Options{Display: "max"}); err != nil {
+		t.Fatalf("Expected success, got error: %v", err)
+	}
+
+	content, err := os.ReadFile(logFile)
+	if err != nil {
+		t.Fatalf("Failed to read fake binaries calls: %v", err)
+	}
+
+	calls := strings.Split(strings.TrimSpace(string(content)), "\n")
+	if calls[1] != "swww-daemon " || calls[len(calls)-1] != "swww img /tmp/1003.jpeg --resize fit" {
+		t.Fatalf("Expected daemon to be started before setting image, got:\n%s", content)
+	}
+}
+
+func TestHyprpaperSet(t *testing.T) {
+	runtimeDir := t.TempDir()
+	t.Setenv("XDG_RUNTIME_DIR", runtimeDir)
+	t.Setenv("HYPRLAND_INSTANCE_SIGNATURE", "signature")
+
+	socketDir := filepath.Join(runtimeDir, "hypr", "signature")
+	if err := os.MkdirAll(socketDir, 0700); err != nil {
+		t.Fatalf("Failed to prepare socket directory: %v", err)
+	}
+
+	listener, err := net.Listen("unix", filepath.Join(socketDir, ".hyprpaper.sock"))
+	if err != nil {
+		t.Fatalf("Failed to create fake socket: %v", err)
+	}
+	defer listener.Close()
+
+	// Fake hyprpaper answers each request on its own connection
+	requests := make(chan string, 10)
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				close(requests)
+				return
+			}
+
+			buffer := make([]byte, 1024)
+			n, _ := bufio.NewReader(conn).Read(buffer)
+			requests <- string(buffer[:n])
+			conn.Write([]byte("ok"))
+			conn.Close()
+		}
+	}()
+
+	if err := newHyprpaper(ExecRunner{}).Set("/tmp/1003.jpeg", Options{Display: "tile"}); err != nil {
+		t.Fatalf("Expected success, got error: %v", err)
+	}
+	listener.Close()
+
+	var got []string
+	for request := range requests {
+		got = append(got, request)
+	}
+
+	checkCalls(t, got, []string{
+		"preload /tmp/1003.jpeg",
+		"wallpaper ,tile:/tmp/1003.jpeg",
+		"unload unused",
+	})
+}
+
+func TestHyprpaperUnreachable(t *testing.T) {
+	t.Setenv("XDG_RUNTIME_DIR", t.TempDir())
+	t.Setenv("HYPRLAND_INSTANCE_SIGNATURE", "signature")
+
+	if err := newHyprpaper(ExecRunner{}).Set("/tmp/1003.jpeg", Options{Display: "fill"}); err == nil {
+		t.Fatal("Expected error, got success")
+	}
+}
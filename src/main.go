@@ -23,8 +23,24 @@ package main
 
 import (
 	"earth-view/cmd"
+	_ "earth-view/cmd/browse"
+	_ "earth-view/cmd/compose"
+	_ "earth-view/cmd/daemon"
+	_ "earth-view/cmd/export"
+	_ "earth-view/cmd/favorites"
 	_ "earth-view/cmd/fetch"
+	_ "earth-view/cmd/history"
+	_ "earth-view/cmd/index"
+	_ "earth-view/cmd/info"
 	_ "earth-view/cmd/list"
+	_ "earth-view/cmd/mirror"
+	_ "earth-view/cmd/palette"
+	_ "earth-view/cmd/search"
+	_ "earth-view/cmd/serve"
+	_ "earth-view/cmd/set"
+	_ "earth-view/cmd/thumbs"
+	_ "earth-view/cmd/trash"
+	_ "earth-view/cmd/worldmap"
 )
 
 func main() {